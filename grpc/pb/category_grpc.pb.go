@@ -19,12 +19,22 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CategoryService_CreateCategory_FullMethodName       = "/grpc.CategoryService/CreateCategory"
-	CategoryService_GetCategory_FullMethodName          = "/grpc.CategoryService/GetCategory"
-	CategoryService_UpdateCategory_FullMethodName       = "/grpc.CategoryService/UpdateCategory"
-	CategoryService_DeleteCategory_FullMethodName       = "/grpc.CategoryService/DeleteCategory"
-	CategoryService_ListCategories_FullMethodName       = "/grpc.CategoryService/ListCategories"
-	CategoryService_GetCategoryHierarchy_FullMethodName = "/grpc.CategoryService/GetCategoryHierarchy"
+	CategoryService_CreateCategory_FullMethodName            = "/grpc.CategoryService/CreateCategory"
+	CategoryService_GetCategory_FullMethodName               = "/grpc.CategoryService/GetCategory"
+	CategoryService_UpdateCategory_FullMethodName            = "/grpc.CategoryService/UpdateCategory"
+	CategoryService_DeleteCategory_FullMethodName            = "/grpc.CategoryService/DeleteCategory"
+	CategoryService_ListCategories_FullMethodName            = "/grpc.CategoryService/ListCategories"
+	CategoryService_GetCategoryHierarchy_FullMethodName      = "/grpc.CategoryService/GetCategoryHierarchy"
+	CategoryService_GetCategoryBySlug_FullMethodName         = "/grpc.CategoryService/GetCategoryBySlug"
+	CategoryService_UpsertCategoryTranslation_FullMethodName = "/grpc.CategoryService/UpsertCategoryTranslation"
+	CategoryService_MoveCategory_FullMethodName              = "/grpc.CategoryService/MoveCategory"
+	CategoryService_ListCategoryAncestors_FullMethodName     = "/grpc.CategoryService/ListCategoryAncestors"
+	CategoryService_ListCategoryDescendants_FullMethodName   = "/grpc.CategoryService/ListCategoryDescendants"
+	CategoryService_GetCategoryBreadcrumb_FullMethodName     = "/grpc.CategoryService/GetCategoryBreadcrumb"
+	CategoryService_ReorderCategories_FullMethodName         = "/grpc.CategoryService/ReorderCategories"
+	CategoryService_SetCategoryAttributes_FullMethodName     = "/grpc.CategoryService/SetCategoryAttributes"
+	CategoryService_GetCategoryAttributes_FullMethodName     = "/grpc.CategoryService/GetCategoryAttributes"
+	CategoryService_ApplyProductEvents_FullMethodName        = "/grpc.CategoryService/ApplyProductEvents"
 )
 
 // CategoryServiceClient is the client API for CategoryService service.
@@ -37,6 +47,17 @@ type CategoryServiceClient interface {
 	DeleteCategory(ctx context.Context, in *DeleteCategoryRequest, opts ...grpc.CallOption) (*DeleteCategoryResponse, error)
 	ListCategories(ctx context.Context, in *ListCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
 	GetCategoryHierarchy(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*CategoryHierarchyResponse, error)
+	GetCategoryBySlug(ctx context.Context, in *GetCategoryBySlugRequest, opts ...grpc.CallOption) (*Category, error)
+	UpsertCategoryTranslation(ctx context.Context, in *CategoryTranslation, opts ...grpc.CallOption) (*CategoryTranslation, error)
+	MoveCategory(ctx context.Context, in *MoveCategoryRequest, opts ...grpc.CallOption) (*Category, error)
+	ListCategoryAncestors(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
+	ListCategoryDescendants(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
+	GetCategoryBreadcrumb(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*CategoryBreadcrumbResponse, error)
+	ReorderCategories(ctx context.Context, in *ReorderCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error)
+	SetCategoryAttributes(ctx context.Context, in *SetCategoryAttributesRequest, opts ...grpc.CallOption) (*CategoryAttributesResponse, error)
+	// Returns the attributes defined on the category and inherited from its ancestors.
+	GetCategoryAttributes(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*CategoryAttributesResponse, error)
+	ApplyProductEvents(ctx context.Context, in *ApplyProductEventsRequest, opts ...grpc.CallOption) (*ApplyProductEventsResponse, error)
 }
 
 type categoryServiceClient struct {
@@ -107,6 +128,106 @@ func (c *categoryServiceClient) GetCategoryHierarchy(ctx context.Context, in *Ge
 	return out, nil
 }
 
+func (c *categoryServiceClient) GetCategoryBySlug(ctx context.Context, in *GetCategoryBySlugRequest, opts ...grpc.CallOption) (*Category, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Category)
+	err := c.cc.Invoke(ctx, CategoryService_GetCategoryBySlug_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) UpsertCategoryTranslation(ctx context.Context, in *CategoryTranslation, opts ...grpc.CallOption) (*CategoryTranslation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoryTranslation)
+	err := c.cc.Invoke(ctx, CategoryService_UpsertCategoryTranslation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) MoveCategory(ctx context.Context, in *MoveCategoryRequest, opts ...grpc.CallOption) (*Category, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Category)
+	err := c.cc.Invoke(ctx, CategoryService_MoveCategory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) ListCategoryAncestors(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCategoriesResponse)
+	err := c.cc.Invoke(ctx, CategoryService_ListCategoryAncestors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) ListCategoryDescendants(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCategoriesResponse)
+	err := c.cc.Invoke(ctx, CategoryService_ListCategoryDescendants_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) GetCategoryBreadcrumb(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*CategoryBreadcrumbResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoryBreadcrumbResponse)
+	err := c.cc.Invoke(ctx, CategoryService_GetCategoryBreadcrumb_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) ReorderCategories(ctx context.Context, in *ReorderCategoriesRequest, opts ...grpc.CallOption) (*ListCategoriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCategoriesResponse)
+	err := c.cc.Invoke(ctx, CategoryService_ReorderCategories_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) SetCategoryAttributes(ctx context.Context, in *SetCategoryAttributesRequest, opts ...grpc.CallOption) (*CategoryAttributesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoryAttributesResponse)
+	err := c.cc.Invoke(ctx, CategoryService_SetCategoryAttributes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) GetCategoryAttributes(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*CategoryAttributesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CategoryAttributesResponse)
+	err := c.cc.Invoke(ctx, CategoryService_GetCategoryAttributes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *categoryServiceClient) ApplyProductEvents(ctx context.Context, in *ApplyProductEventsRequest, opts ...grpc.CallOption) (*ApplyProductEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyProductEventsResponse)
+	err := c.cc.Invoke(ctx, CategoryService_ApplyProductEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CategoryServiceServer is the server API for CategoryService service.
 // All implementations must embed UnimplementedCategoryServiceServer
 // for forward compatibility.
@@ -117,6 +238,17 @@ type CategoryServiceServer interface {
 	DeleteCategory(context.Context, *DeleteCategoryRequest) (*DeleteCategoryResponse, error)
 	ListCategories(context.Context, *ListCategoriesRequest) (*ListCategoriesResponse, error)
 	GetCategoryHierarchy(context.Context, *GetCategoryRequest) (*CategoryHierarchyResponse, error)
+	GetCategoryBySlug(context.Context, *GetCategoryBySlugRequest) (*Category, error)
+	UpsertCategoryTranslation(context.Context, *CategoryTranslation) (*CategoryTranslation, error)
+	MoveCategory(context.Context, *MoveCategoryRequest) (*Category, error)
+	ListCategoryAncestors(context.Context, *GetCategoryRequest) (*ListCategoriesResponse, error)
+	ListCategoryDescendants(context.Context, *GetCategoryRequest) (*ListCategoriesResponse, error)
+	GetCategoryBreadcrumb(context.Context, *GetCategoryRequest) (*CategoryBreadcrumbResponse, error)
+	ReorderCategories(context.Context, *ReorderCategoriesRequest) (*ListCategoriesResponse, error)
+	SetCategoryAttributes(context.Context, *SetCategoryAttributesRequest) (*CategoryAttributesResponse, error)
+	// Returns the attributes defined on the category and inherited from its ancestors.
+	GetCategoryAttributes(context.Context, *GetCategoryRequest) (*CategoryAttributesResponse, error)
+	ApplyProductEvents(context.Context, *ApplyProductEventsRequest) (*ApplyProductEventsResponse, error)
 	mustEmbedUnimplementedCategoryServiceServer()
 }
 
@@ -145,6 +277,36 @@ func (UnimplementedCategoryServiceServer) ListCategories(context.Context, *ListC
 func (UnimplementedCategoryServiceServer) GetCategoryHierarchy(context.Context, *GetCategoryRequest) (*CategoryHierarchyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCategoryHierarchy not implemented")
 }
+func (UnimplementedCategoryServiceServer) GetCategoryBySlug(context.Context, *GetCategoryBySlugRequest) (*Category, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCategoryBySlug not implemented")
+}
+func (UnimplementedCategoryServiceServer) UpsertCategoryTranslation(context.Context, *CategoryTranslation) (*CategoryTranslation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertCategoryTranslation not implemented")
+}
+func (UnimplementedCategoryServiceServer) MoveCategory(context.Context, *MoveCategoryRequest) (*Category, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveCategory not implemented")
+}
+func (UnimplementedCategoryServiceServer) ListCategoryAncestors(context.Context, *GetCategoryRequest) (*ListCategoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCategoryAncestors not implemented")
+}
+func (UnimplementedCategoryServiceServer) ListCategoryDescendants(context.Context, *GetCategoryRequest) (*ListCategoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCategoryDescendants not implemented")
+}
+func (UnimplementedCategoryServiceServer) GetCategoryBreadcrumb(context.Context, *GetCategoryRequest) (*CategoryBreadcrumbResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCategoryBreadcrumb not implemented")
+}
+func (UnimplementedCategoryServiceServer) ReorderCategories(context.Context, *ReorderCategoriesRequest) (*ListCategoriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReorderCategories not implemented")
+}
+func (UnimplementedCategoryServiceServer) SetCategoryAttributes(context.Context, *SetCategoryAttributesRequest) (*CategoryAttributesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCategoryAttributes not implemented")
+}
+func (UnimplementedCategoryServiceServer) GetCategoryAttributes(context.Context, *GetCategoryRequest) (*CategoryAttributesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCategoryAttributes not implemented")
+}
+func (UnimplementedCategoryServiceServer) ApplyProductEvents(context.Context, *ApplyProductEventsRequest) (*ApplyProductEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyProductEvents not implemented")
+}
 func (UnimplementedCategoryServiceServer) mustEmbedUnimplementedCategoryServiceServer() {}
 func (UnimplementedCategoryServiceServer) testEmbeddedByValue()                         {}
 
@@ -274,6 +436,186 @@ func _CategoryService_GetCategoryHierarchy_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CategoryService_GetCategoryBySlug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategoryBySlugRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).GetCategoryBySlug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_GetCategoryBySlug_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).GetCategoryBySlug(ctx, req.(*GetCategoryBySlugRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_UpsertCategoryTranslation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CategoryTranslation)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).UpsertCategoryTranslation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_UpsertCategoryTranslation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).UpsertCategoryTranslation(ctx, req.(*CategoryTranslation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_MoveCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).MoveCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_MoveCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).MoveCategory(ctx, req.(*MoveCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_ListCategoryAncestors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).ListCategoryAncestors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_ListCategoryAncestors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).ListCategoryAncestors(ctx, req.(*GetCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_ListCategoryDescendants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).ListCategoryDescendants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_ListCategoryDescendants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).ListCategoryDescendants(ctx, req.(*GetCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_GetCategoryBreadcrumb_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).GetCategoryBreadcrumb(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_GetCategoryBreadcrumb_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).GetCategoryBreadcrumb(ctx, req.(*GetCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_ReorderCategories_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderCategoriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).ReorderCategories(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_ReorderCategories_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).ReorderCategories(ctx, req.(*ReorderCategoriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_SetCategoryAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCategoryAttributesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).SetCategoryAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_SetCategoryAttributes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).SetCategoryAttributes(ctx, req.(*SetCategoryAttributesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_GetCategoryAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).GetCategoryAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_GetCategoryAttributes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).GetCategoryAttributes(ctx, req.(*GetCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CategoryService_ApplyProductEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyProductEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CategoryServiceServer).ApplyProductEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CategoryService_ApplyProductEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CategoryServiceServer).ApplyProductEvents(ctx, req.(*ApplyProductEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CategoryService_ServiceDesc is the grpc.ServiceDesc for CategoryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -305,6 +647,46 @@ var CategoryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCategoryHierarchy",
 			Handler:    _CategoryService_GetCategoryHierarchy_Handler,
 		},
+		{
+			MethodName: "GetCategoryBySlug",
+			Handler:    _CategoryService_GetCategoryBySlug_Handler,
+		},
+		{
+			MethodName: "UpsertCategoryTranslation",
+			Handler:    _CategoryService_UpsertCategoryTranslation_Handler,
+		},
+		{
+			MethodName: "MoveCategory",
+			Handler:    _CategoryService_MoveCategory_Handler,
+		},
+		{
+			MethodName: "ListCategoryAncestors",
+			Handler:    _CategoryService_ListCategoryAncestors_Handler,
+		},
+		{
+			MethodName: "ListCategoryDescendants",
+			Handler:    _CategoryService_ListCategoryDescendants_Handler,
+		},
+		{
+			MethodName: "GetCategoryBreadcrumb",
+			Handler:    _CategoryService_GetCategoryBreadcrumb_Handler,
+		},
+		{
+			MethodName: "ReorderCategories",
+			Handler:    _CategoryService_ReorderCategories_Handler,
+		},
+		{
+			MethodName: "SetCategoryAttributes",
+			Handler:    _CategoryService_SetCategoryAttributes_Handler,
+		},
+		{
+			MethodName: "GetCategoryAttributes",
+			Handler:    _CategoryService_GetCategoryAttributes_Handler,
+		},
+		{
+			MethodName: "ApplyProductEvents",
+			Handler:    _CategoryService_ApplyProductEvents_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc/pb/category.proto",
@@ -19,17 +19,28 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	ProductService_CreateProduct_FullMethodName        = "/pb.ProductService/CreateProduct"
-	ProductService_GetProduct_FullMethodName           = "/pb.ProductService/GetProduct"
-	ProductService_UpdateProduct_FullMethodName        = "/pb.ProductService/UpdateProduct"
-	ProductService_DeleteProduct_FullMethodName        = "/pb.ProductService/DeleteProduct"
-	ProductService_ListProducts_FullMethodName         = "/pb.ProductService/ListProducts"
-	ProductService_CreateProductVariant_FullMethodName = "/pb.ProductService/CreateProductVariant"
-	ProductService_UpdateProductVariant_FullMethodName = "/pb.ProductService/UpdateProductVariant"
-	ProductService_DeleteProductVariant_FullMethodName = "/pb.ProductService/DeleteProductVariant"
-	ProductService_AddProductImage_FullMethodName      = "/pb.ProductService/AddProductImage"
-	ProductService_UpdateProductImage_FullMethodName   = "/pb.ProductService/UpdateProductImage"
-	ProductService_DeleteProductImage_FullMethodName   = "/pb.ProductService/DeleteProductImage"
+	ProductService_CreateProduct_FullMethodName            = "/pb.ProductService/CreateProduct"
+	ProductService_GetProduct_FullMethodName               = "/pb.ProductService/GetProduct"
+	ProductService_UpdateProduct_FullMethodName            = "/pb.ProductService/UpdateProduct"
+	ProductService_DeleteProduct_FullMethodName            = "/pb.ProductService/DeleteProduct"
+	ProductService_ListProducts_FullMethodName             = "/pb.ProductService/ListProducts"
+	ProductService_GetProductBySlug_FullMethodName         = "/pb.ProductService/GetProductBySlug"
+	ProductService_CreateProductVariant_FullMethodName     = "/pb.ProductService/CreateProductVariant"
+	ProductService_UpdateProductVariant_FullMethodName     = "/pb.ProductService/UpdateProductVariant"
+	ProductService_DeleteProductVariant_FullMethodName     = "/pb.ProductService/DeleteProductVariant"
+	ProductService_AddProductImage_FullMethodName          = "/pb.ProductService/AddProductImage"
+	ProductService_UpdateProductImage_FullMethodName       = "/pb.ProductService/UpdateProductImage"
+	ProductService_DeleteProductImage_FullMethodName       = "/pb.ProductService/DeleteProductImage"
+	ProductService_ReorderProductImages_FullMethodName     = "/pb.ProductService/ReorderProductImages"
+	ProductService_CreateBundle_FullMethodName             = "/pb.ProductService/CreateBundle"
+	ProductService_GetBundle_FullMethodName                = "/pb.ProductService/GetBundle"
+	ProductService_ListBundles_FullMethodName              = "/pb.ProductService/ListBundles"
+	ProductService_DeleteBundle_FullMethodName             = "/pb.ProductService/DeleteBundle"
+	ProductService_GetRecommendations_FullMethodName       = "/pb.ProductService/GetRecommendations"
+	ProductService_SetRelatedProducts_FullMethodName       = "/pb.ProductService/SetRelatedProducts"
+	ProductService_UpsertProductTranslation_FullMethodName = "/pb.ProductService/UpsertProductTranslation"
+	ProductService_CountProductsByCategory_FullMethodName  = "/pb.ProductService/CountProductsByCategory"
+	ProductService_ReassignProducts_FullMethodName         = "/pb.ProductService/ReassignProducts"
 )
 
 // ProductServiceClient is the client API for ProductService service.
@@ -44,6 +55,7 @@ type ProductServiceClient interface {
 	UpdateProduct(ctx context.Context, in *UpdateProductRequest, opts ...grpc.CallOption) (*Product, error)
 	DeleteProduct(ctx context.Context, in *DeleteProductRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error)
 	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	GetProductBySlug(ctx context.Context, in *GetProductBySlugRequest, opts ...grpc.CallOption) (*Product, error)
 	// Product Variant operations
 	CreateProductVariant(ctx context.Context, in *CreateProductVariantRequest, opts ...grpc.CallOption) (*ProductVariant, error)
 	UpdateProductVariant(ctx context.Context, in *UpdateProductVariantRequest, opts ...grpc.CallOption) (*ProductVariant, error)
@@ -52,6 +64,20 @@ type ProductServiceClient interface {
 	AddProductImage(ctx context.Context, in *AddProductImageRequest, opts ...grpc.CallOption) (*ProductImage, error)
 	UpdateProductImage(ctx context.Context, in *UpdateProductImageRequest, opts ...grpc.CallOption) (*ProductImage, error)
 	DeleteProductImage(ctx context.Context, in *DeleteProductImageRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error)
+	ReorderProductImages(ctx context.Context, in *ReorderProductImagesRequest, opts ...grpc.CallOption) (*ReorderProductImagesResponse, error)
+	// Bundle operations
+	CreateBundle(ctx context.Context, in *CreateBundleRequest, opts ...grpc.CallOption) (*Bundle, error)
+	GetBundle(ctx context.Context, in *GetBundleRequest, opts ...grpc.CallOption) (*Bundle, error)
+	ListBundles(ctx context.Context, in *ListBundlesRequest, opts ...grpc.CallOption) (*ListBundlesResponse, error)
+	DeleteBundle(ctx context.Context, in *DeleteBundleRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error)
+	// Recommendations
+	GetRecommendations(ctx context.Context, in *GetRecommendationsRequest, opts ...grpc.CallOption) (*GetRecommendationsResponse, error)
+	SetRelatedProducts(ctx context.Context, in *SetRelatedProductsRequest, opts ...grpc.CallOption) (*SetRelatedProductsResponse, error)
+	// Translations
+	UpsertProductTranslation(ctx context.Context, in *ProductTranslation, opts ...grpc.CallOption) (*ProductTranslation, error)
+	// Category maintenance, used by the category service before deleting categories.
+	CountProductsByCategory(ctx context.Context, in *CountProductsByCategoryRequest, opts ...grpc.CallOption) (*CountProductsByCategoryResponse, error)
+	ReassignProducts(ctx context.Context, in *ReassignProductsRequest, opts ...grpc.CallOption) (*ReassignProductsResponse, error)
 }
 
 type productServiceClient struct {
@@ -112,6 +138,16 @@ func (c *productServiceClient) ListProducts(ctx context.Context, in *ListProduct
 	return out, nil
 }
 
+func (c *productServiceClient) GetProductBySlug(ctx context.Context, in *GetProductBySlugRequest, opts ...grpc.CallOption) (*Product, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Product)
+	err := c.cc.Invoke(ctx, ProductService_GetProductBySlug_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *productServiceClient) CreateProductVariant(ctx context.Context, in *CreateProductVariantRequest, opts ...grpc.CallOption) (*ProductVariant, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ProductVariant)
@@ -172,6 +208,106 @@ func (c *productServiceClient) DeleteProductImage(ctx context.Context, in *Delet
 	return out, nil
 }
 
+func (c *productServiceClient) ReorderProductImages(ctx context.Context, in *ReorderProductImagesRequest, opts ...grpc.CallOption) (*ReorderProductImagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReorderProductImagesResponse)
+	err := c.cc.Invoke(ctx, ProductService_ReorderProductImages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) CreateBundle(ctx context.Context, in *CreateBundleRequest, opts ...grpc.CallOption) (*Bundle, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Bundle)
+	err := c.cc.Invoke(ctx, ProductService_CreateBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetBundle(ctx context.Context, in *GetBundleRequest, opts ...grpc.CallOption) (*Bundle, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Bundle)
+	err := c.cc.Invoke(ctx, ProductService_GetBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ListBundles(ctx context.Context, in *ListBundlesRequest, opts ...grpc.CallOption) (*ListBundlesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBundlesResponse)
+	err := c.cc.Invoke(ctx, ProductService_ListBundles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) DeleteBundle(ctx context.Context, in *DeleteBundleRequest, opts ...grpc.CallOption) (*DeleteProductResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProductResponse)
+	err := c.cc.Invoke(ctx, ProductService_DeleteBundle_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetRecommendations(ctx context.Context, in *GetRecommendationsRequest, opts ...grpc.CallOption) (*GetRecommendationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRecommendationsResponse)
+	err := c.cc.Invoke(ctx, ProductService_GetRecommendations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) SetRelatedProducts(ctx context.Context, in *SetRelatedProductsRequest, opts ...grpc.CallOption) (*SetRelatedProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetRelatedProductsResponse)
+	err := c.cc.Invoke(ctx, ProductService_SetRelatedProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) UpsertProductTranslation(ctx context.Context, in *ProductTranslation, opts ...grpc.CallOption) (*ProductTranslation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProductTranslation)
+	err := c.cc.Invoke(ctx, ProductService_UpsertProductTranslation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) CountProductsByCategory(ctx context.Context, in *CountProductsByCategoryRequest, opts ...grpc.CallOption) (*CountProductsByCategoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountProductsByCategoryResponse)
+	err := c.cc.Invoke(ctx, ProductService_CountProductsByCategory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ReassignProducts(ctx context.Context, in *ReassignProductsRequest, opts ...grpc.CallOption) (*ReassignProductsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReassignProductsResponse)
+	err := c.cc.Invoke(ctx, ProductService_ReassignProducts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations must embed UnimplementedProductServiceServer
 // for forward compatibility.
@@ -184,6 +320,7 @@ type ProductServiceServer interface {
 	UpdateProduct(context.Context, *UpdateProductRequest) (*Product, error)
 	DeleteProduct(context.Context, *DeleteProductRequest) (*DeleteProductResponse, error)
 	ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	GetProductBySlug(context.Context, *GetProductBySlugRequest) (*Product, error)
 	// Product Variant operations
 	CreateProductVariant(context.Context, *CreateProductVariantRequest) (*ProductVariant, error)
 	UpdateProductVariant(context.Context, *UpdateProductVariantRequest) (*ProductVariant, error)
@@ -192,6 +329,20 @@ type ProductServiceServer interface {
 	AddProductImage(context.Context, *AddProductImageRequest) (*ProductImage, error)
 	UpdateProductImage(context.Context, *UpdateProductImageRequest) (*ProductImage, error)
 	DeleteProductImage(context.Context, *DeleteProductImageRequest) (*DeleteProductResponse, error)
+	ReorderProductImages(context.Context, *ReorderProductImagesRequest) (*ReorderProductImagesResponse, error)
+	// Bundle operations
+	CreateBundle(context.Context, *CreateBundleRequest) (*Bundle, error)
+	GetBundle(context.Context, *GetBundleRequest) (*Bundle, error)
+	ListBundles(context.Context, *ListBundlesRequest) (*ListBundlesResponse, error)
+	DeleteBundle(context.Context, *DeleteBundleRequest) (*DeleteProductResponse, error)
+	// Recommendations
+	GetRecommendations(context.Context, *GetRecommendationsRequest) (*GetRecommendationsResponse, error)
+	SetRelatedProducts(context.Context, *SetRelatedProductsRequest) (*SetRelatedProductsResponse, error)
+	// Translations
+	UpsertProductTranslation(context.Context, *ProductTranslation) (*ProductTranslation, error)
+	// Category maintenance, used by the category service before deleting categories.
+	CountProductsByCategory(context.Context, *CountProductsByCategoryRequest) (*CountProductsByCategoryResponse, error)
+	ReassignProducts(context.Context, *ReassignProductsRequest) (*ReassignProductsResponse, error)
 	mustEmbedUnimplementedProductServiceServer()
 }
 
@@ -217,6 +368,9 @@ func (UnimplementedProductServiceServer) DeleteProduct(context.Context, *DeleteP
 func (UnimplementedProductServiceServer) ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListProducts not implemented")
 }
+func (UnimplementedProductServiceServer) GetProductBySlug(context.Context, *GetProductBySlugRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProductBySlug not implemented")
+}
 func (UnimplementedProductServiceServer) CreateProductVariant(context.Context, *CreateProductVariantRequest) (*ProductVariant, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateProductVariant not implemented")
 }
@@ -235,6 +389,36 @@ func (UnimplementedProductServiceServer) UpdateProductImage(context.Context, *Up
 func (UnimplementedProductServiceServer) DeleteProductImage(context.Context, *DeleteProductImageRequest) (*DeleteProductResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteProductImage not implemented")
 }
+func (UnimplementedProductServiceServer) ReorderProductImages(context.Context, *ReorderProductImagesRequest) (*ReorderProductImagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReorderProductImages not implemented")
+}
+func (UnimplementedProductServiceServer) CreateBundle(context.Context, *CreateBundleRequest) (*Bundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBundle not implemented")
+}
+func (UnimplementedProductServiceServer) GetBundle(context.Context, *GetBundleRequest) (*Bundle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBundle not implemented")
+}
+func (UnimplementedProductServiceServer) ListBundles(context.Context, *ListBundlesRequest) (*ListBundlesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBundles not implemented")
+}
+func (UnimplementedProductServiceServer) DeleteBundle(context.Context, *DeleteBundleRequest) (*DeleteProductResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBundle not implemented")
+}
+func (UnimplementedProductServiceServer) GetRecommendations(context.Context, *GetRecommendationsRequest) (*GetRecommendationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecommendations not implemented")
+}
+func (UnimplementedProductServiceServer) SetRelatedProducts(context.Context, *SetRelatedProductsRequest) (*SetRelatedProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRelatedProducts not implemented")
+}
+func (UnimplementedProductServiceServer) UpsertProductTranslation(context.Context, *ProductTranslation) (*ProductTranslation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertProductTranslation not implemented")
+}
+func (UnimplementedProductServiceServer) CountProductsByCategory(context.Context, *CountProductsByCategoryRequest) (*CountProductsByCategoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountProductsByCategory not implemented")
+}
+func (UnimplementedProductServiceServer) ReassignProducts(context.Context, *ReassignProductsRequest) (*ReassignProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReassignProducts not implemented")
+}
 func (UnimplementedProductServiceServer) mustEmbedUnimplementedProductServiceServer() {}
 func (UnimplementedProductServiceServer) testEmbeddedByValue()                        {}
 
@@ -346,6 +530,24 @@ func _ProductService_ListProducts_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_GetProductBySlug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetProductBySlugRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetProductBySlug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetProductBySlug_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetProductBySlug(ctx, req.(*GetProductBySlugRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProductService_CreateProductVariant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateProductVariantRequest)
 	if err := dec(in); err != nil {
@@ -454,6 +656,186 @@ func _ProductService_DeleteProductImage_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_ReorderProductImages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderProductImagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ReorderProductImages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ReorderProductImages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ReorderProductImages(ctx, req.(*ReorderProductImagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_CreateBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CreateBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_CreateBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CreateBundle(ctx, req.(*CreateBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetBundle(ctx, req.(*GetBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ListBundles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBundlesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ListBundles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ListBundles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ListBundles(ctx, req.(*ListBundlesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_DeleteBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).DeleteBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_DeleteBundle_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).DeleteBundle(ctx, req.(*DeleteBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetRecommendations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecommendationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetRecommendations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_GetRecommendations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetRecommendations(ctx, req.(*GetRecommendationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_SetRelatedProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRelatedProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).SetRelatedProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_SetRelatedProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).SetRelatedProducts(ctx, req.(*SetRelatedProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_UpsertProductTranslation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProductTranslation)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).UpsertProductTranslation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_UpsertProductTranslation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).UpsertProductTranslation(ctx, req.(*ProductTranslation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_CountProductsByCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountProductsByCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).CountProductsByCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_CountProductsByCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).CountProductsByCategory(ctx, req.(*CountProductsByCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ReassignProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReassignProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ReassignProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProductService_ReassignProducts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ReassignProducts(ctx, req.(*ReassignProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -481,6 +863,10 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListProducts",
 			Handler:    _ProductService_ListProducts_Handler,
 		},
+		{
+			MethodName: "GetProductBySlug",
+			Handler:    _ProductService_GetProductBySlug_Handler,
+		},
 		{
 			MethodName: "CreateProductVariant",
 			Handler:    _ProductService_CreateProductVariant_Handler,
@@ -505,6 +891,46 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteProductImage",
 			Handler:    _ProductService_DeleteProductImage_Handler,
 		},
+		{
+			MethodName: "ReorderProductImages",
+			Handler:    _ProductService_ReorderProductImages_Handler,
+		},
+		{
+			MethodName: "CreateBundle",
+			Handler:    _ProductService_CreateBundle_Handler,
+		},
+		{
+			MethodName: "GetBundle",
+			Handler:    _ProductService_GetBundle_Handler,
+		},
+		{
+			MethodName: "ListBundles",
+			Handler:    _ProductService_ListBundles_Handler,
+		},
+		{
+			MethodName: "DeleteBundle",
+			Handler:    _ProductService_DeleteBundle_Handler,
+		},
+		{
+			MethodName: "GetRecommendations",
+			Handler:    _ProductService_GetRecommendations_Handler,
+		},
+		{
+			MethodName: "SetRelatedProducts",
+			Handler:    _ProductService_SetRelatedProducts_Handler,
+		},
+		{
+			MethodName: "UpsertProductTranslation",
+			Handler:    _ProductService_UpsertProductTranslation_Handler,
+		},
+		{
+			MethodName: "CountProductsByCategory",
+			Handler:    _ProductService_CountProductsByCategory_Handler,
+		},
+		{
+			MethodName: "ReassignProducts",
+			Handler:    _ProductService_ReassignProducts_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc/pb/product.proto",
@@ -19,10 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	StockService_ChangeStock_FullMethodName            = "/pb.StockService/ChangeStock"
-	StockService_GetStock_FullMethodName               = "/pb.StockService/GetStock"
-	StockService_BatchGetStock_FullMethodName          = "/pb.StockService/BatchGetStock"
-	StockService_CheckStockAvailability_FullMethodName = "/pb.StockService/CheckStockAvailability"
+	StockService_ChangeStock_FullMethodName             = "/pb.StockService/ChangeStock"
+	StockService_GetStock_FullMethodName                = "/pb.StockService/GetStock"
+	StockService_BatchGetStock_FullMethodName           = "/pb.StockService/BatchGetStock"
+	StockService_CheckStockAvailability_FullMethodName  = "/pb.StockService/CheckStockAvailability"
+	StockService_CheckBundleAvailability_FullMethodName = "/pb.StockService/CheckBundleAvailability"
 )
 
 // StockServiceClient is the client API for StockService service.
@@ -37,6 +38,8 @@ type StockServiceClient interface {
 	BatchGetStock(ctx context.Context, in *BatchGetStockRequest, opts ...grpc.CallOption) (*BatchGetStockResponse, error)
 	// Mengecek ketersediaan stok
 	CheckStockAvailability(ctx context.Context, in *CheckStockAvailabilityRequest, opts ...grpc.CallOption) (*CheckStockAvailabilityResponse, error)
+	// Mengecek ketersediaan bundle dari stok komponennya
+	CheckBundleAvailability(ctx context.Context, in *CheckBundleAvailabilityRequest, opts ...grpc.CallOption) (*CheckStockAvailabilityResponse, error)
 }
 
 type stockServiceClient struct {
@@ -87,6 +90,16 @@ func (c *stockServiceClient) CheckStockAvailability(ctx context.Context, in *Che
 	return out, nil
 }
 
+func (c *stockServiceClient) CheckBundleAvailability(ctx context.Context, in *CheckBundleAvailabilityRequest, opts ...grpc.CallOption) (*CheckStockAvailabilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckStockAvailabilityResponse)
+	err := c.cc.Invoke(ctx, StockService_CheckBundleAvailability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StockServiceServer is the server API for StockService service.
 // All implementations must embed UnimplementedStockServiceServer
 // for forward compatibility.
@@ -99,6 +112,8 @@ type StockServiceServer interface {
 	BatchGetStock(context.Context, *BatchGetStockRequest) (*BatchGetStockResponse, error)
 	// Mengecek ketersediaan stok
 	CheckStockAvailability(context.Context, *CheckStockAvailabilityRequest) (*CheckStockAvailabilityResponse, error)
+	// Mengecek ketersediaan bundle dari stok komponennya
+	CheckBundleAvailability(context.Context, *CheckBundleAvailabilityRequest) (*CheckStockAvailabilityResponse, error)
 	mustEmbedUnimplementedStockServiceServer()
 }
 
@@ -121,6 +136,9 @@ func (UnimplementedStockServiceServer) BatchGetStock(context.Context, *BatchGetS
 func (UnimplementedStockServiceServer) CheckStockAvailability(context.Context, *CheckStockAvailabilityRequest) (*CheckStockAvailabilityResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CheckStockAvailability not implemented")
 }
+func (UnimplementedStockServiceServer) CheckBundleAvailability(context.Context, *CheckBundleAvailabilityRequest) (*CheckStockAvailabilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckBundleAvailability not implemented")
+}
 func (UnimplementedStockServiceServer) mustEmbedUnimplementedStockServiceServer() {}
 func (UnimplementedStockServiceServer) testEmbeddedByValue()                      {}
 
@@ -214,6 +232,24 @@ func _StockService_CheckStockAvailability_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StockService_CheckBundleAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckBundleAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StockServiceServer).CheckBundleAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StockService_CheckBundleAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StockServiceServer).CheckBundleAvailability(ctx, req.(*CheckBundleAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StockService_ServiceDesc is the grpc.ServiceDesc for StockService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -237,6 +273,10 @@ var StockService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckStockAvailability",
 			Handler:    _StockService_CheckStockAvailability_Handler,
 		},
+		{
+			MethodName: "CheckBundleAvailability",
+			Handler:    _StockService_CheckBundleAvailability_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc/pb/stock.proto",
@@ -7,11 +7,16 @@ import (
 
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"github.com/wafi04/golang-backend/services/auth/service"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type AuthHandler struct {
 	pb.UnimplementedAuthServiceServer
 	UserService *service.UserService
+	// SigningKeys are published by GetJWKS.
+	SigningKeys *middleware.KeySet
 }
 
 func (s *AuthHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
@@ -23,11 +28,13 @@ func (s *AuthHandler) CreateUser(ctx context.Context, req *pb.CreateUserRequest)
 	}
 
 	return &pb.CreateUserResponse{
-		UserId:    user.UserId,
-		Name:      user.Email,
-		Email:     user.Email,
-		Role:      user.Role,
-		CreatedAt: time.Now().Unix(),
+		UserId:      user.UserId,
+		Name:        user.Email,
+		Email:       user.Email,
+		Role:        user.Role,
+		CreatedAt:   time.Now().Unix(),
+		AccessToken: user.AccessToken,
+		SessionInfo: user.SessionInfo,
 	}, nil
 }
 func (s *AuthHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
@@ -40,6 +47,40 @@ func (s *AuthHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 
 	return user, nil
 }
+
+func (s *AuthHandler) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginResponse, error) {
+	log.Printf("Received verify login 2FA request")
+	return s.UserService.VerifyLoginMFA(ctx, req)
+}
+
+func (s *AuthHandler) EnrollMFA(ctx context.Context, req *pb.EnrollMFARequest) (*pb.EnrollMFAResponse, error) {
+	log.Printf("Received enroll 2FA request for user: %s", req.UserId)
+	return s.UserService.EnrollMFA(ctx, req)
+}
+
+func (s *AuthHandler) ConfirmMFA(ctx context.Context, req *pb.ConfirmMFARequest) (*pb.ConfirmMFAResponse, error) {
+	log.Printf("Received confirm 2FA request for user: %s", req.UserId)
+	return s.UserService.ConfirmMFA(ctx, req)
+}
+
+func (s *AuthHandler) DisableMFA(ctx context.Context, req *pb.DisableMFARequest) (*pb.DisableMFAResponse, error) {
+	log.Printf("Received disable 2FA request for user: %s", req.UserId)
+	return s.UserService.DisableMFA(ctx, req)
+}
+
+func (s *AuthHandler) ListOIDCProviders(ctx context.Context, req *pb.ListOIDCProvidersRequest) (*pb.ListOIDCProvidersResponse, error) {
+	return s.UserService.ListOIDCProviders(ctx, req)
+}
+
+func (s *AuthHandler) StartOIDCLogin(ctx context.Context, req *pb.StartOIDCLoginRequest) (*pb.StartOIDCLoginResponse, error) {
+	log.Printf("Received %s login request", req.Provider)
+	return s.UserService.StartOIDCLogin(ctx, req)
+}
+
+func (s *AuthHandler) CompleteOIDCLogin(ctx context.Context, req *pb.CompleteOIDCLoginRequest) (*pb.LoginResponse, error) {
+	log.Printf("Received %s login callback from IP: %s", req.Provider, req.IpAddress)
+	return s.UserService.CompleteOIDCLogin(ctx, req)
+}
 func (s *AuthHandler) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
 	log.Printf("Received verify email request for user: %v", req)
 
@@ -83,6 +124,26 @@ func (s *AuthHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Lo
 
 	return user, nil
 }
+func (s *AuthHandler) LogoutAll(ctx context.Context, req *pb.LogoutAllRequest) (*pb.LogoutAllResponse, error) {
+	log.Printf("Received logout all request for user: %s", req.UserId)
+	return s.UserService.LogoutAll(ctx, req)
+}
+func (s *AuthHandler) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	log.Printf("Received password reset request")
+	return s.UserService.RequestPasswordReset(ctx, req)
+}
+func (s *AuthHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	log.Printf("Received reset password request")
+	return s.UserService.ResetPassword(ctx, req)
+}
+func (s *AuthHandler) RequestMagicLink(ctx context.Context, req *pb.RequestMagicLinkRequest) (*pb.RequestMagicLinkResponse, error) {
+	log.Printf("Received magic link request")
+	return s.UserService.RequestMagicLink(ctx, req)
+}
+func (s *AuthHandler) ConsumeMagicLink(ctx context.Context, req *pb.ConsumeMagicLinkRequest) (*pb.LoginResponse, error) {
+	log.Printf("Received magic link login from IP: %s", req.IpAddress)
+	return s.UserService.ConsumeMagicLink(ctx, req)
+}
 func (s *AuthHandler) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse,error) {
 
 	user, err := s.UserService.RevokeSession(ctx, req)
@@ -94,11 +155,11 @@ func (s *AuthHandler) RevokeSession(ctx context.Context, req *pb.RevokeSessionRe
 
 
 func  (s *AuthHandler)   RefreshToken(ctx context.Context,req *pb.RefreshTokenRequest)(*pb.RefreshTokenResponse,error){
-	log.Printf("Received verify email request for user: %v", req)
+	log.Printf("Received refresh token request for session: %s", req.SessionId)
 
 	refresh, err := s.UserService.RefreshToken(ctx, req)
 	if err != nil {
-		log.Fatalf("Failed  to refresh token  :%v ",err)
+		log.Printf("Failed  to refresh token  :%v ",err)
 		return nil, err
 	}
 
@@ -116,3 +177,82 @@ func(s *AuthHandler)  ListSessions(ctx context.Context,req *pb.ListSessionsReque
 
 	return ListSessions,nil
 }
+
+func (s *AuthHandler) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	log.Printf("Received list roles request")
+	return s.UserService.ListRoles(ctx, req)
+}
+
+func (s *AuthHandler) UpsertRole(ctx context.Context, req *pb.Role) (*pb.Role, error) {
+	log.Printf("Received upsert role request: %v", req)
+	return s.UserService.UpsertRole(ctx, req)
+}
+
+func (s *AuthHandler) GetUserRoles(ctx context.Context, req *pb.GetUserRequest) (*pb.UserRolesResponse, error) {
+	log.Printf("Received get user roles request: %v", req)
+	return s.UserService.GetUserRoles(ctx, req)
+}
+
+func (s *AuthHandler) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	log.Printf("Received assign role request: %v", req)
+	return s.UserService.AssignRole(ctx, req)
+}
+
+func (s *AuthHandler) RevokeRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	log.Printf("Received revoke role request: %v", req)
+	return s.UserService.RevokeRole(ctx, req)
+}
+
+func (s *AuthHandler) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	log.Printf("Received unlock account request for user: %s", req.UserId)
+	return s.UserService.UnlockAccount(ctx, req)
+}
+
+func (s *AuthHandler) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	log.Printf("Received create API key request from user: %s", req.CreatedBy)
+	return s.UserService.CreateAPIKey(ctx, req)
+}
+
+func (s *AuthHandler) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	log.Printf("Received list API keys request")
+	return s.UserService.ListAPIKeys(ctx, req)
+}
+
+func (s *AuthHandler) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.APIKey, error) {
+	log.Printf("Received revoke API key request for key: %s", req.KeyId)
+	return s.UserService.RevokeAPIKey(ctx, req)
+}
+
+// ValidateAPIKey is called by the gateway for requests made with an API
+// key, so it does not log every call.
+func (s *AuthHandler) ValidateAPIKey(ctx context.Context, req *pb.ValidateAPIKeyRequest) (*pb.ValidateAPIKeyResponse, error) {
+	return s.UserService.ValidateAPIKey(ctx, req)
+}
+
+func (s *AuthHandler) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	log.Printf("Received update user request for user: %s", req.UserId)
+	return s.UserService.UpdateUser(ctx, req)
+}
+
+// GetJWKS returns the public keys access tokens are signed with, for the
+// gateway to publish and to verify tokens with.
+func (s *AuthHandler) GetJWKS(ctx context.Context, req *pb.GetJWKSRequest) (*pb.GetJWKSResponse, error) {
+	if s.SigningKeys == nil {
+		return nil, status.Errorf(codes.Unavailable, "signing keys not loaded")
+	}
+
+	resp := &pb.GetJWKSResponse{}
+	for _, key := range s.SigningKeys.JWKS().Keys {
+		resp.Keys = append(resp.Keys, &pb.JsonWebKey{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Alg: key.Alg,
+			Use: key.Use,
+			Crv: key.Crv,
+			X:   key.X,
+			N:   key.N,
+			E:   key.E,
+		})
+	}
+	return resp, nil
+}
@@ -0,0 +1,166 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSender struct{}
+
+func (failingSender) Send(ctx context.Context, msg Message) error {
+	return errors.New("connection refused")
+}
+
+func newTestMailer(t *testing.T, sender Sender) (*Mailer, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return New(sqlx.NewDb(db, "sqlmock"), sender), mock
+}
+
+func outboxRows(attempts int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "recipient", "subject", "text_body", "html_body", "attempts"}).
+		AddRow(1, "wafi@example.com", "Verify your email address", "text", "<p>html</p>", attempts)
+}
+
+func TestRenderTemplates(t *testing.T) {
+	cases := map[string]interface{}{
+		Verification:  VerificationData{Name: "Wafi", Code: "123456", ExpiresIn: "1 hour"},
+		PasswordReset: PasswordResetData{Name: "Wafi", Link: "https://example.com/reset?token=abc", ExpiresIn: "1 hour"},
+		Lockout:       LockoutData{Name: "Wafi", Until: "12:00 UTC on Jan 2"},
+		MagicLink:     MagicLinkData{Name: "Wafi", Link: "https://example.com/login?token=abc", ExpiresIn: "15 minutes"},
+	}
+	for name, data := range cases {
+		msg, err := Render(name, "wafi@example.com", data)
+		require.NoError(t, err, name)
+		assert.NotEmpty(t, msg.Subject, name)
+		assert.NotContains(t, msg.Subject, "\n", name)
+		assert.Contains(t, msg.Text, "Hi Wafi", name)
+		assert.Contains(t, msg.HTML, "<!DOCTYPE html>", name)
+	}
+
+	_, err := Render("unknown", "wafi@example.com", nil)
+	assert.Error(t, err)
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	msg, err := Render(Verification, "wafi@example.com", VerificationData{Name: "<script>", Code: "1"})
+	require.NoError(t, err)
+	assert.NotContains(t, msg.HTML, "<script>")
+	assert.Contains(t, msg.HTML, "&lt;script&gt;")
+}
+
+func TestDeliverMarksSent(t *testing.T) {
+	sender := &MemorySender{}
+	m, mock := newTestMailer(t, sender)
+
+	mock.ExpectQuery(`UPDATE email_outbox\s+SET next_attempt_at`).
+		WithArgs(batchSize, int64(claimLease.Seconds())).
+		WillReturnRows(outboxRows(0))
+	mock.ExpectExec(`SET sent_at = CURRENT_TIMESTAMP`).WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := m.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, sender.Messages(), 1)
+	assert.Equal(t, "wafi@example.com", sender.Messages()[0].To)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliverRetriesFailures(t *testing.T) {
+	m, mock := newTestMailer(t, failingSender{})
+
+	mock.ExpectQuery(`FROM email_outbox`).WillReturnRows(outboxRows(2))
+	mock.ExpectExec(`SET attempts = \$2, last_error = \$3, next_attempt_at`).
+		WithArgs(1, 3, "connection refused", int64(120)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err := m.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliverGivesUpAfterMaxAttempts(t *testing.T) {
+	m, mock := newTestMailer(t, failingSender{})
+
+	mock.ExpectQuery(`FROM email_outbox`).WillReturnRows(outboxRows(DefaultMaxAttempts - 1))
+	mock.ExpectExec(`SET failed_at = CURRENT_TIMESTAMP`).
+		WithArgs(1, DefaultMaxAttempts, "connection refused").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err := m.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeliverRecordsEachMessageOnItsOwn(t *testing.T) {
+	sender := &MemorySender{}
+	m, mock := newTestMailer(t, sender)
+
+	// Failing to record the first send neither unsends it nor stops the
+	// rest of the batch.
+	mock.ExpectQuery(`FROM email_outbox`).
+		WillReturnRows(outboxRows(0).AddRow(2, "other@example.com", "Reset your password", "text", "", 0))
+	mock.ExpectExec(`SET sent_at = CURRENT_TIMESTAMP`).WithArgs(1, 1).WillReturnError(errors.New("connection reset"))
+	mock.ExpectExec(`SET sent_at = CURRENT_TIMESTAMP`).WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := m.Deliver(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Len(t, sender.Messages(), 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSMTPSenderGivesUpOnHungServer(t *testing.T) {
+	// A server that accepts connections and never greets.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: addr.Port, From: "noreply@example.com"})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = sender.Send(ctx, Message{To: "wafi@example.com", Subject: "Hi", Text: "text"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestLogSenderWritesEML(t *testing.T) {
+	dir := t.TempDir()
+	sender := NewLogSender(dir, "noreply@example.com")
+
+	msg, err := Render(Lockout, "wafi@example.com", LockoutData{Name: "Wafi", Until: "12:00 UTC on Jan 2"})
+	require.NoError(t, err)
+	require.NoError(t, sender.Send(context.Background(), msg))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(raw), "From: noreply@example.com\r\nTo: wafi@example.com\r\n"))
+	assert.Contains(t, string(raw), "multipart/alternative")
+}
@@ -0,0 +1,190 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+const (
+	// DefaultMaxAttempts is how often delivery of a message is tried before
+	// it is given up.
+	DefaultMaxAttempts = 8
+
+	batchSize      = 20
+	retryBaseDelay = 30 * time.Second
+	retryMaxDelay  = time.Hour
+
+	// sendTimeout bounds the delivery of one message. claimLease is how
+	// long claimed messages are left to the worker that claimed them; it
+	// outlasts a batch of sends, so no other worker picks them up meanwhile.
+	sendTimeout = 30 * time.Second
+	claimLease  = 15 * time.Minute
+)
+
+// Mailer queues emails in the email_outbox table and delivers them in the
+// background. Queueing happens in the request, so an email is never lost
+// once the RPC that sends it succeeded, and delivery is retried with
+// exponential backoff.
+type Mailer struct {
+	db          *sqlx.DB
+	sender      Sender
+	logger      *common.Logger
+	MaxAttempts int
+}
+
+func New(db *sqlx.DB, sender Sender) *Mailer {
+	return &Mailer{
+		db:          db,
+		sender:      sender,
+		logger:      common.NewLogger(),
+		MaxAttempts: DefaultMaxAttempts,
+	}
+}
+
+// Send renders a template and queues the message for to.
+func (m *Mailer) Send(ctx context.Context, template, to string, data interface{}) error {
+	msg, err := Render(template, to, data)
+	if err != nil {
+		return err
+	}
+	return m.Enqueue(ctx, msg)
+}
+
+// Enqueue queues a message for delivery.
+func (m *Mailer) Enqueue(ctx context.Context, msg Message) error {
+	_, err := m.db.ExecContext(ctx, `
+		INSERT INTO email_outbox (recipient, subject, text_body, html_body, next_attempt_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)`,
+		msg.To, msg.Subject, msg.Text, msg.HTML)
+	if err != nil {
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+// Run delivers queued messages every interval until ctx is done.
+func (m *Mailer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				n, err := m.Deliver(ctx)
+				if err != nil {
+					m.logger.Log(common.ErrorLevel, "Failed to deliver emails: %v", err)
+				}
+				// A full batch means there may be more waiting.
+				if err != nil || n < batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+type outboxMessage struct {
+	id       int64
+	attempts int
+	msg      Message
+}
+
+// Deliver sends one batch of due messages and returns how many it tried.
+// The batch is claimed by pushing its next attempt out by claimLease, so
+// several replicas can deliver from the same outbox, and no row is locked
+// while messages are sent. Each result is recorded on its own; only a
+// worker that dies between sending and recording sends a message again,
+// once the lease ends. Bodies are cleared once sent: they can hold codes
+// and links that should not be kept around.
+func (m *Mailer) Deliver(ctx context.Context) (int, error) {
+	due, err := m.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, o := range due {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		sendErr := m.sender.Send(sendCtx, o.msg)
+		cancel()
+		if err := m.record(ctx, o, sendErr); err != nil {
+			m.logger.Log(common.ErrorLevel, "Failed to record delivery of email %d: %v", o.id, err)
+		}
+	}
+	return len(due), nil
+}
+
+// claim leases a batch of due messages to this worker.
+func (m *Mailer) claim(ctx context.Context) ([]outboxMessage, error) {
+	rows, err := m.db.QueryContext(ctx, `
+		UPDATE email_outbox
+		SET next_attempt_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE sent_at IS NULL AND failed_at IS NULL AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, recipient, subject, text_body, html_body, attempts`,
+		batchSize, int64(claimLease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var due []outboxMessage
+	for rows.Next() {
+		var o outboxMessage
+		if err := rows.Scan(&o.id, &o.msg.To, &o.msg.Subject, &o.msg.Text, &o.msg.HTML, &o.attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox: %w", err)
+		}
+		due = append(due, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox: %w", err)
+	}
+	return due, nil
+}
+
+// record stores the result of an attempt to send a claimed message.
+func (m *Mailer) record(ctx context.Context, o outboxMessage, sendErr error) error {
+	attempts := o.attempts + 1
+	var err error
+	switch {
+	case sendErr == nil:
+		_, err = m.db.ExecContext(ctx, `
+			UPDATE email_outbox
+			SET sent_at = CURRENT_TIMESTAMP, attempts = $2, last_error = NULL, text_body = '', html_body = ''
+			WHERE id = $1`, o.id, attempts)
+	case attempts >= m.MaxAttempts:
+		m.logger.Log(common.ErrorLevel, "Giving up on email %d to %s after %d attempts: %v", o.id, o.msg.To, attempts, sendErr)
+		_, err = m.db.ExecContext(ctx, `
+			UPDATE email_outbox
+			SET failed_at = CURRENT_TIMESTAMP, attempts = $2, last_error = $3
+			WHERE id = $1`, o.id, attempts, sendErr.Error())
+	default:
+		_, err = m.db.ExecContext(ctx, `
+			UPDATE email_outbox
+			SET attempts = $2, last_error = $3, next_attempt_at = CURRENT_TIMESTAMP + $4 * INTERVAL '1 second'
+			WHERE id = $1`, o.id, attempts, sendErr.Error(), int64(retryDelay(attempts).Seconds()))
+	}
+	return err
+}
+
+// retryDelay is the wait after the given number of failed attempts.
+func retryDelay(attempts int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates; each has a text body defining the subject and an HTML body
+// rendered into the shared layout.
+const (
+	Verification  = "verification"
+	PasswordReset = "password_reset"
+	Lockout       = "lockout"
+	MagicLink     = "magic_link"
+)
+
+// VerificationData fills the verification template.
+type VerificationData struct {
+	Name      string
+	Code      string
+	ExpiresIn string
+}
+
+// PasswordResetData fills the password reset template.
+type PasswordResetData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+// MagicLinkData fills the magic link template.
+type MagicLinkData struct {
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+// LockoutData fills the lockout template.
+type LockoutData struct {
+	Name  string
+	Until string
+}
+
+//go:embed templates
+var templateFS embed.FS
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templates = mustParseTemplates(Verification, PasswordReset, Lockout, MagicLink)
+
+func mustParseTemplates(names ...string) map[string]emailTemplate {
+	parsed := make(map[string]emailTemplate, len(names))
+	for _, name := range names {
+		text := texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/"+name+".txt"))
+		html := htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+		parsed[name] = emailTemplate{text: text, html: html}
+	}
+	return parsed
+}
+
+// Render renders a template into a message for to.
+func Render(name, to string, data interface{}) (Message, error) {
+	t, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s text: %w", name, err)
+	}
+	if err := t.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, fmt.Errorf("failed to render %s html: %w", name, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
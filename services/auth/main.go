@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,26 +15,200 @@ import (
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"github.com/wafi04/common/pkg/logger"
 	"github.com/wafi04/golang-backend/configs/database"
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"github.com/wafi04/golang-backend/services/auth/handler"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+	"github.com/wafi04/golang-backend/services/auth/oidc"
+	"github.com/wafi04/golang-backend/services/auth/password"
 	"github.com/wafi04/golang-backend/services/auth/repository"
 	"github.com/wafi04/golang-backend/services/auth/service"
+	"github.com/wafi04/golang-backend/services/auth/signing"
 	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
 	"google.golang.org/grpc"
 )
 
 type Config struct {
 	DatabaseURL string
 	Port        string
+	Passwords   password.Config
+	// AccessTokenTTL and RefreshTokenTTL override the token lifetimes.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	SigningKeys     signing.Config
+	// RedisAddr holds the session denylist; empty disables immediate
+	// revocation of access tokens.
+	RedisAddr     string
+	RedisPassword string
+	// MFAIssuer names the service in authenticator apps.
+	MFAIssuer string
+	Lockout   repository.LockoutPolicy
+	Mail      MailConfig
+	// PasswordResetURL is the frontend page reset links open.
+	PasswordResetURL string
+	// MagicLinkURL is the frontend page login links open. MagicLinkSecret
+	// signs them; without it links only work until the service restarts
+	// and only on the instance that sent them.
+	MagicLinkURL    string
+	MagicLinkSecret string
+	// Replicas is the number of instances the service runs as.
+	Replicas int
+	// OIDCProviders are the social login providers, by name.
+	OIDCProviders map[string]oidc.Config
+}
+
+// MailConfig selects how queued emails are delivered: "smtp", or "log"
+// (the default) to log them or, with LogDir, write them as .eml files.
+type MailConfig struct {
+	Transport string
+	SMTP      mailer.SMTPConfig
+	LogDir    string
 }
 
 func loadConfig() Config {
 	return Config{
-		DatabaseURL: common.LoadEnv("DATABASE_AUTH"),
-		Port:        common.LoadEnv("AUTH_PORT"),
+		DatabaseURL:      common.LoadEnv("DATABASE_AUTH"),
+		Port:             common.LoadEnv("AUTH_PORT"),
+		Passwords:        loadPasswordConfig(),
+		AccessTokenTTL:   loadDuration("ACCESS_TOKEN_TTL", middleware.AccessTokenTTL),
+		RefreshTokenTTL:  loadDuration("REFRESH_TOKEN_TTL", repository.DefaultRefreshTokenTTL),
+		SigningKeys:      loadSigningConfig(),
+		RedisAddr:        os.Getenv("REDIS_ADDR"),
+		RedisPassword:    os.Getenv("REDIS_PASSWORD"),
+		MFAIssuer:        os.Getenv("MFA_ISSUER"),
+		Lockout:          loadLockoutPolicy(),
+		Mail:             loadMailConfig(),
+		PasswordResetURL: os.Getenv("PASSWORD_RESET_URL"),
+		MagicLinkURL:     os.Getenv("MAGIC_LINK_URL"),
+		MagicLinkSecret:  os.Getenv("MAGIC_LINK_SECRET"),
+		Replicas:         loadReplicas(),
+		OIDCProviders:    loadOIDCProviders(),
+	}
+}
+
+// loadReplicas reads AUTH_REPLICAS, defaulting to a single instance.
+func loadReplicas() int {
+	if n, err := strconv.Atoi(os.Getenv("AUTH_REPLICAS")); err == nil && n > 0 {
+		return n
+	}
+	return 1
+}
+
+// loadMailConfig reads the MAIL_* and SMTP_* settings. APP_PASSWORD is
+// still accepted as the SMTP password.
+func loadMailConfig() MailConfig {
+	config := MailConfig{
+		Transport: os.Getenv("MAIL_TRANSPORT"),
+		SMTP: mailer.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     587,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		},
+		LogDir: os.Getenv("MAIL_LOG_DIR"),
+	}
+	if port, err := strconv.Atoi(os.Getenv("SMTP_PORT")); err == nil {
+		config.SMTP.Port = port
+	}
+	if config.SMTP.Password == "" {
+		config.SMTP.Password = strings.ReplaceAll(os.Getenv("APP_PASSWORD"), " ", "")
+	}
+	if config.SMTP.Username == "" {
+		config.SMTP.Username = config.SMTP.From
+	}
+	return config
+}
+
+func newMailSender(config MailConfig) mailer.Sender {
+	if config.Transport == "smtp" {
+		return mailer.NewSMTPSender(config.SMTP)
 	}
+	return mailer.NewLogSender(config.LogDir, config.SMTP.From)
+}
+
+// loadOIDCProviders reads the providers named in OIDC_PROVIDERS, each
+// configured by OIDC_<NAME>_* settings. Google's issuer is known.
+func loadOIDCProviders() map[string]oidc.Config {
+	providers := make(map[string]oidc.Config)
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		config := oidc.Config{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if config.Issuer == "" && name == "google" {
+			config.Issuer = oidc.GoogleIssuer
+		}
+		providers[name] = config
+	}
+	return providers
+}
+
+// loadLockoutPolicy reads the optional LOGIN_* throttling settings.
+func loadLockoutPolicy() repository.LockoutPolicy {
+	policy := repository.DefaultLockoutPolicy()
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_FREE_ATTEMPTS")); err == nil && n > 0 {
+		policy.FreeAttempts = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_THRESHOLD")); err == nil && n > 0 {
+		policy.LockoutThreshold = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LOGIN_IP_LOCKOUT_THRESHOLD")); err == nil && n > 0 {
+		policy.IPLockoutThreshold = n
+	}
+	policy.LockoutDuration = loadDuration("LOGIN_LOCKOUT_DURATION", policy.LockoutDuration)
+	return policy
+}
+
+// loadSigningConfig reads the optional JWT_SIGNING_* settings.
+func loadSigningConfig() signing.Config {
+	config := signing.DefaultConfig()
+	if algorithm := os.Getenv("JWT_SIGNING_ALGORITHM"); algorithm != "" {
+		config.Algorithm = algorithm
+	}
+	config.RotationInterval = loadDuration("JWT_SIGNING_KEY_ROTATION", config.RotationInterval)
+	config.Overlap = loadDuration("JWT_SIGNING_KEY_OVERLAP", config.Overlap)
+	return config
+}
+
+func loadDuration(key string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
+
+// loadPasswordConfig reads the optional PASSWORD_HASH_* settings; unset
+// values keep the defaults (bcrypt at its default cost).
+func loadPasswordConfig() password.Config {
+	config := password.DefaultConfig()
+	if algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM"); algorithm != "" {
+		config.Algorithm = algorithm
+	}
+	if cost, err := strconv.Atoi(os.Getenv("PASSWORD_HASH_BCRYPT_COST")); err == nil {
+		config.BcryptCost = cost
+	}
+	if memory, err := strconv.ParseUint(os.Getenv("PASSWORD_HASH_ARGON2_MEMORY_KB"), 10, 32); err == nil {
+		config.Argon2Memory = uint32(memory)
+	}
+	if iterations, err := strconv.ParseUint(os.Getenv("PASSWORD_HASH_ARGON2_TIME"), 10, 32); err == nil {
+		config.Argon2Time = uint32(iterations)
+	}
+	if threads, err := strconv.ParseUint(os.Getenv("PASSWORD_HASH_ARGON2_THREADS"), 10, 8); err == nil {
+		config.Argon2Threads = uint8(threads)
+	}
+	return config
 }
 
 var (
@@ -80,12 +256,80 @@ func main() {
 	health := db.Health()
 	log.Log(logger.InfoLevel, "Database health: %v", health["status"])
 
+	passwords, err := password.NewHasher(config.Passwords)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Invalid password hashing config: %v", err)
+		return
+	}
+
+	if config.SigningKeys.Overlap < config.AccessTokenTTL {
+		log.Log(logger.ErrorLevel, "JWT_SIGNING_KEY_OVERLAP must be at least ACCESS_TOKEN_TTL")
+		return
+	}
+	signingKeys, err := signing.NewManager(db.DB, config.SigningKeys)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Invalid signing key config: %v", err)
+		return
+	}
+	if err := signingKeys.Load(context.Background()); err != nil {
+		log.Log(logger.ErrorLevel, "Failed to load signing keys: %v", err)
+		return
+	}
+	middleware.SetSigningKeys(signingKeys.Keys())
+	keysCtx, stopKeys := context.WithCancel(context.Background())
+	defer stopKeys()
+	go signingKeys.Run(keysCtx, time.Minute)
+
 	userRepo := repository.NewUserRepository(db.DB)
+	userRepo.Passwords = passwords
+	userRepo.RefreshTokenTTL = config.RefreshTokenTTL
+	if config.MFAIssuer != "" {
+		userRepo.MFAIssuer = config.MFAIssuer
+	}
+	userRepo.Lockout = config.Lockout
+	if config.PasswordResetURL != "" {
+		userRepo.PasswordResetURL = config.PasswordResetURL
+	}
+	if config.MagicLinkURL != "" {
+		userRepo.MagicLinkURL = config.MagicLinkURL
+	}
+	if config.MagicLinkSecret != "" {
+		userRepo.MagicLinkKey = []byte(config.MagicLinkSecret)
+	} else if config.Replicas > 1 {
+		log.Log(logger.ErrorLevel, "MAGIC_LINK_SECRET must be set when running %d replicas, login links would only work on the instance that sent them", config.Replicas)
+		return
+	} else {
+		log.Log(logger.ErrorLevel, "MAGIC_LINK_SECRET not set, login links stop working when the service restarts")
+	}
+	userRepo.OIDC = oidc.NewRegistry()
+	for name, providerConfig := range config.OIDCProviders {
+		if providerConfig.Issuer == "" || providerConfig.ClientID == "" || providerConfig.RedirectURL == "" {
+			log.Log(logger.ErrorLevel, "Login provider %s needs an issuer, client id and redirect url, skipping it", name)
+			continue
+		}
+		userRepo.OIDC.Register(oidc.NewProvider(name, providerConfig))
+	}
+	userRepo.Mailer = mailer.New(db.DB, newMailSender(config.Mail))
+	mailCtx, stopMail := context.WithCancel(context.Background())
+	defer stopMail()
+	go userRepo.Mailer.Run(mailCtx, 5*time.Second)
+	middleware.AccessTokenTTL = config.AccessTokenTTL
+	if config.RedisAddr != "" {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     config.RedisAddr,
+			Password: config.RedisPassword,
+		})
+		defer redisClient.Close()
+		userRepo.Revocations = middleware.NewRevocationStore(redisClient, config.AccessTokenTTL)
+	} else {
+		log.Log(logger.InfoLevel, "REDIS_ADDR not set, revoked sessions stay valid until their access tokens expire")
+	}
 	userService := &service.UserService{
 		UserRepository: userRepo,
 	}
 	authHandler := &handler.AuthHandler{
 		UserService: userService,
+		SigningKeys: signingKeys.Keys(),
 	}
 
 	grpcServer := grpc.NewServer(
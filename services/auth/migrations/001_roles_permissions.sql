@@ -0,0 +1,45 @@
+-- Roles grant permissions; users get permissions through their roles.
+CREATE TABLE IF NOT EXISTS roles (
+    name         VARCHAR(64) PRIMARY KEY,
+    description  TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role        VARCHAR(64) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission  VARCHAR(128) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id     VARCHAR(255) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    role        VARCHAR(64) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role ON user_roles (role);
+
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'Full access, including managing roles'),
+    ('catalog_manager', 'Manages categories, products and bundles'),
+    ('inventory_manager', 'Adjusts stock'),
+    ('support', 'Reads all orders')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'catalog:write'),
+    ('admin', 'stock:adjust'),
+    ('admin', 'orders:read_all'),
+    ('admin', 'roles:manage'),
+    ('catalog_manager', 'catalog:write'),
+    ('inventory_manager', 'stock:adjust'),
+    ('support', 'orders:read_all')
+ON CONFLICT DO NOTHING;
+
+-- Keep existing admins. Further admins are assigned with the AssignRole RPC;
+-- on a fresh database grant the first one here by hand:
+--   INSERT INTO user_roles (user_id, role) VALUES ('<user id>', 'admin');
+INSERT INTO user_roles (user_id, role)
+SELECT user_id, 'admin' FROM users WHERE role = 'admin'
+ON CONFLICT DO NOTHING;
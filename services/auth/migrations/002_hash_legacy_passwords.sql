@@ -0,0 +1,14 @@
+-- Hash the passwords of accounts created before passwords were hashed, so
+-- the service no longer has to accept plaintext values. pgcrypto's "bf"
+-- salts produce bcrypt hashes the service verifies like its own, and they
+-- are upgraded to the configured algorithm on the next login.
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+UPDATE users
+SET password_hash = crypt(password_hash, gen_salt('bf', 10)),
+    updated_at = CURRENT_TIMESTAMP
+WHERE password_hash <> ''
+  AND password_hash NOT LIKE '$2a$%'
+  AND password_hash NOT LIKE '$2b$%'
+  AND password_hash NOT LIKE '$2y$%'
+  AND password_hash NOT LIKE '$argon2id$%';
@@ -0,0 +1,21 @@
+-- Opaque refresh tokens. Only the SHA-256 of a token is stored. Every use
+-- rotates the token: the presented one is marked used and a new one is
+-- issued in the same family. Presenting a used token again revokes the
+-- whole family.
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    token_hash   CHAR(64) PRIMARY KEY,
+    session_id   VARCHAR(255) NOT NULL REFERENCES sessions (session_id) ON DELETE CASCADE,
+    family_id    VARCHAR(255) NOT NULL,
+    user_id      VARCHAR(255) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at   TIMESTAMP NOT NULL,
+    used_at      TIMESTAMP,
+    revoked_at   TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session ON refresh_tokens (session_id);
+
+-- sessions.refresh_token used to hold a copy of the access token; it now
+-- holds the hash of the session's current refresh token.
+UPDATE sessions SET refresh_token = '';
@@ -0,0 +1,12 @@
+-- Keys access tokens are signed with. The newest key without not_after is
+-- the current one; keys it replaced stay published in the JWKS until
+-- not_after so tokens they signed remain valid until they expire.
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid          VARCHAR(64) PRIMARY KEY,
+    algorithm    VARCHAR(16) NOT NULL,
+    private_key  TEXT NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    not_after    TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_signing_keys_created_at ON signing_keys (created_at DESC);
@@ -0,0 +1,38 @@
+-- TOTP two-factor authentication. The secret has to be readable to check
+-- codes; recovery codes are only stored hashed.
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id         VARCHAR(255) PRIMARY KEY REFERENCES users (user_id) ON DELETE CASCADE,
+    secret          VARCHAR(64) NOT NULL,
+    confirmed_at    TIMESTAMP,
+    -- Last TOTP time step accepted, so a code cannot be replayed.
+    last_used_step  BIGINT NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    user_id    VARCHAR(255) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    code_hash  CHAR(64) NOT NULL,
+    used_at    TIMESTAMP,
+    PRIMARY KEY (user_id, code_hash)
+);
+
+-- Pending logins: the password was right, the second factor is missing.
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    challenge_hash  CHAR(64) PRIMARY KEY,
+    user_id         VARCHAR(255) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    device_info     TEXT NOT NULL DEFAULT '',
+    ip_address      VARCHAR(64) NOT NULL DEFAULT '',
+    attempts        INT NOT NULL DEFAULT 0,
+    expires_at      TIMESTAMP NOT NULL,
+    used_at         TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires_at ON mfa_challenges (expires_at);
+
+-- Sessions record whether the second factor was checked at login; roles
+-- that require it only grant their permissions to such sessions.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS mfa_verified BOOLEAN NOT NULL DEFAULT false;
+
+-- Require 2FA for a role with UpsertRole, or here, e.g.:
+--   UPDATE roles SET requires_mfa = true WHERE name = 'admin';
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS requires_mfa BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,15 @@
+-- Failed logins, counted per account (subject is the lowercased email, also
+-- for emails without an account) and per client IP (subject is the IP).
+CREATE TABLE IF NOT EXISTS login_attempts (
+    scope            VARCHAR(16) NOT NULL,
+    subject          VARCHAR(255) NOT NULL,
+    failures         INT NOT NULL DEFAULT 0,
+    last_failure_at  TIMESTAMP NOT NULL,
+    locked_until     TIMESTAMP,
+    PRIMARY KEY (scope, subject)
+);
+
+-- Admins unlock accounts with the UnlockAccount RPC.
+INSERT INTO role_permissions (role, permission) VALUES
+    ('admin', 'users:manage')
+ON CONFLICT DO NOTHING;
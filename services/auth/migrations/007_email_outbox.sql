@@ -0,0 +1,20 @@
+-- Emails waiting to be delivered. RPCs only insert here; a worker in the
+-- auth service sends them and retries failures with backoff.
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id               BIGSERIAL PRIMARY KEY,
+    recipient        VARCHAR(255) NOT NULL,
+    subject          TEXT NOT NULL,
+    -- Cleared once sent.
+    text_body        TEXT NOT NULL DEFAULT '',
+    html_body        TEXT NOT NULL DEFAULT '',
+    attempts         INT NOT NULL DEFAULT 0,
+    last_error       TEXT,
+    next_attempt_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at          TIMESTAMP,
+    -- Set when delivery was given up after too many attempts.
+    failed_at        TIMESTAMP,
+    created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_outbox_pending ON email_outbox (next_attempt_at)
+    WHERE sent_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,27 @@
+-- Social login with OpenID Connect providers such as Google.
+
+-- Logins sent to a provider and not back yet. The state is only stored
+-- hashed; the nonce and PKCE verifier are useless without it.
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash     CHAR(64) PRIMARY KEY,
+    provider       VARCHAR(64) NOT NULL,
+    nonce          VARCHAR(64) NOT NULL,
+    code_verifier  VARCHAR(128) NOT NULL,
+    expires_at     TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states (expires_at);
+
+-- Provider accounts linked to users. A user is found by the provider's
+-- subject, which unlike the email never changes.
+CREATE TABLE IF NOT EXISTS user_identities (
+    provider       VARCHAR(64) NOT NULL,
+    subject        VARCHAR(255) NOT NULL,
+    user_id        VARCHAR(255) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    email          VARCHAR(255) NOT NULL DEFAULT '',
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_login_at  TIMESTAMP,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities (user_id);
@@ -0,0 +1,25 @@
+-- API keys of service accounts and integrations such as the ERP and WMS.
+
+-- Keys are looked up by their prefix and only stored hashed; the secret is
+-- shown once, when the key is created.
+CREATE TABLE IF NOT EXISTS api_keys (
+    key_id        VARCHAR(255) PRIMARY KEY,
+    name          VARCHAR(255) NOT NULL,
+    prefix        VARCHAR(32) NOT NULL UNIQUE,
+    key_hash      CHAR(64) NOT NULL,
+    created_by    VARCHAR(255) REFERENCES users (user_id) ON DELETE SET NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at    TIMESTAMP,
+    last_used_at  TIMESTAMP,
+    last_used_ip  VARCHAR(64) NOT NULL DEFAULT '',
+    revoked_at    TIMESTAMP
+);
+
+-- Permissions a key grants, from the same set roles grant.
+CREATE TABLE IF NOT EXISTS api_key_scopes (
+    key_id  VARCHAR(255) NOT NULL REFERENCES api_keys (key_id) ON DELETE CASCADE,
+    scope   VARCHAR(128) NOT NULL,
+    PRIMARY KEY (key_id, scope)
+);
+
+INSERT INTO role_permissions (role, permission) VALUES ('admin', 'api_keys:manage') ON CONFLICT DO NOTHING;
@@ -0,0 +1,131 @@
+package oidc_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/services/auth/oidc"
+	"github.com/wafi04/golang-backend/services/auth/oidc/oidctest"
+)
+
+const redirectURL = "http://localhost:8080/api/v1/auth/oidc/mock/callback"
+
+func newTestServer(t *testing.T) *oidctest.Server {
+	server, err := oidctest.NewServer("client-1", "secret", oidctest.User{
+		Subject:       "1234567890",
+		Email:         "wafi@example.com",
+		EmailVerified: true,
+		Name:          "Wafi",
+		Picture:       "https://example.com/wafi.png",
+	})
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoginFlow(t *testing.T) {
+	server := newTestServer(t)
+	provider := server.Provider("mock", redirectURL)
+	ctx := context.Background()
+
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+	assert.Equal(t, redirectURL, parsed.Query().Get("redirect_uri"))
+
+	code, state, err := server.Authorize(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", claims.Subject)
+	assert.Equal(t, "wafi@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+	assert.Equal(t, "https://example.com/wafi.png", claims.Picture)
+
+	// Codes are single-use.
+	_, err = provider.Exchange(ctx, code, verifier, "nonce-1")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestExchangeRejectsWrongVerifier(t *testing.T) {
+	server := newTestServer(t)
+	provider := server.Provider("mock", redirectURL)
+	ctx := context.Background()
+
+	verifier, _ := oidc.NewCodeVerifier()
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	code, _, err := server.Authorize(authURL)
+	require.NoError(t, err)
+
+	other, _ := oidc.NewCodeVerifier()
+	_, err = provider.Exchange(ctx, code, other, "nonce-1")
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestExchangeRejectsWrongNonce(t *testing.T) {
+	server := newTestServer(t)
+	provider := server.Provider("mock", redirectURL)
+	ctx := context.Background()
+
+	verifier, _ := oidc.NewCodeVerifier()
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	code, _, err := server.Authorize(authURL)
+	require.NoError(t, err)
+
+	_, err = provider.Exchange(ctx, code, verifier, "nonce-2")
+	assert.ErrorContains(t, err, "nonce")
+}
+
+func TestExchangeRejectsOtherClientsTokens(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	// A client the provider knows, but whose tokens are not for us.
+	other := server.Provider("mock", redirectURL)
+	provider := oidc.NewProvider("mock", oidc.Config{
+		Issuer:      server.URL,
+		ClientID:    "client-2",
+		RedirectURL: redirectURL,
+	})
+
+	verifier, _ := oidc.NewCodeVerifier()
+	authURL, err := other.AuthCodeURL(ctx, "state-1", "nonce-1", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+	code, _, err := server.Authorize(authURL)
+	require.NoError(t, err)
+	claims, err := other.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	require.NotNil(t, claims)
+
+	_, err = provider.Exchange(ctx, code, verifier, "nonce-1")
+	assert.Error(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := oidc.NewRegistry(
+		oidc.NewProvider("mock", oidc.Config{}),
+		oidc.NewProvider("google", oidc.Config{Issuer: oidc.GoogleIssuer}),
+	)
+	assert.Equal(t, []string{"google", "mock"}, registry.Names())
+
+	_, ok := registry.Get("google")
+	assert.True(t, ok)
+	_, ok = registry.Get("github")
+	assert.False(t, ok)
+
+	var none *oidc.Registry
+	_, ok = none.Get("google")
+	assert.False(t, ok)
+}
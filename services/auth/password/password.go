@@ -0,0 +1,162 @@
+// Package password hashes and verifies user passwords.
+//
+// Hashes are stored self-describing: bcrypt hashes in their usual "$2a$"
+// form and argon2id hashes in the PHC string format
+// "$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>". Verify recognises both
+// whatever algorithm is configured, so stored passwords can be upgraded on
+// the next login. Anything else is refused; legacy plaintext passwords are
+// hashed by a migration.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+// Config selects the algorithm new hashes are created with and its cost.
+type Config struct {
+	Algorithm  string
+	BcryptCost int
+	// Argon2id parameters: memory in KiB, iterations and parallelism.
+	Argon2Memory  uint32
+	Argon2Time    uint32
+	Argon2Threads uint8
+}
+
+// DefaultConfig hashes with bcrypt at bcrypt.DefaultCost.
+func DefaultConfig() Config {
+	return Config{
+		Algorithm:     Bcrypt,
+		BcryptCost:    bcrypt.DefaultCost,
+		Argon2Memory:  64 * 1024,
+		Argon2Time:    3,
+		Argon2Threads: 2,
+	}
+}
+
+var errUnrecognizedHash = errors.New("unrecognized password hash")
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+type Hasher struct {
+	config Config
+}
+
+func NewHasher(config Config) (*Hasher, error) {
+	switch config.Algorithm {
+	case Bcrypt:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost %d", config.BcryptCost)
+		}
+	case Argon2id:
+		if config.Argon2Memory == 0 || config.Argon2Time == 0 || config.Argon2Threads == 0 {
+			return nil, errors.New("invalid argon2id parameters")
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", config.Algorithm)
+	}
+	return &Hasher{config: config}, nil
+}
+
+// Hash hashes a password with the configured algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == Argon2id {
+		salt := make([]byte, argon2SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("failed to generate salt: %w", err)
+		}
+		key := argon2.IDKey([]byte(password), salt, h.config.Argon2Time, h.config.Argon2Memory, h.config.Argon2Threads, argon2KeyLength)
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, h.config.Argon2Memory, h.config.Argon2Time, h.config.Argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// Verify reports whether password matches the stored hash, and whether the
+// hash should be replaced by a fresh one because it uses another algorithm
+// or other parameters.
+func (h *Hasher) Verify(password, stored string) (ok bool, needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(stored, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(stored)
+		if err != nil {
+			return false, false, err
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, uint32(len(key)))
+		if subtle.ConstantTimeCompare(candidate, key) != 1 {
+			return false, false, nil
+		}
+		rehash := h.config.Algorithm != Argon2id ||
+			params.Argon2Memory != h.config.Argon2Memory ||
+			params.Argon2Time != h.config.Argon2Time ||
+			params.Argon2Threads != h.config.Argon2Threads
+		return true, rehash, nil
+
+	case strings.HasPrefix(stored, "$2a$"), strings.HasPrefix(stored, "$2b$"), strings.HasPrefix(stored, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(stored), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, false, nil
+		}
+		if err != nil {
+			return false, false, err
+		}
+		cost, err := bcrypt.Cost([]byte(stored))
+		if err != nil {
+			return false, false, err
+		}
+		return true, h.config.Algorithm != Bcrypt || cost != h.config.BcryptCost, nil
+
+	default:
+		// A corrupted or truncated hash must not work as its own password.
+		return false, false, errUnrecognizedHash
+	}
+}
+
+func decodeArgon2id(stored string) (Config, []byte, []byte, error) {
+	var params Config
+	parts := strings.Split(stored, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2id version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Time, &params.Argon2Threads); err != nil {
+		return params, nil, nil, errors.New("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, errors.New("invalid argon2id salt")
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	return params, salt, key, nil
+}
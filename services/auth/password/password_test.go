@@ -0,0 +1,94 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fastConfig(algorithm string) Config {
+	config := DefaultConfig()
+	config.Algorithm = algorithm
+	config.BcryptCost = 4
+	config.Argon2Memory = 1024
+	config.Argon2Time = 1
+	config.Argon2Threads = 1
+	return config
+}
+
+func TestHashAndVerify(t *testing.T) {
+	for _, algorithm := range []string{Bcrypt, Argon2id} {
+		h, err := NewHasher(fastConfig(algorithm))
+		require.NoError(t, err)
+
+		hash, err := h.Hash("secret")
+		require.NoError(t, err)
+		assert.NotEqual(t, "secret", hash)
+
+		ok, rehash, err := h.Verify("secret", hash)
+		assert.NoError(t, err)
+		assert.True(t, ok, algorithm)
+		assert.False(t, rehash, algorithm)
+
+		ok, _, err = h.Verify("wrong", hash)
+		assert.NoError(t, err)
+		assert.False(t, ok, algorithm)
+	}
+}
+
+func TestVerifyRequestsRehash(t *testing.T) {
+	bcryptHasher, err := NewHasher(fastConfig(Bcrypt))
+	require.NoError(t, err)
+	argonHasher, err := NewHasher(fastConfig(Argon2id))
+	require.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.Hash("secret")
+	require.NoError(t, err)
+
+	// Switching algorithms upgrades on the next verify.
+	ok, rehash, err := argonHasher.Verify("secret", bcryptHash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+
+	// So does raising the cost.
+	config := fastConfig(Bcrypt)
+	config.BcryptCost = 5
+	stronger, err := NewHasher(config)
+	require.NoError(t, err)
+	ok, rehash, err = stronger.Verify("secret", bcryptHash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+}
+
+func TestVerifyRefusesUnrecognizedHashes(t *testing.T) {
+	h, err := NewHasher(fastConfig(Bcrypt))
+	require.NoError(t, err)
+
+	// Neither plaintext nor a truncated hash works as its own password.
+	for _, stored := range []string{"secret", "", "$2a$04$trunc"} {
+		ok, _, err := h.Verify(stored, stored)
+		assert.Error(t, err, stored)
+		assert.False(t, ok, stored)
+	}
+}
+
+func TestVerifyArgon2idFormat(t *testing.T) {
+	h, err := NewHasher(fastConfig(Argon2id))
+	require.NoError(t, err)
+
+	hash, err := h.Hash("secret")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	_, _, err = h.Verify("secret", "$argon2id$v=19$broken")
+	assert.Error(t, err)
+}
+
+func TestNewHasherRejectsUnknownAlgorithm(t *testing.T) {
+	_, err := NewHasher(Config{Algorithm: "md5"})
+	assert.Error(t, err)
+}
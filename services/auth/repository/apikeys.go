@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// A key is "gbk_<id>_<secret>" with a hex id of apiKeyIDLength; the
+	// part before the secret is stored as its prefix to find it by.
+	apiKeyIDLength = 12
+	// apiKeyUsageInterval is how often the last use of a key is recorded,
+	// so busy integrations do not write on every request.
+	apiKeyUsageInterval = time.Minute
+	// apiKeyPrincipalPrefix starts the user id of the principal a key acts
+	// as, keeping it apart from user ids.
+	apiKeyPrincipalPrefix = "apikey:"
+)
+
+var (
+	errInvalidAPIKey  = status.Error(codes.Unauthenticated, "invalid, expired or revoked API key")
+	errAPIKeyNotFound = status.Error(codes.NotFound, "API key not found")
+)
+
+// apiKeysQuery lists keys with one row per scope; a WHERE clause is
+// appended to it.
+const apiKeysQuery = `
+	SELECT
+		k.key_id, k.name, k.prefix, COALESCE(k.created_by, ''),
+		EXTRACT(EPOCH FROM k.created_at)::bigint,
+		COALESCE(EXTRACT(EPOCH FROM k.expires_at)::bigint, 0),
+		COALESCE(EXTRACT(EPOCH FROM k.last_used_at)::bigint, 0),
+		k.last_used_ip,
+		COALESCE(EXTRACT(EPOCH FROM k.revoked_at)::bigint, 0),
+		COALESCE(s.scope, '')
+	FROM api_keys k
+	LEFT JOIN api_key_scopes s ON s.key_id = k.key_id`
+
+// CreateAPIKey issues a key for a service account or integration. The key
+// is returned once and only its hash is stored. It cannot grant scopes its
+// creator does not hold.
+func (r *UserRepository) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid API key: name is required")
+	}
+	scopes := uniqueSorted(req.Scopes)
+	if len(scopes) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid API key: at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !permissionPattern.MatchString(scope) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid scope %q: expected resource:action", scope)
+		}
+	}
+	var expiresAt *time.Time
+	if req.ExpiresAt != 0 {
+		t := time.Unix(req.ExpiresAt, 0)
+		if !t.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "invalid API key: expiry is in the past")
+		}
+		expiresAt = &t
+	}
+
+	_, held, err := r.UserPermissions(ctx, req.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !middleware.HasPermissions(held, scopes...) {
+		return nil, status.Error(codes.PermissionDenied, "cannot grant scopes you do not hold")
+	}
+
+	key, prefix, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	keyID := uuid.New().String()
+	now := time.Now()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO api_keys (key_id, name, prefix, key_hash, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		keyID, name, prefix, hashToken(key), req.CreatedBy, now, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save API key: %w", err)
+	}
+	for _, scope := range scopes {
+		_, err := tx.ExecContext(ctx, "INSERT INTO api_key_scopes (key_id, scope) VALUES ($1, $2)", keyID, scope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save API key scopes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.logger.Log(common.InfoLevel, "API key %s (%s) created by %s", prefix, name, req.CreatedBy)
+
+	return &pb.CreateAPIKeyResponse{
+		ApiKey: &pb.APIKey{
+			KeyId:     keyID,
+			Name:      name,
+			Prefix:    prefix,
+			Scopes:    scopes,
+			CreatedBy: req.CreatedBy,
+			CreatedAt: now.Unix(),
+			ExpiresAt: req.ExpiresAt,
+		},
+		Key: key,
+	}, nil
+}
+
+func (r *UserRepository) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	keys, err := r.queryAPIKeys(ctx, `
+		WHERE $1 OR k.revoked_at IS NULL
+		ORDER BY k.created_at DESC, k.key_id, s.scope`,
+		req.IncludeRevoked)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListAPIKeysResponse{ApiKeys: keys}, nil
+}
+
+// RevokeAPIKey stops a key from being accepted. Revoking a key twice keeps
+// the time of the first revocation.
+func (r *UserRepository) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.APIKey, error) {
+	result, err := r.DB.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP) WHERE key_id = $1",
+		req.KeyId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, errAPIKeyNotFound
+	}
+
+	keys, err := r.queryAPIKeys(ctx, "WHERE k.key_id = $1 ORDER BY s.scope", req.KeyId)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, errAPIKeyNotFound
+	}
+	r.logger.Log(common.InfoLevel, "API key %s (%s) revoked", keys[0].Prefix, keys[0].Name)
+	return keys[0], nil
+}
+
+// ValidateAPIKey returns the service principal a key acts as: a user with
+// the service role and the key's scopes as permissions. The use is
+// recorded against the key.
+func (r *UserRepository) ValidateAPIKey(ctx context.Context, req *pb.ValidateAPIKeyRequest) (*pb.ValidateAPIKeyResponse, error) {
+	prefix, ok := apiKeyPrefixOf(req.Key)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+
+	var keyID, name, keyHash string
+	var active bool
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT key_id, name, key_hash,
+			revoked_at IS NULL AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		FROM api_keys
+		WHERE prefix = $1`,
+		prefix).Scan(&keyID, &name, &keyHash, &active)
+	if err == sql.ErrNoRows {
+		return nil, errInvalidAPIKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(hashToken(req.Key))) != 1 || !active {
+		return nil, errInvalidAPIKey
+	}
+
+	rows, err := r.DB.QueryContext(ctx, "SELECT scope FROM api_key_scopes WHERE key_id = $1 ORDER BY scope", keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key scopes: %w", err)
+	}
+	defer rows.Close()
+	scopes := []string{}
+	for rows.Next() {
+		var scope string
+		if err := rows.Scan(&scope); err != nil {
+			return nil, fmt.Errorf("failed to scan API key scope: %w", err)
+		}
+		scopes = append(scopes, scope)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get API key scopes: %w", err)
+	}
+
+	// Failing to record the use does not fail the request.
+	_, err = r.DB.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP, last_used_ip = $2
+		WHERE key_id = $1
+			AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - $3 * INTERVAL '1 second')`,
+		keyID, req.IpAddress, int64(apiKeyUsageInterval.Seconds()))
+	if err != nil {
+		r.logger.Log(common.WarnLevel, "Failed to record use of API key %s: %v", prefix, err)
+	}
+
+	return &pb.ValidateAPIKeyResponse{
+		KeyId: keyID,
+		Principal: &pb.UserInfo{
+			UserId:      apiKeyPrincipalPrefix + keyID,
+			Name:        name,
+			Role:        middleware.ServiceRole,
+			Roles:       []string{middleware.ServiceRole},
+			Permissions: scopes,
+		},
+	}, nil
+}
+
+// queryAPIKeys runs apiKeysQuery with a WHERE clause and groups the rows
+// by key.
+func (r *UserRepository) queryAPIKeys(ctx context.Context, where string, args ...interface{}) ([]*pb.APIKey, error) {
+	rows, err := r.DB.QueryContext(ctx, apiKeysQuery+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*pb.APIKey{}
+	for rows.Next() {
+		var key pb.APIKey
+		var scope string
+		err := rows.Scan(
+			&key.KeyId, &key.Name, &key.Prefix, &key.CreatedBy,
+			&key.CreatedAt, &key.ExpiresAt, &key.LastUsedAt, &key.LastUsedIp, &key.RevokedAt,
+			&scope,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		if len(keys) == 0 || keys[len(keys)-1].KeyId != key.KeyId {
+			keys = append(keys, &key)
+		}
+		if scope != "" {
+			last := keys[len(keys)-1]
+			last.Scopes = append(last.Scopes, scope)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// newAPIKey returns a new key and the prefix it is found by.
+func newAPIKey() (string, string, error) {
+	id := make([]byte, apiKeyIDLength/2)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	prefix := middleware.APIKeyPrefix + hex.EncodeToString(id)
+	return prefix + "_" + secret, prefix, nil
+}
+
+// apiKeyPrefixOf returns the prefix of a well-formed key.
+func apiKeyPrefixOf(key string) (string, bool) {
+	n := len(middleware.APIKeyPrefix) + apiKeyIDLength
+	if !strings.HasPrefix(key, middleware.APIKeyPrefix) || len(key) <= n+1 || key[n] != '_' {
+		return "", false
+	}
+	return key[:n], true
+}
@@ -0,0 +1,162 @@
+package repository_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+func expectAdminPermissions(mock sqlmock.Sqlmock, permissions ...string) {
+	rows := sqlmock.NewRows([]string{"role", "permission", "requires_mfa"})
+	for _, permission := range permissions {
+		rows.AddRow("admin", permission, false)
+	}
+	mock.ExpectQuery(`FROM user_roles`).
+		WithArgs("user-123").
+		WillReturnRows(rows)
+}
+
+func TestCreateAndValidateAPIKey(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	prefix, keyHash := &capture{}, &capture{}
+	expectAdminPermissions(mock, "api_keys:manage", "stock:adjust")
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO api_keys`).
+		WithArgs(sqlmock.AnyArg(), "WMS", prefix, keyHash, "user-123", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO api_key_scopes`).
+		WithArgs(sqlmock.AnyArg(), "stock:adjust").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateAPIKey(context.Background(), &pb.CreateAPIKeyRequest{
+		Name:      " WMS ",
+		Scopes:    []string{"stock:adjust", "stock:adjust"},
+		CreatedBy: "user-123",
+	})
+	require.NoError(t, err)
+
+	// Only the hash of the key is stored; the prefix identifies it.
+	sum := sha256.Sum256([]byte(created.Key))
+	assert.Equal(t, hex.EncodeToString(sum[:]), keyHash.value)
+	assert.True(t, strings.HasPrefix(created.Key, prefix.value+"_"))
+	assert.Equal(t, prefix.value, created.ApiKey.Prefix)
+	assert.Equal(t, []string{"stock:adjust"}, created.ApiKey.Scopes)
+
+	mock.ExpectQuery(`FROM api_keys\s+WHERE prefix = \$1`).
+		WithArgs(prefix.value).
+		WillReturnRows(sqlmock.NewRows([]string{"key_id", "name", "key_hash", "active"}).
+			AddRow(created.ApiKey.KeyId, "WMS", keyHash.value, true))
+	mock.ExpectQuery(`FROM api_key_scopes`).
+		WithArgs(created.ApiKey.KeyId).
+		WillReturnRows(sqlmock.NewRows([]string{"scope"}).AddRow("stock:adjust"))
+	mock.ExpectExec(`UPDATE api_keys SET last_used_at`).
+		WithArgs(created.ApiKey.KeyId, "10.0.0.5", int64(60)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := repo.ValidateAPIKey(context.Background(), &pb.ValidateAPIKeyRequest{
+		Key:       created.Key,
+		IpAddress: "10.0.0.5",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "apikey:"+created.ApiKey.KeyId, resp.Principal.UserId)
+	assert.Equal(t, "service", resp.Principal.Role)
+	assert.Equal(t, []string{"stock:adjust"}, resp.Principal.Permissions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPIKeyRefusesScopesNotHeld(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	expectAdminPermissions(mock, "api_keys:manage")
+
+	_, err := repo.CreateAPIKey(context.Background(), &pb.CreateAPIKeyRequest{
+		Name:      "ERP",
+		Scopes:    []string{"orders:read_all"},
+		CreatedBy: "user-123",
+	})
+	assert.ErrorContains(t, err, "cannot grant scopes")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPIKeyValidatesRequest(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	cases := map[string]*pb.CreateAPIKeyRequest{
+		"no name":   {Scopes: []string{"stock:adjust"}},
+		"no scopes": {Name: "ERP"},
+		"bad scope": {Name: "ERP", Scopes: []string{"Stock"}},
+		"expired":   {Name: "ERP", Scopes: []string{"stock:adjust"}, ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+	}
+	for name, req := range cases {
+		_, err := repo.CreateAPIKey(context.Background(), req)
+		assert.ErrorContains(t, err, "invalid", name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateAPIKeyRejectsRevokedAndForgedKeys(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	key := "gbk_0123456789ab_secret"
+	sum := sha256.Sum256([]byte(key))
+	keyHash := hex.EncodeToString(sum[:])
+
+	// Malformed keys are refused without a query.
+	_, err := repo.ValidateAPIKey(context.Background(), &pb.ValidateAPIKeyRequest{Key: "gbk_short"})
+	assert.ErrorContains(t, err, "invalid, expired or revoked API key")
+
+	mock.ExpectQuery(`FROM api_keys\s+WHERE prefix = \$1`).
+		WithArgs("gbk_0123456789ab").
+		WillReturnRows(sqlmock.NewRows([]string{"key_id", "name", "key_hash", "active"}).
+			AddRow("key-1", "ERP", keyHash, false))
+	_, err = repo.ValidateAPIKey(context.Background(), &pb.ValidateAPIKeyRequest{Key: key})
+	assert.ErrorContains(t, err, "invalid, expired or revoked API key")
+
+	mock.ExpectQuery(`FROM api_keys\s+WHERE prefix = \$1`).
+		WithArgs("gbk_0123456789ab").
+		WillReturnRows(sqlmock.NewRows([]string{"key_id", "name", "key_hash", "active"}).
+			AddRow("key-1", "ERP", keyHash, true))
+	_, err = repo.ValidateAPIKey(context.Background(), &pb.ValidateAPIKeyRequest{Key: "gbk_0123456789ab_forged"})
+	assert.ErrorContains(t, err, "invalid, expired or revoked API key")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+		WithArgs("key-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`FROM api_keys k`).
+		WithArgs("key-1").
+		WillReturnRows(sqlmock.NewRows([]string{"key_id", "name", "prefix", "created_by", "created_at", "expires_at", "last_used_at", "last_used_ip", "revoked_at", "scope"}).
+			AddRow("key-1", "ERP", "gbk_0123456789ab", "user-123", int64(100), int64(0), int64(150), "10.0.0.5", int64(200), "orders:read_all").
+			AddRow("key-1", "ERP", "gbk_0123456789ab", "user-123", int64(100), int64(0), int64(150), "10.0.0.5", int64(200), "stock:adjust"))
+
+	key, err := repo.RevokeAPIKey(context.Background(), &pb.RevokeAPIKeyRequest{KeyId: "key-1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), key.RevokedAt)
+	assert.Equal(t, []string{"orders:read_all", "stock:adjust"}, key.Scopes)
+
+	mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = repo.RevokeAPIKey(context.Background(), &pb.RevokeAPIKeyRequest{KeyId: "missing"})
+	assert.ErrorContains(t, err, "API key not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+	"github.com/wafi04/golang-backend/services/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Failed logins are counted per account (by email, whether or not it
+// belongs to a user, so lockouts do not reveal which emails exist) and per
+// client IP.
+const (
+	scopeAccount = "account"
+	scopeIP      = "ip"
+)
+
+// LockoutPolicy decides how failed logins are throttled. After the free
+// attempts each further attempt has to wait BaseDelay, doubling with every
+// failure up to MaxDelay; at the lockout threshold logins are refused for
+// LockoutDuration. Counters are forgotten Window after the last failure.
+type LockoutPolicy struct {
+	FreeAttempts     int
+	LockoutThreshold int
+	// IP limits are higher: many users can share an address.
+	IPFreeAttempts     int
+	IPLockoutThreshold int
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	LockoutDuration    time.Duration
+	Window             time.Duration
+}
+
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		FreeAttempts:       3,
+		LockoutThreshold:   10,
+		IPFreeAttempts:     20,
+		IPLockoutThreshold: 100,
+		BaseDelay:          time.Second,
+		MaxDelay:           5 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+		Window:             time.Hour,
+	}
+}
+
+func (p LockoutPolicy) limits(scope string) (free, lockout int) {
+	if scope == scopeIP {
+		return p.IPFreeAttempts, p.IPLockoutThreshold
+	}
+	return p.FreeAttempts, p.LockoutThreshold
+}
+
+// delay is how long to wait after the last of failures before the next
+// attempt.
+func (p LockoutPolicy) delay(scope string, failures int) time.Duration {
+	free, _ := p.limits(scope)
+	if failures < free {
+		return 0
+	}
+	exp := failures - free
+	if exp > 30 {
+		return p.MaxDelay
+	}
+	delay := p.BaseDelay << uint(exp)
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+var (
+	errInvalidCredentials = status.Error(codes.Unauthenticated, "invalid credentials")
+	errAccountDeactivated = status.Error(codes.PermissionDenied, "account is deactivated")
+)
+
+func errTooManyAttempts(wait time.Duration) error {
+	return status.Errorf(codes.ResourceExhausted, "too many login attempts, retry in %d seconds", int(math.Ceil(wait.Seconds())))
+}
+
+func errAccountLocked(wait time.Duration) error {
+	return status.Errorf(codes.ResourceExhausted, "account temporarily locked, retry in %d seconds", int(math.Ceil(wait.Seconds())))
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+type loginSubject struct{ scope, value string }
+
+// loginSubjects are the counters a login attempt is counted against.
+func loginSubjects(email, ipAddress string) []loginSubject {
+	subjects := []loginSubject{{scopeAccount, normalizeEmail(email)}}
+	if ipAddress != "" {
+		subjects = append(subjects, loginSubject{scopeIP, ipAddress})
+	}
+	return subjects
+}
+
+// claimLoginAttempt counts a login attempt against the account and the IP
+// before the credentials are checked, and refuses it while either is locked
+// or backing off. The counters are locked while they are checked and
+// incremented, so concurrent attempts are counted one after the other
+// instead of all passing the same check. Successful logins give the attempt
+// back with refundLoginAttempt or clearLoginFailures.
+func (r *UserRepository) claimLoginAttempt(ctx context.Context, email, ipAddress string) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, subject := range loginSubjects(email, ipAddress) {
+		var (
+			failures      int
+			since, locked float64
+		)
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO login_attempts (scope, subject, failures, last_failure_at)
+			VALUES ($1, $2, 0, CURRENT_TIMESTAMP)
+			ON CONFLICT (scope, subject) DO UPDATE SET scope = EXCLUDED.scope
+			RETURNING
+				failures,
+				EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - last_failure_at))::float8,
+				COALESCE(EXTRACT(EPOCH FROM (locked_until - CURRENT_TIMESTAMP)), 0)::float8`,
+			subject.scope, subject.value).Scan(&failures, &since, &locked)
+		if err != nil {
+			return fmt.Errorf("failed to check login attempts: %w", err)
+		}
+
+		if locked > 0 {
+			wait := time.Duration(locked * float64(time.Second))
+			if subject.scope == scopeAccount {
+				return errAccountLocked(wait)
+			}
+			return errTooManyAttempts(wait)
+		}
+		elapsed := time.Duration(since * float64(time.Second))
+		if elapsed >= r.Lockout.Window {
+			failures = 0
+		} else if wait := r.Lockout.delay(subject.scope, failures) - elapsed; wait > 0 {
+			return errTooManyAttempts(wait)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE login_attempts SET failures = $3, last_failure_at = CURRENT_TIMESTAMP
+			WHERE scope = $1 AND subject = $2`,
+			subject.scope, subject.value, failures+1)
+		if err != nil {
+			return fmt.Errorf("failed to count login attempt: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// recordLoginFailure locks the account or IP when the failed attempt,
+// already counted by claimLoginAttempt, brings it to its threshold. user is
+// nil when the email is unknown. The lock restarts the count, so a locked
+// account gets its free attempts back once the lock ends.
+func (r *UserRepository) recordLoginFailure(ctx context.Context, email, ipAddress string, user *pb.UserInfo) {
+	for _, subject := range loginSubjects(email, ipAddress) {
+		locked, err := r.lockAtThreshold(ctx, subject.scope, subject.value)
+		if err != nil {
+			r.logger.Log(common.ErrorLevel, "Failed to record login failure: %v", err)
+			continue
+		}
+		if !locked {
+			continue
+		}
+		r.logger.Log(common.WarnLevel, "Login locked for %s %s after repeated failures", subject.scope, subject.value)
+		if subject.scope == scopeAccount && user != nil && r.Mailer != nil {
+			until := time.Now().Add(r.Lockout.LockoutDuration)
+			err := r.Mailer.Send(ctx, mailer.Lockout, user.Email, mailer.LockoutData{
+				Name:  user.Name,
+				Until: until.UTC().Format("15:04 MST on Jan 2"),
+			})
+			if err != nil {
+				r.logger.Log(common.ErrorLevel, "Failed to queue lockout email: %v", err)
+			}
+		}
+	}
+}
+
+func (r *UserRepository) lockAtThreshold(ctx context.Context, scope, subject string) (bool, error) {
+	_, threshold := r.Lockout.limits(scope)
+	res, err := r.DB.ExecContext(ctx, `
+		UPDATE login_attempts
+		SET failures = 0, locked_until = CURRENT_TIMESTAMP + $3 * INTERVAL '1 second'
+		WHERE scope = $1 AND subject = $2 AND failures >= $4`,
+		scope, subject, int64(r.Lockout.LockoutDuration.Seconds()), threshold)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// refundLoginAttempt gives back an attempt counted by claimLoginAttempt
+// whose credentials were right.
+func (r *UserRepository) refundLoginAttempt(ctx context.Context, email, ipAddress string) {
+	for _, subject := range loginSubjects(email, ipAddress) {
+		r.refundAttempt(ctx, subject)
+	}
+}
+
+func (r *UserRepository) refundAttempt(ctx context.Context, subject loginSubject) {
+	_, err := r.DB.ExecContext(ctx, `
+		UPDATE login_attempts SET failures = GREATEST(failures - 1, 0)
+		WHERE scope = $1 AND subject = $2`,
+		subject.scope, subject.value)
+	if err != nil {
+		r.logger.Log(common.ErrorLevel, "Failed to refund login attempt: %v", err)
+	}
+}
+
+// clearLoginFailures forgets an account's failures after a successful
+// login. The IP only gets this attempt back; one valid account must not
+// reset the limit for guessing others from the same address.
+func (r *UserRepository) clearLoginFailures(ctx context.Context, email, ipAddress string) {
+	_, err := r.DB.ExecContext(ctx, "DELETE FROM login_attempts WHERE scope = $1 AND subject = $2",
+		scopeAccount, normalizeEmail(email))
+	if err != nil {
+		r.logger.Log(common.ErrorLevel, "Failed to clear login failures: %v", err)
+	}
+	if ipAddress != "" {
+		r.refundAttempt(ctx, loginSubject{scopeIP, ipAddress})
+	}
+}
+
+// UnlockAccount lifts a lockout and clears the failed login count of a
+// user.
+func (r *UserRepository) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	var email string
+	err := r.DB.QueryRowContext(ctx, "SELECT email FROM users WHERE user_id = $1", req.UserId).Scan(&email)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	res, err := r.DB.ExecContext(ctx, "DELETE FROM login_attempts WHERE scope = $1 AND subject = $2",
+		scopeAccount, normalizeEmail(email))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock account: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	return &pb.UnlockAccountResponse{
+		Success:         true,
+		ClearedAttempts: n > 0,
+	}, nil
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// verifyDummyPassword spends the time of a password check on an unknown
+// email, so response times do not tell which emails have accounts.
+func (r *UserRepository) verifyDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = r.Passwords.Hash("not the password of any account")
+	})
+	r.Passwords.Verify(password, dummyHash)
+}
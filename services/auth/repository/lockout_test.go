@@ -0,0 +1,172 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var loginAttemptColumns = []string{"failures", "since", "locked"}
+
+// expectLoginClaimed expects an attempt to be counted against the account
+// and the IP, neither of which had recent failures.
+func expectLoginClaimed(mock sqlmock.Sqlmock, email, ip string) {
+	mock.ExpectBegin()
+	for _, subject := range [][2]string{{"account", email}, {"ip", ip}} {
+		mock.ExpectQuery(`INSERT INTO login_attempts`).
+			WithArgs(subject[0], subject[1]).
+			WillReturnRows(sqlmock.NewRows(loginAttemptColumns).AddRow(0, 0.0, 0.0))
+		mock.ExpectExec(`UPDATE login_attempts SET failures = \$3`).
+			WithArgs(subject[0], subject[1], 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func expectLoginAllowed(mock sqlmock.Sqlmock) {
+	expectLoginClaimed(mock, "wafiq610@gmail.com", "127.0.0.1")
+}
+
+// expectLoginFailure expects a counted failure to be checked against the
+// lockout threshold.
+func expectLoginFailure(mock sqlmock.Sqlmock, scope, subject string, locked bool) {
+	var rows int64
+	if locked {
+		rows = 1
+	}
+	mock.ExpectExec(`UPDATE login_attempts\s+SET failures = 0, locked_until`).
+		WithArgs(scope, subject, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, rows))
+}
+
+// expectLoginRefunded expects a successful attempt to be given back to the
+// IP, and to the account unless its failures are cleared.
+func expectLoginRefunded(mock sqlmock.Sqlmock, scopes ...string) {
+	subjects := map[string]string{"account": "wafiq610@gmail.com", "ip": "127.0.0.1"}
+	for _, scope := range scopes {
+		mock.ExpectExec(`UPDATE login_attempts SET failures = GREATEST`).
+			WithArgs(scope, subjects[scope]).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+}
+
+func TestLoginUnknownEmailFailsLikeWrongPassword(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	expectLoginClaimed(mock, "nobody@example.com", "127.0.0.1")
+	mock.ExpectQuery(`FROM users`).
+		WithArgs("nobody@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	expectLoginFailure(mock, "account", "nobody@example.com", false)
+	expectLoginFailure(mock, "ip", "127.0.0.1", false)
+
+	_, err := repo.Login(context.Background(), &pb.LoginRequest{
+		Email:     "nobody@example.com",
+		Password:  "password123",
+		IpAddress: "127.0.0.1",
+	})
+
+	assert.ErrorContains(t, err, "invalid credentials")
+	assert.NotContains(t, err.Error(), "not found")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginBacksOffAfterFailures(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	// Five failures, the last one a second ago: attempts must wait 4s after
+	// it (1s doubled for each failure past the three free ones), 3s more.
+	// The refused attempt is not counted.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO login_attempts`).
+		WithArgs("account", "wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows(loginAttemptColumns).AddRow(5, 1.0, 0.0))
+	mock.ExpectRollback()
+
+	_, err := repo.Login(context.Background(), &pb.LoginRequest{
+		Email:     "Wafiq610@gmail.com",
+		Password:  "password123",
+		IpAddress: "127.0.0.1",
+	})
+
+	assert.ErrorContains(t, err, "too many login attempts, retry in 3 seconds")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginRefusedWhileLocked(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO login_attempts`).
+		WithArgs("account", "wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows(loginAttemptColumns).AddRow(0, 30.0, 870.0))
+	mock.ExpectRollback()
+
+	_, err := repo.Login(context.Background(), &pb.LoginRequest{
+		Email:     "wafiq610@gmail.com",
+		Password:  "password123",
+		IpAddress: "127.0.0.1",
+	})
+
+	assert.ErrorContains(t, err, "account temporarily locked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoginLocksAccountAndNotifies(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	repo.Mailer = mailer.New(db, &mailer.MemorySender{})
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	expectLoginAllowed(mock)
+	mock.ExpectQuery(`FROM users`).
+		WithArgs("wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"user_id", "name", "email", "role", "password_hash", "picture", "is_email_verified", "created_at", "updated_at", "last_login_at", "is_active",
+		}).AddRow(
+			"user-123", "Wafi", "wafiq610@gmail.com", "user", string(hashedPassword), "", true, time.Now().Unix(), time.Now().Unix(), time.Now().Unix(), true,
+		))
+	expectLoginFailure(mock, "account", "wafiq610@gmail.com", true)
+	mock.ExpectExec(`INSERT INTO email_outbox`).
+		WithArgs("wafiq610@gmail.com", "Your account was temporarily locked", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectLoginFailure(mock, "ip", "127.0.0.1", false)
+
+	_, err := repo.Login(context.Background(), &pb.LoginRequest{
+		Email:     "wafiq610@gmail.com",
+		Password:  "wrong",
+		IpAddress: "127.0.0.1",
+	})
+
+	assert.ErrorContains(t, err, "invalid credentials")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnlockAccount(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT email FROM users`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("Wafiq610@gmail.com"))
+	mock.ExpectExec(`DELETE FROM login_attempts`).
+		WithArgs("account", "wafiq610@gmail.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := repo.UnlockAccount(context.Background(), &pb.UnlockAccountRequest{UserId: "user-123"})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.True(t, resp.ClearedAttempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultMagicLinkURL is the page login links point to; the signed
+	// token is added as the "token" query parameter.
+	DefaultMagicLinkURL = "http://localhost:3000/magic-login"
+
+	magicLinkTTL = 15 * time.Minute
+	// At most magicLinkLimit links are sent to an email within
+	// magicLinkWindow of the previous request.
+	magicLinkLimit  = 3
+	magicLinkWindow = 15 * time.Minute
+
+	// scopeMagicLink counts link requests in login_attempts, per email.
+	scopeMagicLink = "magic_link"
+)
+
+var (
+	errInvalidMagicLink  = status.Error(codes.Unauthenticated, "invalid or expired login link")
+	errTooManyMagicLinks = status.Error(codes.ResourceExhausted, "too many login links requested, retry later")
+)
+
+// RequestMagicLink emails a single-use login link. Like password resets it
+// answers the same whether or not the email belongs to an account.
+func (r *UserRepository) RequestMagicLink(ctx context.Context, req *pb.RequestMagicLinkRequest) (*pb.RequestMagicLinkResponse, error) {
+	if err := r.allowMagicLink(ctx, req.Email); err != nil {
+		return nil, err
+	}
+	resp := &pb.RequestMagicLinkResponse{
+		Success:   true,
+		ExpiresAt: time.Now().Add(magicLinkTTL).Unix(),
+	}
+
+	userID, name, email, err := r.findActiveUserByEmail(ctx, req.Email)
+	if err == sql.ErrNoRows {
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := r.issueToken(ctx, userID, tokenTypeMagicLink, magicLinkTTL)
+	if err != nil {
+		return nil, err
+	}
+	link, err := withToken(r.MagicLinkURL, r.signMagicLink(token, expiresAt))
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Mailer.Send(ctx, mailer.MagicLink, email, mailer.MagicLinkData{
+		Name:      name,
+		Link:      link,
+		ExpiresIn: "15 minutes",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send email: %w", err)
+	}
+	resp.ExpiresAt = expiresAt.Unix()
+	return resp, nil
+}
+
+// ConsumeMagicLink logs a user in with the token from a login link and
+// creates the session on the device it is opened on.
+func (r *UserRepository) ConsumeMagicLink(ctx context.Context, req *pb.ConsumeMagicLinkRequest) (*pb.LoginResponse, error) {
+	token, ok := r.verifyMagicLink(req.Token)
+	if !ok {
+		return nil, errInvalidMagicLink
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeToken(ctx, tx, token, tokenTypeMagicLink)
+	if err == sql.ErrNoRows {
+		return nil, errInvalidMagicLink
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify login link: %w", err)
+	}
+
+	// Opening the link proves the user reads the email.
+	result, err := tx.ExecContext(ctx, `
+		UPDATE users SET is_email_verified = true, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND is_active = true`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, errAccountDeactivated
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	userInfo, err := r.loadUserInfo(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := r.mfaEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return r.startMFAChallenge(ctx, userID, req.DeviceInfo, req.IpAddress)
+	}
+	return r.completeLogin(ctx, userInfo, req.DeviceInfo, req.IpAddress, false)
+}
+
+// allowMagicLink counts a link request for email, whether or not it has an
+// account, and refuses it over the limit. The window is fixed: it starts at
+// the first request and is kept in last_failure_at, which later requests in
+// the window leave alone so that steady requests cannot keep it open.
+func (r *UserRepository) allowMagicLink(ctx context.Context, email string) error {
+	var requests int
+	err := r.DB.QueryRowContext(ctx, `
+		INSERT INTO login_attempts (scope, subject, failures, last_failure_at)
+		VALUES ($1, $2, 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (scope, subject) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failure_at < CURRENT_TIMESTAMP - $3 * INTERVAL '1 second' THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = CASE
+				WHEN login_attempts.last_failure_at < CURRENT_TIMESTAMP - $3 * INTERVAL '1 second' THEN CURRENT_TIMESTAMP
+				ELSE login_attempts.last_failure_at
+			END
+		RETURNING failures`,
+		scopeMagicLink, normalizeEmail(email), int64(magicLinkWindow.Seconds())).Scan(&requests)
+	if err != nil {
+		return fmt.Errorf("failed to count login link requests: %w", err)
+	}
+	if requests > magicLinkLimit {
+		return errTooManyMagicLinks
+	}
+	return nil
+}
+
+// signMagicLink binds a token to its expiry with an HMAC, so forged and
+// expired links are refused before the database is asked.
+func (r *UserRepository) signMagicLink(token string, expiresAt time.Time) string {
+	payload := token + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + r.magicLinkMAC(payload)
+}
+
+// verifyMagicLink returns the token of a signed link that has not expired.
+func (r *UserRepository) verifyMagicLink(signed string) (string, bool) {
+	i := strings.LastIndexByte(signed, '.')
+	if i < 0 {
+		return "", false
+	}
+	payload, mac := signed[:i], signed[i+1:]
+	if !hmac.Equal([]byte(mac), []byte(r.magicLinkMAC(payload))) {
+		return "", false
+	}
+
+	i = strings.LastIndexByte(payload, '.')
+	if i < 0 {
+		return "", false
+	}
+	expiresAt, err := strconv.ParseInt(payload[i+1:], 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return "", false
+	}
+	return payload[:i], true
+}
+
+// newMagicLinkKey returns a random signing key. Links signed with it stop
+// working when the service restarts, so deployments set a shared one.
+func newMagicLinkKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("failed to generate magic link key: %v", err))
+	}
+	return key
+}
+
+func (r *UserRepository) magicLinkMAC(payload string) string {
+	mac := hmac.New(sha256.New, r.MagicLinkKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
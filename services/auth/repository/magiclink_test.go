@@ -0,0 +1,150 @@
+package repository_test
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+	"github.com/wafi04/golang-backend/services/auth/repository"
+)
+
+// expectMagicLinkAllowed expects a request to be counted in a window that
+// later requests do not move.
+func expectMagicLinkAllowed(mock sqlmock.Sqlmock, requests int) {
+	mock.ExpectQuery(`(?s)INSERT INTO login_attempts.*ELSE login_attempts\.last_failure_at`).
+		WithArgs("magic_link", "wafiq610@gmail.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"failures"}).AddRow(requests))
+}
+
+// requestMagicLink runs RequestMagicLink for a known user and returns the
+// token from the emailed link.
+func requestMagicLink(t *testing.T, repo *repository.UserRepository, mock sqlmock.Sqlmock) string {
+	body := &capture{}
+	expectMagicLinkAllowed(mock, 1)
+	mock.ExpectQuery(`FROM users WHERE email = \$1`).
+		WithArgs("wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email"}).
+			AddRow("user-123", "Wafi", "wafiq610@gmail.com"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE verification_tokens SET is_used = true`).
+		WithArgs("user-123", "MAGIC_LINK").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO verification_tokens`).
+		WithArgs(sqlmock.AnyArg(), "user-123", "MAGIC_LINK", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`INSERT INTO email_outbox`).
+		WithArgs("wafiq610@gmail.com", sqlmock.AnyArg(), body, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp, err := repo.RequestMagicLink(context.Background(), &pb.RequestMagicLinkRequest{
+		Email: "wafiq610@gmail.com",
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+
+	link, err := url.Parse(regexp.MustCompile(`http\S+`).FindString(body.value))
+	require.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestMagicLinkLogsIn(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	repo.Mailer = mailer.New(db, &mailer.MemorySender{})
+
+	token := requestMagicLink(t, repo, mock)
+	require.NotEmpty(t, token)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE verification_tokens SET is_used = true`).
+		WithArgs(sqlmock.AnyArg(), "MAGIC_LINK").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-123"))
+	mock.ExpectExec(`UPDATE users SET is_email_verified = true`).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`FROM users`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "role", "is_email_verified", "created_at", "updated_at", "last_login_at"}).
+			AddRow("user-123", "Wafi", "wafiq610@gmail.com", "user", true, time.Now().Unix(), time.Now().Unix(), time.Now().Unix()))
+	mock.ExpectQuery(`FROM user_mfa`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`FROM user_roles`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permission", "requires_mfa"}))
+	mock.ExpectQuery(`FROM sessions`).
+		WithArgs("user-123", "Safari").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "ip_address", "device_info", "created_at", "last_activity_at"}).
+			AddRow("session-1", "127.0.0.1", "Safari", time.Now().Unix(), time.Now().Unix()))
+	mock.ExpectExec(`INSERT INTO sessions`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectNewRefreshFamily(mock, "session-1", "user-123")
+	mock.ExpectExec(`UPDATE users`).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp, err := repo.ConsumeMagicLink(context.Background(), &pb.ConsumeMagicLinkRequest{
+		Token:      token,
+		DeviceInfo: "Safari",
+		IpAddress:  "10.0.0.1",
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.AccessToken)
+	assert.Equal(t, "Safari", resp.SessionInfo.DeviceInfo)
+	assert.Equal(t, "10.0.0.1", resp.SessionInfo.IpAddress)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMagicLinkRejectsTamperedToken(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	repo.Mailer = mailer.New(db, &mailer.MemorySender{})
+
+	token := requestMagicLink(t, repo, mock)
+
+	// Pushing the expiry out breaks the signature; no query is made.
+	forged := regexp.MustCompile(`\.\d+\.`).ReplaceAllString(token, ".9999999999.")
+	_, err := repo.ConsumeMagicLink(context.Background(), &pb.ConsumeMagicLinkRequest{Token: forged})
+	assert.ErrorContains(t, err, "invalid or expired login link")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMagicLinkIsSingleUse(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	repo.Mailer = mailer.New(db, &mailer.MemorySender{})
+
+	token := requestMagicLink(t, repo, mock)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE verification_tokens SET is_used = true`).
+		WithArgs(sqlmock.AnyArg(), "MAGIC_LINK").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectRollback()
+
+	_, err := repo.ConsumeMagicLink(context.Background(), &pb.ConsumeMagicLinkRequest{Token: token})
+	assert.ErrorContains(t, err, "invalid or expired login link")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequestMagicLinkIsRateLimited(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	expectMagicLinkAllowed(mock, 4)
+
+	_, err := repo.RequestMagicLink(context.Background(), &pb.RequestMagicLinkRequest{
+		Email: "Wafiq610@gmail.com ",
+	})
+	assert.ErrorContains(t, err, "too many login links")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,372 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/totp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultMFAIssuer names the service in authenticator apps.
+	DefaultMFAIssuer = "golang-backend"
+
+	mfaChallengeTTL   = 5 * time.Minute
+	maxMFAAttempts    = 5
+	recoveryCodeCount = 10
+)
+
+var (
+	errInvalidMFAChallenge = status.Error(codes.Unauthenticated, "invalid or expired mfa challenge")
+	errInvalidMFACode      = status.Error(codes.InvalidArgument, "invalid mfa code")
+	errMFANotEnabled       = status.Error(codes.FailedPrecondition, "2FA is not enabled")
+	errMFAAlreadyEnabled   = status.Error(codes.FailedPrecondition, "2FA is already enabled")
+)
+
+func (r *UserRepository) mfaEnabled(ctx context.Context, userID string) (bool, error) {
+	var enabled bool
+	err := r.DB.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM user_mfa WHERE user_id = $1 AND confirmed_at IS NOT NULL)", userID).Scan(&enabled)
+	if err != nil {
+		return false, fmt.Errorf("failed to check 2FA: %w", err)
+	}
+	return enabled, nil
+}
+
+// startMFAChallenge answers a login whose password was right but that
+// still needs the second factor. The challenge is finished with
+// VerifyLoginMFA.
+func (r *UserRepository) startMFAChallenge(ctx context.Context, userID, deviceInfo, ipAddress string) (*pb.LoginResponse, error) {
+	challenge, challengeHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.DB.ExecContext(ctx, `
+		INSERT INTO mfa_challenges (challenge_hash, user_id, device_info, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		challengeHash, userID, deviceInfo, ipAddress, time.Now().Add(mfaChallengeTTL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mfa challenge: %w", err)
+	}
+
+	return &pb.LoginResponse{
+		UserId:       userID,
+		MfaRequired:  true,
+		MfaChallenge: challenge,
+	}, nil
+}
+
+// VerifyLoginMFA finishes a login started with Login by checking a TOTP
+// code or a recovery code, and creates the session.
+func (r *UserRepository) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginResponse, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		userID, email, name, deviceInfo, ipAddress string
+		attempts                                   int
+		expired, used                              bool
+	)
+	challengeHash := hashToken(req.MfaChallenge)
+	err = tx.QueryRowContext(ctx, `
+		SELECT c.user_id, u.email, u.name, c.device_info, c.ip_address, c.attempts,
+			c.expires_at < CURRENT_TIMESTAMP, c.used_at IS NOT NULL
+		FROM mfa_challenges c
+		JOIN users u ON u.user_id = c.user_id
+		WHERE c.challenge_hash = $1
+		FOR UPDATE OF c`, challengeHash).Scan(
+		&userID, &email, &name, &deviceInfo, &ipAddress, &attempts, &expired, &used,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errInvalidMFAChallenge
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mfa challenge: %w", err)
+	}
+	if expired || used || attempts >= maxMFAAttempts {
+		return nil, errInvalidMFAChallenge
+	}
+	// Wrong codes count against the account like wrong passwords, so
+	// opening new challenges does not give a guesser more attempts.
+	if err := r.claimLoginAttempt(ctx, email, ipAddress); err != nil {
+		return nil, err
+	}
+
+	ok, err := r.verifySecondFactor(ctx, tx, userID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		_, err := tx.ExecContext(ctx, "UPDATE mfa_challenges SET attempts = attempts + 1 WHERE challenge_hash = $1", challengeHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update mfa challenge: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		r.recordLoginFailure(ctx, email, ipAddress, &pb.UserInfo{UserId: userID, Email: email, Name: name})
+		return nil, errInvalidMFACode
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE mfa_challenges SET used_at = CURRENT_TIMESTAMP WHERE challenge_hash = $1", challengeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update mfa challenge: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.clearLoginFailures(ctx, email, ipAddress)
+
+	userInfo, err := r.loadUserInfo(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.completeLogin(ctx, userInfo, deviceInfo, ipAddress, true)
+}
+
+// verifySecondFactor checks code as a TOTP code, then as an unused
+// recovery code. A matching recovery code is used up.
+func (r *UserRepository) verifySecondFactor(ctx context.Context, tx *sql.Tx, userID, code string) (bool, error) {
+	var (
+		secret   string
+		lastStep int64
+	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT secret, last_used_step FROM user_mfa
+		WHERE user_id = $1 AND confirmed_at IS NOT NULL
+		FOR UPDATE`, userID).Scan(&secret, &lastStep)
+	if err == sql.ErrNoRows {
+		return false, errMFANotEnabled
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get 2FA secret: %w", err)
+	}
+
+	if step, ok := totp.Verify(secret, code, time.Now(), lastStep); ok {
+		_, err := tx.ExecContext(ctx, "UPDATE user_mfa SET last_used_step = $1 WHERE user_id = $2", step, userID)
+		if err != nil {
+			return false, fmt.Errorf("failed to update 2FA: %w", err)
+		}
+		return true, nil
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE mfa_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check recovery code: %w", err)
+	}
+	return n == 1, nil
+}
+
+// EnrollMFA starts enrollment with a new secret. 2FA is enabled once a code
+// from it is confirmed with ConfirmMFA.
+func (r *UserRepository) EnrollMFA(ctx context.Context, req *pb.EnrollMFARequest) (*pb.EnrollMFAResponse, error) {
+	var email string
+	err := r.DB.QueryRowContext(ctx, "SELECT email FROM users WHERE user_id = $1", req.UserId).Scan(&email)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	enabled, err := r.mfaEnabled(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, errMFAAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	_, err = r.DB.ExecContext(ctx, `
+		INSERT INTO user_mfa (user_id, secret, created_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, created_at = EXCLUDED.created_at
+		WHERE user_mfa.confirmed_at IS NULL`,
+		req.UserId, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save 2FA secret: %w", err)
+	}
+
+	uri := totp.URI(r.MFAIssuer, email, secret)
+	return &pb.EnrollMFAResponse{
+		Secret:     secret,
+		OtpauthUri: uri,
+		QrPayload:  uri,
+	}, nil
+}
+
+// ConfirmMFA enables 2FA once the user proved their app produces codes,
+// and returns fresh recovery codes.
+func (r *UserRepository) ConfirmMFA(ctx context.Context, req *pb.ConfirmMFARequest) (*pb.ConfirmMFAResponse, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		secret    string
+		confirmed bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT secret, confirmed_at IS NOT NULL FROM user_mfa
+		WHERE user_id = $1
+		FOR UPDATE`, req.UserId).Scan(&secret, &confirmed)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.FailedPrecondition, "2FA enrollment not started")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get 2FA secret: %w", err)
+	}
+	if confirmed {
+		return nil, errMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Verify(secret, req.Code, time.Now(), 0)
+	if !ok {
+		return nil, errInvalidMFACode
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_mfa SET confirmed_at = CURRENT_TIMESTAMP, last_used_step = $1
+		WHERE user_id = $2`, step, req.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable 2FA: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM mfa_recovery_codes WHERE user_id = $1", req.UserId); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	recoveryCodes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, "INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)",
+			req.UserId, hashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+		}
+		recoveryCodes = append(recoveryCodes, code)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &pb.ConfirmMFAResponse{
+		Success:       true,
+		RecoveryCodes: recoveryCodes,
+	}, nil
+}
+
+// DisableMFA turns 2FA off after checking a current code. Sessions that
+// passed 2FA lose the roles requiring it on their next refresh.
+func (r *UserRepository) DisableMFA(ctx context.Context, req *pb.DisableMFARequest) (*pb.DisableMFAResponse, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	ok, err := r.verifySecondFactor(ctx, tx, req.UserId, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errInvalidMFACode
+	}
+
+	for _, query := range []string{
+		"DELETE FROM user_mfa WHERE user_id = $1",
+		"DELETE FROM mfa_recovery_codes WHERE user_id = $1",
+		"UPDATE sessions SET mfa_verified = false WHERE user_id = $1",
+	} {
+		if _, err := tx.ExecContext(ctx, query, req.UserId); err != nil {
+			return nil, fmt.Errorf("failed to disable 2FA: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &pb.DisableMFAResponse{
+		Success: true,
+	}, nil
+}
+
+// loadUserInfo loads the user a token is issued for.
+func (r *UserRepository) loadUserInfo(ctx context.Context, userID string) (*pb.UserInfo, error) {
+	user := &pb.UserInfo{}
+	err := r.DB.QueryRowContext(ctx, `
+		SELECT
+			user_id,
+			name,
+			email,
+			role,
+			COALESCE(is_email_verified, false)::boolean,
+			EXTRACT(EPOCH FROM created_at)::bigint,
+			EXTRACT(EPOCH FROM updated_at)::bigint,
+			EXTRACT(EPOCH FROM COALESCE(last_login_at, created_at))::bigint
+		FROM users
+		WHERE user_id = $1`, userID).Scan(
+		&user.UserId,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.IsEmailVerified,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.LastLoginAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCode returns a code like "k4xm2-p7qra".
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate recovery code: %w", err)
+	}
+	code := strings.ToLower(recoveryEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
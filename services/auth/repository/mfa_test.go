@@ -0,0 +1,241 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/totp"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func mfaChallengeRow(attempts int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"user_id", "email", "name", "device_info", "ip_address", "attempts", "expired", "used"}).
+		AddRow("user-123", "wafiq610@gmail.com", "Wafi", "Android", "127.0.0.1", attempts, false, false)
+}
+
+func TestLoginWithMFAReturnsChallenge(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	expectLoginAllowed(mock)
+	mock.ExpectQuery(`SELECT`).
+		WithArgs("wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{
+			"user_id", "name", "email", "role", "password_hash", "picture", "is_email_verified", "created_at", "updated_at", "last_login_at", "is_active",
+		}).AddRow(
+			"user-123", "Wafi", "wafiq610@gmail.com", "admin", string(hashedPassword), "", true, time.Now().Unix(), time.Now().Unix(), time.Now().Unix(), true,
+		))
+	// Failures are kept until the second factor is right too.
+	mock.ExpectQuery(`FROM user_mfa`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	expectLoginRefunded(mock, "account", "ip")
+	mock.ExpectExec(`INSERT INTO mfa_challenges`).
+		WithArgs(sqlmock.AnyArg(), "user-123", "Android", "127.0.0.1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp, err := repo.Login(context.Background(), &pb.LoginRequest{
+		Email:      "wafiq610@gmail.com",
+		Password:   "password123",
+		IpAddress:  "127.0.0.1",
+		DeviceInfo: "Android",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.MfaRequired)
+	assert.NotEmpty(t, resp.MfaChallenge)
+	assert.Empty(t, resp.AccessToken)
+	assert.Empty(t, resp.RefreshToken)
+	assert.Nil(t, resp.SessionInfo)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyLoginMFACreatesSession(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM mfa_challenges`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(mfaChallengeRow(0))
+	expectLoginAllowed(mock)
+	mock.ExpectQuery(`FROM user_mfa`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"secret", "last_used_step"}).AddRow(secret, 0))
+	mock.ExpectExec(`UPDATE user_mfa SET last_used_step`).
+		WithArgs(sqlmock.AnyArg(), "user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE mfa_challenges SET used_at`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`DELETE FROM login_attempts`).
+		WithArgs("account", "wafiq610@gmail.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectLoginRefunded(mock, "ip")
+
+	mock.ExpectQuery(`FROM users`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "role", "is_email_verified", "created_at", "updated_at", "last_login_at"}).
+			AddRow("user-123", "Wafi", "wafiq610@gmail.com", "admin", true, time.Now().Unix(), time.Now().Unix(), time.Now().Unix()))
+	// The admin role requires 2FA; after the challenge it is granted.
+	mock.ExpectQuery(`FROM user_roles`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permission", "requires_mfa"}).
+			AddRow("admin", "roles:manage", true))
+	mock.ExpectQuery(`FROM sessions`).
+		WithArgs("user-123", "Android").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "ip_address", "device_info", "created_at", "last_activity_at"}).
+			AddRow("session-1", "127.0.0.1", "Android", time.Now().Unix(), time.Now().Unix()))
+	mock.ExpectExec(`INSERT INTO sessions`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectNewRefreshFamily(mock, "session-1", "user-123")
+	mock.ExpectExec(`UPDATE users`).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp, err := repo.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallenge: "challenge",
+		Code:         code,
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.False(t, resp.MfaEnrollmentRequired)
+	assert.Equal(t, "session-1", resp.SessionInfo.SessionId)
+
+	claims, err := middleware.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"roles:manage"}, claims.Permissions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyLoginMFACountsFailedAttempts(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM mfa_challenges`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(mfaChallengeRow(1))
+	expectLoginAllowed(mock)
+	mock.ExpectQuery(`FROM user_mfa`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"secret", "last_used_step"}).AddRow(secret, 0))
+	// Not a TOTP code, so it is tried as a recovery code.
+	mock.ExpectExec(`UPDATE mfa_recovery_codes SET used_at`).
+		WithArgs("user-123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE mfa_challenges SET attempts = attempts \+ 1`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// The wrong code also counts against the account and the IP.
+	expectLoginFailure(mock, "account", "wafiq610@gmail.com", false)
+	expectLoginFailure(mock, "ip", "127.0.0.1", false)
+
+	_, err = repo.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallenge: "challenge",
+		Code:         "wrong",
+	})
+
+	assert.ErrorContains(t, err, "invalid mfa code")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyLoginMFARefusedWhileLocked(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	// A fresh challenge does not get around a lock from earlier failures.
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM mfa_challenges`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(mfaChallengeRow(0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO login_attempts`).
+		WithArgs("account", "wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows(loginAttemptColumns).AddRow(0, 30.0, 870.0))
+	mock.ExpectRollback()
+	mock.ExpectRollback()
+
+	_, err := repo.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallenge: "challenge",
+		Code:         "123456",
+	})
+
+	assert.ErrorContains(t, err, "account temporarily locked")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyLoginMFARejectsExhaustedChallenge(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM mfa_challenges`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(mfaChallengeRow(5))
+	mock.ExpectRollback()
+
+	_, err := repo.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{
+		MfaChallenge: "challenge",
+		Code:         "123456",
+	})
+
+	assert.ErrorContains(t, err, "mfa challenge")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestConfirmMFAReturnsRecoveryCodes(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_mfa`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"secret", "confirmed"}).AddRow(secret, false))
+	mock.ExpectExec(`UPDATE user_mfa SET confirmed_at`).
+		WithArgs(sqlmock.AnyArg(), "user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM mfa_recovery_codes`).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	for i := 0; i < 10; i++ {
+		mock.ExpectExec(`INSERT INTO mfa_recovery_codes`).
+			WithArgs("user-123", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectCommit()
+
+	resp, err := repo.ConfirmMFA(context.Background(), &pb.ConfirmMFARequest{
+		UserId: "user-123",
+		Code:   code,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Len(t, resp.RecoveryCodes, 10)
+	assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, resp.RecoveryCodes[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
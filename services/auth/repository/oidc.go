@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/oidc"
+	"github.com/wafi04/golang-backend/services/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// oidcLoginTTL is how long a user has to come back from the provider.
+const oidcLoginTTL = 10 * time.Minute
+
+var (
+	errInvalidOIDCState        = status.Error(codes.Unauthenticated, "invalid or expired login state")
+	errOIDCEmailUnverified     = status.Error(codes.PermissionDenied, "email is not verified by the provider")
+	errUnknownOIDCProvider     = status.Error(codes.NotFound, "unknown login provider")
+	errOIDCProviderUnavailable = status.Error(codes.Unavailable, "login provider unavailable")
+)
+
+// ListOIDCProviders lists the providers users can sign in with.
+func (r *UserRepository) ListOIDCProviders(ctx context.Context, req *pb.ListOIDCProvidersRequest) (*pb.ListOIDCProvidersResponse, error) {
+	return &pb.ListOIDCProvidersResponse{Providers: r.OIDC.Names()}, nil
+}
+
+// StartOIDCLogin begins a login with a provider and returns the page to
+// send the user to. The login is finished with CompleteOIDCLogin.
+func (r *UserRepository) StartOIDCLogin(ctx context.Context, req *pb.StartOIDCLoginRequest) (*pb.StartOIDCLoginResponse, error) {
+	provider, ok := r.OIDC.Get(req.Provider)
+	if !ok {
+		return nil, errUnknownOIDCProvider
+	}
+
+	state, stateHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	nonce, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		r.logger.Log(common.ErrorLevel, "Failed to start %s login: %v", provider.Name, err)
+		return nil, errOIDCProviderUnavailable
+	}
+
+	expiresAt := time.Now().Add(oidcLoginTTL)
+	_, err = r.DB.ExecContext(ctx, `
+		INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		stateHash, provider.Name, nonce, verifier, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save login state: %w", err)
+	}
+
+	return &pb.StartOIDCLoginResponse{
+		AuthorizationUrl: authURL,
+		State:            state,
+		ExpiresAt:        expiresAt.Unix(),
+	}, nil
+}
+
+// CompleteOIDCLogin finishes a login when the provider sends the user
+// back. The provider account is linked to the user with its verified
+// email, or to a new user, on first sign-in.
+func (r *UserRepository) CompleteOIDCLogin(ctx context.Context, req *pb.CompleteOIDCLoginRequest) (*pb.LoginResponse, error) {
+	provider, ok := r.OIDC.Get(req.Provider)
+	if !ok {
+		return nil, errUnknownOIDCProvider
+	}
+
+	// The state is used up whether or not the login succeeds.
+	var nonce, verifier string
+	var valid bool
+	err := r.DB.QueryRowContext(ctx, `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1 AND provider = $2
+		RETURNING nonce, code_verifier, expires_at > CURRENT_TIMESTAMP`,
+		hashToken(req.State), provider.Name).Scan(&nonce, &verifier, &valid)
+	if err == sql.ErrNoRows || (err == nil && !valid) {
+		return nil, errInvalidOIDCState
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load login state: %w", err)
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, verifier, nonce)
+	if err != nil {
+		r.logger.Log(common.WarnLevel, "%s login failed: %v", provider.Name, err)
+		return nil, status.Errorf(codes.Unauthenticated, "%s login failed", provider.Name)
+	}
+
+	userID, err := r.linkOIDCIdentity(ctx, provider.Name, claims)
+	if err != nil {
+		return nil, err
+	}
+	userInfo, err := r.loadUserInfo(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enabled, err := r.mfaEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return r.startMFAChallenge(ctx, userID, req.DeviceInfo, req.IpAddress)
+	}
+	return r.completeLogin(ctx, userInfo, req.DeviceInfo, req.IpAddress, false)
+}
+
+// linkOIDCIdentity returns the user a provider account signs in as,
+// linking it on first sign-in, and copies the picture from the ID token.
+func (r *UserRepository) linkOIDCIdentity(ctx context.Context, provider string, claims *oidc.Claims) (string, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var userID string
+	var active, linkedByEmail bool
+	var endedSessions []string
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.user_id, u.is_active
+		FROM user_identities i
+		JOIN users u ON u.user_id = i.user_id
+		WHERE i.provider = $1 AND i.subject = $2`,
+		provider, claims.Subject).Scan(&userID, &active)
+	if err == sql.ErrNoRows {
+		// An unverified email could be anyone's, so it never links.
+		if claims.Email == "" || !claims.EmailVerified {
+			return "", errOIDCEmailUnverified
+		}
+		userID, active, endedSessions, err = r.findOrCreateOIDCUser(ctx, tx, claims)
+		linkedByEmail = true
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find user: %w", err)
+	}
+	if !active {
+		return "", errAccountDeactivated
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO user_identities (provider, subject, user_id, email, last_login_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (provider, subject)
+		DO UPDATE SET email = EXCLUDED.email, last_login_at = EXCLUDED.last_login_at`,
+		provider, claims.Subject, userID, claims.Email)
+	if err != nil {
+		return "", fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	// The provider vouched for the email it was linked by.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users
+		SET picture = COALESCE(NULLIF($2, ''), picture),
+			is_email_verified = is_email_verified OR $3,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1`,
+		userID, claims.Picture, linkedByEmail)
+	if err != nil {
+		return "", fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.revokeSessions(ctx, endedSessions...)
+	if linkedByEmail {
+		r.logger.Log(common.InfoLevel, "Linked %s account %s to user %s", provider, claims.Subject, userID)
+	}
+	return userID, nil
+}
+
+// findOrCreateOIDCUser returns the user with the claims' email, creating
+// one if there is none. An existing account no provider account is linked
+// to yet is taken over: it was created with a password, and whoever set
+// that password may not own the email even if it shows as verified. The
+// password, 2FA and sessions are removed, and the sessions returned to be
+// revoked once tx commits; the owner can set a new password with a reset
+// link.
+func (r *UserRepository) findOrCreateOIDCUser(ctx context.Context, tx *sql.Tx, claims *oidc.Claims) (string, bool, []string, error) {
+	var userID string
+	var active, linked bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT u.user_id, u.is_active, EXISTS(SELECT 1 FROM user_identities i WHERE i.user_id = u.user_id)
+		FROM users u WHERE LOWER(u.email) = LOWER($1)`,
+		claims.Email).Scan(&userID, &active, &linked)
+	if err == nil && active && !linked {
+		sessionIDs, err := r.claimPasswordUser(ctx, tx, userID)
+		return userID, active, sessionIDs, err
+	}
+	if err != sql.ErrNoRows {
+		return userID, active, nil, err
+	}
+
+	passwordHash, err := r.unusablePassword()
+	if err != nil {
+		return "", false, nil, err
+	}
+	name := claims.Name
+	if name == "" {
+		name = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+
+	userID = uuid.New().String()
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO users (
+			user_id, name, email, password_hash, role, picture,
+			is_active, is_email_verified, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		userID, name, claims.Email, passwordHash, "user", claims.Picture,
+		true, true, now, now)
+	if err != nil {
+		return "", false, nil, err
+	}
+	return userID, true, nil, nil
+}
+
+// claimPasswordUser shuts whoever created a password account out of it
+// before it is linked to the provider account that owns its email.
+func (r *UserRepository) claimPasswordUser(ctx context.Context, tx *sql.Tx, userID string) ([]string, error) {
+	passwordHash, err := r.unusablePassword()
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx,
+		"UPDATE users SET password_hash = $2, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1",
+		userID, passwordHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reset password: %w", err)
+	}
+	for _, query := range []string{
+		"DELETE FROM mfa_recovery_codes WHERE user_id = $1",
+		"DELETE FROM user_mfa WHERE user_id = $1",
+	} {
+		if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+			return nil, fmt.Errorf("failed to remove 2FA: %w", err)
+		}
+	}
+	sessionIDs, err := endSessions(ctx, tx, "DELETE FROM sessions WHERE user_id = $1 RETURNING session_id", userID)
+	if err != nil {
+		return nil, err
+	}
+	r.logger.Log(common.WarnLevel, "User %s claimed by its email's provider account: password and 2FA removed, ended %d sessions", userID, len(sessionIDs))
+	return sessionIDs, nil
+}
+
+// unusablePassword hashes a random secret. The account has no usable
+// password until the user sets one with a reset link.
+func (r *UserRepository) unusablePassword() (string, error) {
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	return r.Passwords.Hash(secret)
+}
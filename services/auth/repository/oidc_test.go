@@ -0,0 +1,226 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/oidc"
+	"github.com/wafi04/golang-backend/services/auth/oidc/oidctest"
+	"github.com/wafi04/golang-backend/services/auth/repository"
+)
+
+// capture matches any argument and keeps it, for values generated inside
+// the repository that a later query has to return.
+type capture struct{ value string }
+
+func (c *capture) Match(v driver.Value) bool {
+	c.value, _ = v.(string)
+	return true
+}
+
+func newOIDCProvider(t *testing.T, repo *repository.UserRepository, user oidctest.User) *oidctest.Server {
+	server, err := oidctest.NewServer("client-1", "secret", user)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	repo.OIDC = oidc.NewRegistry(server.Provider("mock", "http://localhost:8080/api/v1/auth/oidc/mock/callback"))
+	return server
+}
+
+// startOIDCLogin runs StartOIDCLogin and the user's trip to the provider,
+// and expects CompleteOIDCLogin to load the saved state.
+func startOIDCLogin(t *testing.T, repo *repository.UserRepository, mock sqlmock.Sqlmock, server *oidctest.Server) (code, state string) {
+	nonce, verifier := &capture{}, &capture{}
+	mock.ExpectExec(`INSERT INTO oidc_login_states`).
+		WithArgs(sqlmock.AnyArg(), "mock", nonce, verifier, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp, err := repo.StartOIDCLogin(context.Background(), &pb.StartOIDCLoginRequest{Provider: "mock"})
+	require.NoError(t, err)
+	code, state, err = server.Authorize(resp.AuthorizationUrl)
+	require.NoError(t, err)
+	assert.Equal(t, resp.State, state)
+
+	mock.ExpectQuery(`DELETE FROM oidc_login_states`).
+		WithArgs(sqlmock.AnyArg(), "mock").
+		WillReturnRows(sqlmock.NewRows([]string{"nonce", "code_verifier", "valid"}).
+			AddRow(nonce.value, verifier.value, true))
+	return code, state
+}
+
+func TestOIDCLoginLinksAccountByVerifiedEmail(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	server := newOIDCProvider(t, repo, oidctest.User{
+		Subject:       "google-123",
+		Email:         "wafiq610@gmail.com",
+		EmailVerified: true,
+		Name:          "Wafi",
+		Picture:       "https://example.com/wafi.png",
+	})
+
+	code, state := startOIDCLogin(t, repo, mock, server)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_identities`).
+		WithArgs("mock", "google-123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "is_active"}))
+	// The account is already linked to another provider account, so it
+	// belongs to the email's owner.
+	mock.ExpectQuery(`FROM users u WHERE LOWER\(u.email\) = LOWER\(\$1\)`).
+		WithArgs("wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "is_active", "linked"}).AddRow("user-123", true, true))
+	mock.ExpectExec(`INSERT INTO user_identities`).
+		WithArgs("mock", "google-123", "user-123", "wafiq610@gmail.com").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE users`).
+		WithArgs("user-123", "https://example.com/wafi.png", true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectQuery(`FROM users`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "role", "is_email_verified", "created_at", "updated_at", "last_login_at"}).
+			AddRow("user-123", "Wafi", "wafiq610@gmail.com", "user", true, time.Now().Unix(), time.Now().Unix(), time.Now().Unix()))
+	mock.ExpectQuery(`FROM user_mfa`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery(`FROM user_roles`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permission", "requires_mfa"}))
+	mock.ExpectQuery(`FROM sessions`).
+		WithArgs("user-123", "Firefox").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "ip_address", "device_info", "created_at", "last_activity_at"}).
+			AddRow("session-1", "127.0.0.1", "Firefox", time.Now().Unix(), time.Now().Unix()))
+	mock.ExpectExec(`INSERT INTO sessions`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectNewRefreshFamily(mock, "session-1", "user-123")
+	mock.ExpectExec(`UPDATE users`).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp, err := repo.CompleteOIDCLogin(context.Background(), &pb.CompleteOIDCLoginRequest{
+		Provider:   "mock",
+		State:      state,
+		Code:       code,
+		DeviceInfo: "Firefox",
+		IpAddress:  "127.0.0.1",
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.AccessToken)
+	assert.Equal(t, "user-123", resp.UserId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDCLoginClaimsAccountWithoutLinkedIdentity(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	server := newOIDCProvider(t, repo, oidctest.User{
+		Subject:       "google-123",
+		Email:         "wafiq610@gmail.com",
+		EmailVerified: true,
+	})
+
+	// Someone signed up with the email before its owner; whether or not
+	// the email shows as verified, their password, 2FA and sessions must
+	// not survive the owner's first sign-in.
+	code, state := startOIDCLogin(t, repo, mock, server)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_identities`).
+		WithArgs("mock", "google-123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "is_active"}))
+	mock.ExpectQuery(`FROM users u WHERE LOWER\(u.email\) = LOWER\(\$1\)`).
+		WithArgs("wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "is_active", "linked"}).AddRow("user-123", true, false))
+	mock.ExpectExec(`UPDATE users SET password_hash`).
+		WithArgs("user-123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM mfa_recovery_codes`).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM user_mfa`).
+		WithArgs("user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`DELETE FROM sessions WHERE user_id = \$1 RETURNING session_id`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id"}).AddRow("attacker-session"))
+	mock.ExpectExec(`INSERT INTO user_identities`).
+		WithArgs("mock", "google-123", "user-123", "wafiq610@gmail.com").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE users`).
+		WithArgs("user-123", "", true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	// Stop after the link; the rest of the login is covered above.
+	mock.ExpectQuery(`FROM users`).
+		WithArgs("user-123").
+		WillReturnError(fmt.Errorf("stop"))
+
+	_, err := repo.CompleteOIDCLogin(context.Background(), &pb.CompleteOIDCLoginRequest{
+		Provider: "mock",
+		State:    state,
+		Code:     code,
+	})
+
+	assert.ErrorContains(t, err, "stop")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOIDCLoginRefusesUnverifiedEmail(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	server := newOIDCProvider(t, repo, oidctest.User{
+		Subject: "google-123",
+		Email:   "wafiq610@gmail.com",
+	})
+
+	code, state := startOIDCLogin(t, repo, mock, server)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM user_identities`).
+		WithArgs("mock", "google-123").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "is_active"}))
+	mock.ExpectRollback()
+
+	_, err := repo.CompleteOIDCLogin(context.Background(), &pb.CompleteOIDCLoginRequest{
+		Provider: "mock",
+		State:    state,
+		Code:     code,
+	})
+
+	assert.ErrorContains(t, err, "email is not verified")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompleteOIDCLoginRejectsUnknownState(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	newOIDCProvider(t, repo, oidctest.User{Subject: "google-123"})
+
+	mock.ExpectQuery(`DELETE FROM oidc_login_states`).
+		WillReturnRows(sqlmock.NewRows([]string{"nonce", "code_verifier", "valid"}))
+
+	_, err := repo.CompleteOIDCLogin(context.Background(), &pb.CompleteOIDCLoginRequest{
+		Provider: "mock",
+		State:    "forged",
+		Code:     "code",
+	})
+
+	assert.ErrorContains(t, err, "invalid or expired login state")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStartOIDCLoginUnknownProvider(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	_, err := repo.StartOIDCLogin(context.Background(), &pb.StartOIDCLoginRequest{Provider: "github"})
+
+	assert.ErrorContains(t, err, "unknown login provider")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
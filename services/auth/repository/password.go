@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rehashPassword replaces a stored hash after a successful login, when it was
+// created with another algorithm or cost. A failure only means the upgrade
+// is retried on the next login.
+func (r *UserRepository) rehashPassword(ctx context.Context, userID, plain string) {
+	hash, err := r.Passwords.Hash(plain)
+	if err != nil {
+		r.logger.Log(common.ErrorLevel, "Failed to rehash password: %v", err)
+		return
+	}
+
+	_, err = r.DB.ExecContext(ctx, "UPDATE users SET password_hash = $1 WHERE user_id = $2", hash, userID)
+	if err != nil {
+		r.logger.Log(common.ErrorLevel, "Failed to store rehashed password: %v", err)
+	}
+}
+
+// UpdateUser updates the given profile fields. A new password is hashed like
+// on sign-up; roles are changed with AssignRole and RevokeRole instead.
+func (r *UserRepository) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	if req.Role != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: roles are assigned with AssignRole")
+	}
+
+	var sets []string
+	var args []interface{}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if req.Name != nil {
+		set("name", *req.Name)
+	}
+	if req.Email != nil {
+		set("email", *req.Email)
+	}
+	if req.Picture != nil {
+		set("picture", *req.Picture)
+	}
+	if req.Password != nil {
+		if *req.Password == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid request: password must not be empty")
+		}
+		hash, err := r.Passwords.Hash(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		set("password_hash", hash)
+	}
+
+	args = append(args, req.UserId)
+	query := fmt.Sprintf(`
+		UPDATE users
+		SET %s
+		WHERE user_id = $%d
+		RETURNING EXTRACT(EPOCH FROM updated_at)::bigint`,
+		strings.Join(append(sets, "updated_at = CURRENT_TIMESTAMP"), ", "), len(args))
+
+	var updatedAt int64
+	err := r.DB.QueryRowContext(ctx, query, args...).Scan(&updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "user not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return &pb.UpdateUserResponse{
+		UserId:    req.UserId,
+		UpdatedAt: updatedAt,
+	}, nil
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRefreshTokenTTL is how long a refresh token, and with it the
+// session, stays valid when it is not used.
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	errInvalidRefreshToken = status.Error(codes.Unauthenticated, "invalid refresh token")
+	errRefreshTokenReuse   = status.Error(codes.Unauthenticated, "refresh token reuse detected")
+)
+
+// newOpaqueToken returns a random token, as handed out for refresh tokens
+// and login challenges, and the hash it is stored under.
+func newOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startFamily revokes the refresh tokens a session still holds and stores
+// tokenHash as the first token of a new family.
+func (r *UserRepository) startFamily(ctx context.Context, sessionID, userID, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE session_id = $1 AND revoked_at IS NULL`, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, session_id, family_id, user_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, $5)`,
+		tokenHash, sessionID, uuid.New().String(), userID, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token. The presented token is used up; presenting it again is
+// treated as theft and revokes every token of its family along with the
+// session.
+func (r *UserRepository) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, errInvalidRefreshToken
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		sessionID, familyID, userID string
+		used, revoked, expired      bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT session_id, family_id, user_id,
+			used_at IS NOT NULL, revoked_at IS NOT NULL, expires_at < CURRENT_TIMESTAMP
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`, hashToken(req.RefreshToken)).Scan(
+		&sessionID, &familyID, &userID, &used, &revoked, &expired,
+	)
+	if err == sql.ErrNoRows {
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if req.SessionId != "" && req.SessionId != sessionID {
+		return nil, errInvalidRefreshToken
+	}
+
+	if used {
+		if err := r.revokeFamily(ctx, tx, familyID, sessionID); err != nil {
+			return nil, err
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		r.logger.Log(common.WarnLevel, "Refresh token reuse detected: revoked family %s of session %s (user %s)",
+			familyID, sessionID, userID)
+		r.revokeSessions(ctx, sessionID)
+		return nil, errRefreshTokenReuse
+	}
+	if revoked || expired {
+		return nil, errInvalidRefreshToken
+	}
+
+	user := &pb.UserInfo{}
+	var sessionActive, mfaVerified bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.user_id, u.name, u.email, u.role, COALESCE(u.is_email_verified, false), s.is_active, s.mfa_verified
+		FROM sessions s
+		JOIN users u ON s.user_id = u.user_id
+		WHERE s.session_id = $1`, sessionID).Scan(
+		&user.UserId, &user.Name, &user.Email, &user.Role, &user.IsEmailVerified, &sessionActive, &mfaVerified,
+	)
+	if err == sql.ErrNoRows || (err == nil && !sessionActive) {
+		return nil, errInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if _, err := r.withPermissions(ctx, user, mfaVerified); err != nil {
+		return nil, err
+	}
+	accessToken, err := middleware.GenerateToken(user, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	refreshToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(r.RefreshTokenTTL)
+
+	_, err = tx.ExecContext(ctx, "UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE token_hash = $1",
+		hashToken(req.RefreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO refresh_tokens (token_hash, session_id, family_id, user_id, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, $5)`,
+		tokenHash, sessionID, familyID, userID, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE sessions
+		SET refresh_token = $1, expires_at = $2, last_activity_at = CURRENT_TIMESTAMP
+		WHERE session_id = $3`,
+		tokenHash, expiresAt, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &pb.RefreshTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(middleware.AccessTokenTTL).Unix(),
+	}, nil
+}
+
+// revokeFamily revokes every token of a family and ends the session it
+// belongs to.
+func (r *UserRepository) revokeFamily(ctx context.Context, tx *sql.Tx, familyID, sessionID string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke token family: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE sessions SET is_active = false WHERE session_id = $1", sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// expectNewRefreshFamily expects a session to start a refresh token family.
+// The ids are strings, or sqlmock.AnyArg() when they are generated.
+func expectNewRefreshFamily(mock sqlmock.Sqlmock, sessionID, userID interface{}) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).
+		WithArgs(sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), sessionID, sqlmock.AnyArg(), userID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+}
+
+func refreshTokenRow(used, revoked, expired bool) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"session_id", "family_id", "user_id", "used", "revoked", "expired"}).
+		AddRow("session-1", "family-1", "user-123", used, revoked, expired)
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM refresh_tokens`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(refreshTokenRow(false, false, false))
+	mock.ExpectQuery(`FROM sessions`).
+		WithArgs("session-1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "role", "is_email_verified", "is_active", "mfa_verified"}).
+			AddRow("user-123", "Wafi", "wafiq610@gmail.com", "user", true, true, false))
+	mock.ExpectQuery(`FROM user_roles`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permission", "requires_mfa"}))
+	mock.ExpectExec(`UPDATE refresh_tokens SET used_at`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), "session-1", "family-1", "user-123", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE sessions`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "session-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := repo.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "old-token"})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, resp.AccessToken)
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.NotEqual(t, "old-token", resp.RefreshToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM refresh_tokens`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(refreshTokenRow(true, false, false))
+	mock.ExpectExec(`UPDATE refresh_tokens SET revoked_at`).
+		WithArgs("family-1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE sessions SET is_active = false`).
+		WithArgs("session-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := repo.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "used-token"})
+
+	assert.Nil(t, resp)
+	assert.ErrorContains(t, err, "refresh token reuse detected")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenRejectsRevokedAndExpired(t *testing.T) {
+	for _, row := range []*sqlmock.Rows{refreshTokenRow(false, true, false), refreshTokenRow(false, false, true)} {
+		db, mock, repo := SetupMockDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM refresh_tokens`).
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(row)
+		mock.ExpectRollback()
+
+		_, err := repo.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: "token"})
+
+		assert.ErrorContains(t, err, "invalid refresh token")
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
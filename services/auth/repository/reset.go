@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+	"github.com/wafi04/golang-backend/services/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultPasswordResetURL is the page reset links point to; the token
+	// is added as the "token" query parameter.
+	DefaultPasswordResetURL = "http://localhost:3000/reset-password"
+
+	passwordResetTTL  = time.Hour
+	minPasswordLength = 8
+)
+
+// RequestPasswordReset emails a single-use reset link. It answers the same
+// whether or not the email belongs to an account, and never returns the
+// token itself.
+func (r *UserRepository) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	resp := &pb.RequestPasswordResetResponse{
+		Success:   true,
+		ExpiresAt: time.Now().Add(passwordResetTTL).Unix(),
+	}
+
+	userID, name, email, err := r.findActiveUserByEmail(ctx, req.Email)
+	if err == sql.ErrNoRows {
+		return resp, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, _, err := r.issueToken(ctx, userID, tokenTypePasswordReset, passwordResetTTL)
+	if err != nil {
+		return nil, err
+	}
+	link, err := withToken(r.PasswordResetURL, token)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Mailer.Send(ctx, mailer.PasswordReset, email, mailer.PasswordResetData{
+		Name:      name,
+		Link:      link,
+		ExpiresIn: "1 hour",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send email: %w", err)
+	}
+	return resp, nil
+}
+
+// ResetPassword sets a new password with a token from a reset link and
+// ends all of the user's sessions.
+func (r *UserRepository) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	if len(req.NewPassword) < minPasswordLength {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid password: must be at least %d characters", minPasswordLength)
+	}
+	hashedPassword, err := r.Passwords.Hash(req.NewPassword)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	userID, err := consumeToken(ctx, tx, req.ResetToken, tokenTypePasswordReset)
+	if err == sql.ErrNoRows {
+		return &pb.ResetPasswordResponse{
+			Success: false,
+			Message: "Invalid or expired reset token",
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify reset token: %w", err)
+	}
+
+	var updatedAt int64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users
+		SET password_hash = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2
+		RETURNING EXTRACT(EPOCH FROM updated_at)::bigint`,
+		hashedPassword, userID).Scan(&updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// Whoever knew the old password may hold a session; end them all.
+	// Their refresh tokens go with them.
+	sessionIDs, err := endSessions(ctx, tx, "DELETE FROM sessions WHERE user_id = $1 RETURNING session_id", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.revokeSessions(ctx, sessionIDs...)
+	r.logger.Log(common.InfoLevel, "Password reset for user %s, ended %d sessions", userID, len(sessionIDs))
+
+	return &pb.ResetPasswordResponse{
+		Success:   true,
+		Message:   "Password successfully reset",
+		UpdatedAt: updatedAt,
+	}, nil
+}
+
+// findActiveUserByEmail looks up who an emailed link goes to. It returns
+// sql.ErrNoRows when the email has no active account.
+func (r *UserRepository) findActiveUserByEmail(ctx context.Context, email string) (userID, name, address string, err error) {
+	err = r.DB.QueryRowContext(ctx,
+		"SELECT user_id, name, email FROM users WHERE email = $1 AND is_active = true",
+		email).Scan(&userID, &name, &address)
+	if err != nil && err != sql.ErrNoRows {
+		err = fmt.Errorf("failed to check user: %w", err)
+	}
+	return userID, name, address, err
+}
+
+// withToken adds token to link as the "token" query parameter.
+func withToken(link, token string) (string, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid link url: %w", err)
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,118 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+)
+
+func TestRequestPasswordResetUnknownEmail(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`FROM users WHERE email = \$1`).
+		WithArgs("nobody@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email"}))
+
+	resp, err := repo.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{
+		Email: "nobody@example.com",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.NotZero(t, resp.ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRequestPasswordResetQueuesLink(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+	repo.Mailer = mailer.New(db, &mailer.MemorySender{})
+
+	mock.ExpectQuery(`FROM users WHERE email = \$1`).
+		WithArgs("wafiq610@gmail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email"}).
+			AddRow("user-1", "Wafi", "wafiq610@gmail.com"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE verification_tokens SET is_used = true`).
+		WithArgs("user-1", "PASSWORD_RESET").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO verification_tokens`).
+		WithArgs(sqlmock.AnyArg(), "user-1", "PASSWORD_RESET", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`INSERT INTO email_outbox`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	resp, err := repo.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{
+		Email: "wafiq610@gmail.com",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPasswordEndsSessions(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE verification_tokens SET is_used = true`).
+		WithArgs(sqlmock.AnyArg(), "PASSWORD_RESET").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("user-1"))
+	mock.ExpectQuery(`UPDATE users`).
+		WithArgs(sqlmock.AnyArg(), "user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(1700000000))
+	mock.ExpectQuery(`DELETE FROM sessions WHERE user_id = \$1`).
+		WithArgs("user-1").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id"}).AddRow("session-1").AddRow("session-2"))
+	mock.ExpectCommit()
+
+	resp, err := repo.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		ResetToken:  "token",
+		NewPassword: "newpassword123",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(1700000000), resp.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPasswordRejectsInvalidToken(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE verification_tokens SET is_used = true`).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectRollback()
+
+	resp, err := repo.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		ResetToken:  "used-token",
+		NewPassword: "newpassword123",
+	})
+
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestResetPasswordRejectsShortPassword(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	_, err := repo.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+		ResetToken:  "token",
+		NewPassword: "short",
+	})
+
+	assert.ErrorContains(t, err, "invalid password")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// revokeSessions denylists sessions so their access tokens stop working at
+// once. Without a revocation store they keep working until they expire.
+func (r *UserRepository) revokeSessions(ctx context.Context, sessionIDs ...string) {
+	if r.Revocations == nil || len(sessionIDs) == 0 {
+		return
+	}
+	if err := r.Revocations.Revoke(ctx, sessionIDs...); err != nil {
+		r.logger.Log(common.ErrorLevel, "Failed to revoke sessions %v: %v", sessionIDs, err)
+	}
+}
+
+// endSessions runs query, a DELETE of sessions RETURNING session_id, in tx
+// and returns the ids to pass to revokeSessions once tx commits. The
+// sessions' refresh tokens go with them.
+func endSessions(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessionIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessionIDs = append(sessionIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return sessionIDs, nil
+}
+
+// LogoutAll ends every session of a user, on all devices.
+func (r *UserRepository) LogoutAll(ctx context.Context, req *pb.LogoutAllRequest) (*pb.LogoutAllResponse, error) {
+	rows, err := r.DB.QueryContext(ctx, "DELETE FROM sessions WHERE user_id = $1 RETURNING session_id", req.UserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessionIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessionIDs = append(sessionIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	r.revokeSessions(ctx, sessionIDs...)
+
+	return &pb.LogoutAllResponse{
+		Success:         true,
+		RevokedSessions: int64(len(sessionIDs)),
+	}, nil
+}
@@ -0,0 +1,41 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+func TestLogoutAll(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`DELETE FROM sessions WHERE user_id = \$1 RETURNING session_id`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id"}).AddRow("session-1").AddRow("session-2"))
+
+	resp, err := repo.LogoutAll(context.Background(), &pb.LogoutAllRequest{UserId: "user-123"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, int64(2), resp.RevokedSessions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogoutDeletesSessionByID(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM sessions`).
+		WithArgs("session-1", "user-123").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := repo.Logout(context.Background(), &pb.LogoutRequest{SessionId: "session-1", UserId: "user-123"})
+
+	assert.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	roleNamePattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	permissionPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*:[a-z][a-z0-9_]*$`)
+)
+
+// UserPermissions returns the roles of a user and the permissions they
+// grant, both sorted.
+func (r *UserRepository) UserPermissions(ctx context.Context, userID string) ([]string, []string, error) {
+	roles, permissions, _, err := r.userPermissions(ctx, userID, true)
+	return roles, permissions, err
+}
+
+// userPermissions is UserPermissions for a session. Roles that require 2FA
+// are left out unless mfaVerified; withheld reports whether any were.
+func (r *UserRepository) userPermissions(ctx context.Context, userID string, mfaVerified bool) (roles, permissions []string, withheld bool, err error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT ur.role, COALESCE(rp.permission, ''), ro.requires_mfa
+		FROM user_roles ur
+		JOIN roles ro ON ro.name = ur.role
+		LEFT JOIN role_permissions rp ON rp.role = ur.role
+		WHERE ur.user_id = $1
+		ORDER BY ur.role, rp.permission`, userID)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles = []string{}
+	seen := map[string]bool{}
+	for rows.Next() {
+		var (
+			role, permission string
+			requiresMFA      bool
+		)
+		if err := rows.Scan(&role, &permission, &requiresMFA); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to scan user role: %w", err)
+		}
+		if requiresMFA && !mfaVerified {
+			withheld = true
+			continue
+		}
+		if len(roles) == 0 || roles[len(roles)-1] != role {
+			roles = append(roles, role)
+		}
+		if permission != "" {
+			seen[permission] = true
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	permissions = make([]string, 0, len(seen))
+	for permission := range seen {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+
+	return roles, permissions, withheld, nil
+}
+
+// withPermissions fills in the roles and permissions of user before a token
+// is issued for it, and reports whether roles requiring 2FA were withheld
+// because the session did not pass it.
+func (r *UserRepository) withPermissions(ctx context.Context, user *pb.UserInfo, mfaVerified bool) (bool, error) {
+	roles, permissions, withheld, err := r.userPermissions(ctx, user.UserId, mfaVerified)
+	if err != nil {
+		return false, err
+	}
+	user.Roles = roles
+	user.Permissions = permissions
+	return withheld, nil
+}
+
+func (r *UserRepository) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+		SELECT ro.name, ro.description, ro.requires_mfa, COALESCE(rp.permission, '')
+		FROM roles ro
+		LEFT JOIN role_permissions rp ON rp.role = ro.name
+		ORDER BY ro.name, rp.permission`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*pb.Role
+	for rows.Next() {
+		var (
+			name, description, permission string
+			requiresMFA                   bool
+		)
+		if err := rows.Scan(&name, &description, &requiresMFA, &permission); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		if len(roles) == 0 || roles[len(roles)-1].Name != name {
+			roles = append(roles, &pb.Role{Name: name, Description: description, RequiresMfa: requiresMFA})
+		}
+		if permission != "" {
+			role := roles[len(roles)-1]
+			role.Permissions = append(role.Permissions, permission)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	return &pb.ListRolesResponse{
+		Roles: roles,
+	}, nil
+}
+
+// UpsertRole creates a role or replaces its description and permissions.
+func (r *UserRepository) UpsertRole(ctx context.Context, req *pb.Role) (*pb.Role, error) {
+	if !roleNamePattern.MatchString(req.Name) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid role name %q", req.Name)
+	}
+	for _, permission := range req.Permissions {
+		if !permissionPattern.MatchString(permission) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid permission %q: expected resource:action", permission)
+		}
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO roles (name, description, requires_mfa, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, requires_mfa = EXCLUDED.requires_mfa`,
+		req.Name, req.Description, req.RequiresMfa)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save role: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "DELETE FROM role_permissions WHERE role = $1 RETURNING permission", req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save role permissions: %w", err)
+	}
+	var previous []string
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan role permission: %w", err)
+		}
+		previous = append(previous, permission)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to save role permissions: %w", err)
+	}
+
+	permissions := uniqueSorted(req.Permissions)
+	for _, permission := range permissions {
+		_, err := tx.ExecContext(ctx, "INSERT INTO role_permissions (role, permission) VALUES ($1, $2)", req.Name, permission)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save role permissions: %w", err)
+		}
+	}
+
+	// Access tokens carry the permissions they were issued with, so holders
+	// of the role are logged out when it loses any.
+	var sessionIDs []string
+	if !middleware.HasPermissions(permissions, previous...) {
+		sessionIDs, err = endSessions(ctx, tx, `
+			DELETE FROM sessions
+			WHERE user_id IN (SELECT user_id FROM user_roles WHERE role = $1)
+			RETURNING session_id`,
+			req.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.revokeSessions(ctx, sessionIDs...)
+
+	return &pb.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+		RequiresMfa: req.RequiresMfa,
+	}, nil
+}
+
+func (r *UserRepository) GetUserRoles(ctx context.Context, req *pb.GetUserRequest) (*pb.UserRolesResponse, error) {
+	if err := r.userExists(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	return r.userRolesResponse(ctx, req.UserId)
+}
+
+func (r *UserRepository) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	if err := r.userExists(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM roles WHERE name = $1)", req.Role).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check role: %w", err)
+	}
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "role not found")
+	}
+
+	_, err := r.DB.ExecContext(ctx, `
+		INSERT INTO user_roles (user_id, role, created_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT DO NOTHING`,
+		req.UserId, req.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return r.userRolesResponse(ctx, req.UserId)
+}
+
+func (r *UserRepository) RevokeRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	if err := r.userExists(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM user_roles WHERE user_id = $1 AND role = $2", req.UserId, req.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke role: %w", err)
+	}
+
+	// The user's access tokens still carry the role's permissions; ending
+	// the sessions takes them away at once.
+	var sessionIDs []string
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		sessionIDs, err = endSessions(ctx, tx, "DELETE FROM sessions WHERE user_id = $1 RETURNING session_id", req.UserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.revokeSessions(ctx, sessionIDs...)
+
+	return r.userRolesResponse(ctx, req.UserId)
+}
+
+func (r *UserRepository) userRolesResponse(ctx context.Context, userID string) (*pb.UserRolesResponse, error) {
+	roles, permissions, err := r.UserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UserRolesResponse{
+		UserId:      userID,
+		Roles:       roles,
+		Permissions: permissions,
+	}, nil
+}
+
+func (r *UserRepository) userExists(ctx context.Context, userID string) error {
+	var exists bool
+	if err := r.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)", userID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+	if !exists {
+		return status.Errorf(codes.NotFound, "user not found")
+	}
+	return nil
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
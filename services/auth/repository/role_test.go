@@ -0,0 +1,37 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+func TestRevokeRoleEndsSessions(t *testing.T) {
+	db, mock, repo := SetupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM users`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM user_roles`).
+		WithArgs("user-123", "admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// Tokens issued while the user was an admin must stop working.
+	mock.ExpectQuery(`DELETE FROM sessions WHERE user_id = \$1 RETURNING session_id`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"session_id"}).AddRow("session-1"))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`FROM user_roles`).
+		WithArgs("user-123").
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permission"}))
+
+	resp, err := repo.RevokeRole(context.Background(), &pb.AssignRoleRequest{UserId: "user-123", Role: "admin"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Permissions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"github.com/wafi04/golang-backend/services/common"
-	"github.com/wafi04/golang-backend/services/common/middleware"
 )
 
 func (sr *UserRepository)   RevokeSession(ctx context.Context,req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse,error){
@@ -22,9 +21,11 @@ func (sr *UserRepository)   RevokeSession(ctx context.Context,req *pb.RevokeSess
 
 	if err != nil {
 		sr.logger.Log(common.ErrorLevel, "Failed to Delete Session : %v",err)
-		return nil, nil
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
 	}
 
+	sr.revokeSessions(ctx, req.SessionId)
+
 	return &pb.RevokeSessionResponse{
 		Success: true,},nil
 }
@@ -41,8 +42,9 @@ func (sr *UserRepository) CreateSession(ctx context.Context, session *pb.Session
            is_active, 
            expires_at, 
            last_activity_at, 
-           created_at
-       ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+           created_at,
+           mfa_verified
+       ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
    `
 
    updateQuery := `
@@ -51,45 +53,62 @@ func (sr *UserRepository) CreateSession(ctx context.Context, session *pb.Session
            access_token = $1, 
            refresh_token = $2, 
            ip_address = $3, 
-           last_activity_at = $4
-       WHERE user_id = $5 AND device_info = $6
+           last_activity_at = $4,
+           expires_at = $5,
+           is_active = true,
+           mfa_verified = $6
+       WHERE user_id = $7 AND device_info = $8
+       RETURNING session_id
    `
 
    if session.SessionId == "" {
        session.SessionId = uuid.New().String()
    }
 
+   // The caller gets the opaque refresh token; only its hash is stored.
+   refreshToken, tokenHash, err := newOpaqueToken()
+   if err != nil {
+       return err
+   }
+
    now := time.Now()
-   expiresAt := now.Add(24 * time.Hour)
+   expiresAt := now.Add(sr.RefreshTokenTTL)
 
    // Pertama, coba insert
-   _, err := sr.DB.ExecContext(
+   _, err = sr.DB.ExecContext(
        ctx, 
        insertQuery, 
        session.SessionId, 
        session.UserId, 
        session.AccessToken, 
-       session.RefreshToken, 
+       tokenHash, 
        session.IpAddress, 
        session.DeviceInfo, 
        true,
        expiresAt,
        now,
        now,
+       session.MfaVerified,
    )
 
    // Jika insert gagal (misal duplicate), lakukan update
    if err != nil {
-       _, err = sr.DB.ExecContext(
+       err = sr.DB.QueryRowContext(
            ctx, 
            updateQuery, 
            session.AccessToken, 
-           session.RefreshToken, 
+           tokenHash, 
            session.IpAddress, 
            now,
+           expiresAt,
+           session.MfaVerified,
            session.UserId, 
            session.DeviceInfo,
-       )
+       ).Scan(&session.SessionId)
+   }
+
+   if err == nil {
+       err = sr.startFamily(ctx, session.SessionId, session.UserId, tokenHash, expiresAt)
    }
 
    if err != nil {
@@ -100,51 +119,11 @@ func (sr *UserRepository) CreateSession(ctx context.Context, session *pb.Session
        return fmt.Errorf("failed to create session: %w", err)
    }
 
+   session.RefreshToken = refreshToken
+   session.IsActive = true
+   session.ExpiresAt = expiresAt.Unix()
    return nil
 }
-func (sr *UserRepository) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
-    sr.logger.Log(common.InfoLevel, "Refresh Token Incoming")
-
-    query := `
-        SELECT u.user_id, u.email, u.role, u.is_email_verified
-        FROM sessions s
-        JOIN users u ON s.user_id = u.user_id
-        WHERE s.session_id = $1
-    `
-    var user pb.UserInfo
-    err := sr.DB.QueryRowContext(ctx, query, req.SessionId).Scan(
-        &user.UserId, 
-        &user.Email, 
-        &user.Role, 
-        &user.IsEmailVerified,
-    )
-    if err != nil {
-        sr.logger.Log(common.ErrorLevel, "Failed to retrieve user: %v", err)
-        return nil, err
-    }
-
-    updateQuery := `
-        UPDATE sessions SET access_token = $1 WHERE session_id = $2
-    `
-    _, err = sr.DB.ExecContext(ctx, updateQuery, req.RefreshToken, req.SessionId)
-    if err != nil {
-        sr.logger.Log(common.ErrorLevel, "Failed to Refresh Token: %v", err)
-        return nil, err
-    }
-
-    token, err := middleware.GenerateToken(&user)
-    if err != nil {
-        return nil, err
-    }
-
-    return &pb.RefreshTokenResponse{
-        AccessToken:  token,
-        RefreshToken: token,
-        ExpiresAt:    time.Now().Add(24 * time.Hour).Unix(),
-    }, nil
-}
-
-
 func (sr *UserRepository) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
     query := `
         SELECT 
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Single-use tokens sent by email live in verification_tokens. Only their
+// hash is stored, so a leaked table does not hand out working links.
+const (
+	tokenTypePasswordReset = "PASSWORD_RESET"
+	tokenTypeMagicLink     = "MAGIC_LINK"
+)
+
+// issueToken creates a single-use token of tokenType for a user. Earlier
+// unused tokens of the same type stop working, so only the latest email
+// is valid.
+func (r *UserRepository) issueToken(ctx context.Context, userID, tokenType string, ttl time.Duration) (string, time.Time, error) {
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE verification_tokens SET is_used = true
+		WHERE user_id = $1 AND token_type = $2 AND is_used = false`,
+		userID, tokenType)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to invalidate tokens: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO verification_tokens (token, user_id, token_type, expires_at)
+		VALUES ($1, $2, $3, $4)`,
+		tokenHash, userID, tokenType, expiresAt)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create token: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// consumeToken uses up a token and returns the user it was issued to. It
+// returns sql.ErrNoRows when the token is unknown, used or expired.
+func consumeToken(ctx context.Context, tx *sql.Tx, token, tokenType string) (string, error) {
+	var userID string
+	err := tx.QueryRowContext(ctx, `
+		UPDATE verification_tokens SET is_used = true
+		WHERE token = $1 AND token_type = $2 AND is_used = false AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id`,
+		hashToken(token), tokenType).Scan(&userID)
+	return userID, err
+}
@@ -3,16 +3,17 @@ package repository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/auth/mailer"
+	"github.com/wafi04/golang-backend/services/auth/oidc"
+	"github.com/wafi04/golang-backend/services/auth/password"
 	"github.com/wafi04/golang-backend/services/common"
 	"github.com/wafi04/golang-backend/services/common/middleware"
-	"golang.org/x/crypto/bcrypt"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -20,31 +21,63 @@ import (
 type UserRepository struct {
 	DB     *sqlx.DB
 	logger common.Logger
+	// Passwords hashes new passwords and verifies stored ones.
+	Passwords *password.Hasher
+	// RefreshTokenTTL is how long a refresh token stays valid.
+	RefreshTokenTTL time.Duration
+	// Revocations, when set, denylists the sessions that are ended so
+	// their access tokens are rejected before they expire.
+	Revocations *middleware.RevocationStore
+	// MFAIssuer names the service in authenticator apps.
+	MFAIssuer string
+	// Lockout throttles failed logins.
+	Lockout LockoutPolicy
+	// Mailer queues the emails users are sent.
+	Mailer *mailer.Mailer
+	// PasswordResetURL is the page reset links open.
+	PasswordResetURL string
+	// MagicLinkURL is the page login links open, and MagicLinkKey signs
+	// them.
+	MagicLinkURL string
+	MagicLinkKey []byte
+	// OIDC holds the providers users can sign in with; nil allows none.
+	OIDC *oidc.Registry
 }
 
 func NewUserRepository(db *sqlx.DB) *UserRepository {
+	passwords, _ := password.NewHasher(password.DefaultConfig())
 	return &UserRepository{
-		DB: db,
+		DB:               db,
+		Passwords:        passwords,
+		RefreshTokenTTL:  DefaultRefreshTokenTTL,
+		MFAIssuer:        DefaultMFAIssuer,
+		Lockout:          DefaultLockoutPolicy(),
+		PasswordResetURL: DefaultPasswordResetURL,
+		MagicLinkURL:     DefaultMagicLinkURL,
+		MagicLinkKey:     newMagicLinkKey(),
 	}
 }
 
 func (r *UserRepository) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (pb.CreateUserResponse, error) {
 
+	// New users start without permissions; roles are granted by admins.
 	role := "user"
-	if req.Email == "wafiq610@gmail.com" {
-		role = "admin"
-	}
 	userID := uuid.New().String()
 	now := time.Now()
+
+	passwordHash, err := r.Passwords.Hash(req.Password)
+	if err != nil {
+		return pb.CreateUserResponse{}, err
+	}
 	query := `
         INSERT INTO users (
             user_id, name, email, password_hash, role,
             is_active, is_email_verified, created_at, updated_at
         ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
     `
-	_, err := r.DB.ExecContext(
+	_, err = r.DB.ExecContext(
 		ctx, query,
-		userID, req.Name, req.Email, req.Password, role,
+		userID, req.Name, req.Email, passwordHash, role,
 		true, false, now, now,
 	)
 
@@ -52,28 +85,13 @@ func (r *UserRepository) CreateUser(ctx context.Context, req *pb.CreateUserReque
 		return pb.CreateUserResponse{}, fmt.Errorf("failed to create verification token: %w", err)
 	}
 
-	token, err := middleware.GenerateToken(&pb.UserInfo{
-		UserId:          userID,
-		Name:            req.Name,
-		Email:           req.Email,
-		Role:            role,
-		IsEmailVerified: false,
-	})
-	if err != nil {
-		return pb.CreateUserResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
-	}
-
 	session := pb.Session{
 		SessionId:      uuid.New().String(),
 		UserId:         userID,
-		AccessToken:    token,
-		RefreshToken:   token,
 		IpAddress:      req.IpAddress,
 		DeviceInfo:     req.DeviceInfo,
 		CreatedAt:      time.Now().Unix(),
 		LastActivityAt: time.Now().Unix(),
-		IsActive:       true,
-		ExpiresAt:      time.Now().Unix(),
 	}
 
 	err = r.CreateSession(ctx, &session)
@@ -81,6 +99,17 @@ func (r *UserRepository) CreateUser(ctx context.Context, req *pb.CreateUserReque
 		return pb.CreateUserResponse{}, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	token, err := middleware.GenerateToken(&pb.UserInfo{
+		UserId:          userID,
+		Name:            req.Name,
+		Email:           req.Email,
+		Role:            role,
+		IsEmailVerified: false,
+	}, session.SessionId)
+	if err != nil {
+		return pb.CreateUserResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
 	return pb.CreateUserResponse{
 		UserId:      userID,
 		Name:        req.Name,
@@ -89,9 +118,11 @@ func (r *UserRepository) CreateUser(ctx context.Context, req *pb.CreateUserReque
 		Picture:     req.Picture,
 		AccessToken: token,
 		SessionInfo: &pb.Session{
-			SessionId:  session.SessionId,
-			DeviceInfo: session.DeviceInfo,
-			IpAddress:  session.IpAddress,
+			SessionId:    session.SessionId,
+			RefreshToken: session.RefreshToken,
+			DeviceInfo:   session.DeviceInfo,
+			IpAddress:    session.IpAddress,
+			ExpiresAt:    session.ExpiresAt,
 		},
 	}, nil
 
@@ -112,6 +143,9 @@ type dbUser struct {
 }
 
 func (r *UserRepository) Login(ctx context.Context, login *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if err := r.claimLoginAttempt(ctx, login.Email, login.IpAddress); err != nil {
+		return nil, err
+	}
 
 	query := `
     SELECT
@@ -148,7 +182,10 @@ func (r *UserRepository) Login(ctx context.Context, login *pb.LoginRequest) (*pb
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			// Unknown emails fail like wrong passwords.
+			r.verifyDummyPassword(login.Password)
+			r.recordLoginFailure(ctx, login.Email, login.IpAddress, nil)
+			return nil, errInvalidCredentials
 		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -164,15 +201,43 @@ func (r *UserRepository) Login(ctx context.Context, login *pb.LoginRequest) (*pb
 		LastLoginAt:     dbuser.LastLoginAt,
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(dbuser.Password), []byte(login.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+	ok, needsRehash, err := r.Passwords.Verify(login.Password, dbuser.Password)
+	if err != nil {
+		r.logger.Log(common.ErrorLevel, "Failed to verify password: %v", err)
+	}
+	if !ok {
+		r.recordLoginFailure(ctx, login.Email, login.IpAddress, userInfo)
+		return nil, errInvalidCredentials
+	}
+	if needsRehash {
+		r.rehashPassword(ctx, dbuser.UserID, login.Password)
+	}
+
+	enabled, err := r.mfaEnabled(ctx, userInfo.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		// Failures are only forgotten once the second factor is right too;
+		// until then only this attempt is given back.
+		r.refundLoginAttempt(ctx, login.Email, login.IpAddress)
+		return r.startMFAChallenge(ctx, userInfo.UserId, login.DeviceInfo, login.IpAddress)
 	}
-	token, err := middleware.GenerateToken(userInfo)
+	r.clearLoginFailures(ctx, login.Email, login.IpAddress)
+
+	return r.completeLogin(ctx, userInfo, login.DeviceInfo, login.IpAddress, false)
+}
+
+// completeLogin opens a session for an authenticated user, reusing the
+// user's session on the same device, and issues its tokens. mfaVerified
+// records whether the second factor was checked.
+func (r *UserRepository) completeLogin(ctx context.Context, userInfo *pb.UserInfo, deviceInfo, ipAddress string, mfaVerified bool) (*pb.LoginResponse, error) {
+	withheld, err := r.withPermissions(ctx, userInfo, mfaVerified)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate tokens: %w", err)
+		return nil, err
 	}
 
-	query = `
+	query := `
         SELECT 
             session_id, 
             ip_address,
@@ -184,7 +249,7 @@ func (r *UserRepository) Login(ctx context.Context, login *pb.LoginRequest) (*pb
     `
 
 	var existingSession pb.Session
-	err = r.DB.QueryRowContext(ctx, query, userInfo.UserId, login.DeviceInfo).Scan(
+	err = r.DB.QueryRowContext(ctx, query, userInfo.UserId, deviceInfo).Scan(
 		&existingSession.SessionId,
 		&existingSession.IpAddress,
 		&existingSession.DeviceInfo,
@@ -199,21 +264,24 @@ func (r *UserRepository) Login(ctx context.Context, login *pb.LoginRequest) (*pb
 	if err == sql.ErrNoRows {
 		existingSession = pb.Session{
 			SessionId:      uuid.New().String(),
-			UserId:         userInfo.UserId,
-			AccessToken:    token,
-			RefreshToken:   token,
-			IpAddress:      login.IpAddress,
-			DeviceInfo:     login.DeviceInfo,
+			DeviceInfo:     deviceInfo,
 			CreatedAt:      time.Now().Unix(),
 			LastActivityAt: time.Now().Unix(),
-			IsActive:       true,
-			ExpiresAt:      time.Now().Unix(),
 		}
+	}
 
-		err = r.CreateSession(ctx, &existingSession)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create session: %w", err)
-		}
+	// Every login starts a new refresh token family for the session.
+	existingSession.UserId = userInfo.UserId
+	existingSession.IpAddress = ipAddress
+	existingSession.MfaVerified = mfaVerified
+	err = r.CreateSession(ctx, &existingSession)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	token, err := middleware.GenerateToken(userInfo, existingSession.SessionId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
 	_, err = r.DB.ExecContext(
@@ -226,8 +294,11 @@ func (r *UserRepository) Login(ctx context.Context, login *pb.LoginRequest) (*pb
 	}
 
 	return &pb.LoginResponse{
-		AccessToken: token,
-		UserId:      userInfo.UserId,
+		AccessToken:           token,
+		RefreshToken:          existingSession.RefreshToken,
+		ExpiresAt:             time.Now().Add(middleware.AccessTokenTTL).Unix(),
+		UserId:                userInfo.UserId,
+		MfaEnrollmentRequired: withheld && !mfaVerified,
 		SessionInfo: &pb.SessionInfo{
 			SessionId:      existingSession.SessionId,
 			DeviceInfo:     existingSession.DeviceInfo,
@@ -289,6 +360,11 @@ func (sr *UserRepository) GetUser(ctx context.Context, req *pb.GetUserRequest) (
 		user.User.Picture = picture.String
 	}
 
+	if _, err := sr.withPermissions(ctx, user.User, true); err != nil {
+		sr.logger.Log(common.ErrorLevel, "Error fetching user roles: %v", err)
+		return nil, status.Errorf(codes.Internal, "database error")
+	}
+
 	user.User.CreatedAt = createdAt.Unix()
 	user.User.UpdatedAt = updatedAt.Unix()
 	user.User.LastLoginAt = lastLoginAt.Unix()
@@ -298,18 +374,17 @@ func (sr *UserRepository) GetUser(ctx context.Context, req *pb.GetUserRequest) (
 func (sr *UserRepository) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
 	query := `
 	DELETE FROM sessions
-    WHERE access_token = $1 AND user_id = $2
+    WHERE session_id = $1 AND user_id = $2
 	`
-	_, err := sr.DB.ExecContext(ctx, query, req.AccessToken, req.UserId)
+	_, err := sr.DB.ExecContext(ctx, query, req.SessionId, req.UserId)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, status.Errorf(codes.NotFound, "user not found")
-		}
-		sr.logger.Log(common.ErrorLevel, "Error fetching user: %v", err)
+		sr.logger.Log(common.ErrorLevel, "Error deleting session: %v", err)
 		return nil, status.Errorf(codes.Internal, "database error")
 	}
 
+	sr.revokeSessions(ctx, req.SessionId)
+
 	return &pb.LogoutResponse{
 		Success: true,
 	}, nil
@@ -10,8 +10,10 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"github.com/wafi04/golang-backend/services/auth/repository"
+	"github.com/wafi04/golang-backend/services/common/middleware"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -40,17 +42,17 @@ func TestCreateUser(t *testing.T) {
 	}
 
 	// Mock the user insertion
-	mock.ExpectExec(`INSERT INTO users \(user_id, name, email, password_hash, role, is_active, is_email_verified, created_at, updated_at\)`).
+	mock.ExpectExec(`INSERT INTO users`).
 		WithArgs(
 			sqlmock.AnyArg(), // user_id
 			req.Name,
 			req.Email,
-			req.Password,
-			"admin",    // role (since email is wafiq610@gmail.com)
-			true,       // is_active
-			false,      // is_email_verified
-			time.Now(), // created_at
-			time.Now(), // updated_at
+			sqlmock.AnyArg(), // password_hash
+			"user",           // role; admins are granted roles separately
+			true,             // is_active
+			false,            // is_email_verified
+			sqlmock.AnyArg(), // created_at
+			sqlmock.AnyArg(), // updated_at
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -59,27 +61,32 @@ func TestCreateUser(t *testing.T) {
 			sqlmock.AnyArg(), // session_id
 			sqlmock.AnyArg(), // user_id
 			sqlmock.AnyArg(), // access_token
-			sqlmock.AnyArg(), // refresh_token
+			sqlmock.AnyArg(), // refresh_token hash
 			req.IpAddress,
 			req.DeviceInfo,
-			sqlmock.AnyArg(), // created_at
-			sqlmock.AnyArg(), // last_activity_at
 			true,             // is_active
 			sqlmock.AnyArg(), // expires_at
+			sqlmock.AnyArg(), // last_activity_at
+			sqlmock.AnyArg(), // created_at
+			false,            // mfa_verified
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	expectNewRefreshFamily(mock, sqlmock.AnyArg(), sqlmock.AnyArg())
+
 	// Execute the function
 	resp, err := repo.CreateUser(context.Background(), req)
 
 	// Assertions
-	assert.NoError(t, err)
+	require.NoError(t, err)
 	assert.NotEmpty(t, resp.UserId)
 	assert.Equal(t, req.Name, resp.Name)
 	assert.Equal(t, req.Email, resp.Email)
-	assert.Equal(t, "admin", resp.Role)
+	assert.Equal(t, "user", resp.Role)
 	assert.NotEmpty(t, resp.AccessToken)
+	require.NotNil(t, resp.SessionInfo)
 	assert.NotEmpty(t, resp.SessionInfo.SessionId)
+	assert.NotEmpty(t, resp.SessionInfo.RefreshToken)
 	assert.Equal(t, req.DeviceInfo, resp.SessionInfo.DeviceInfo)
 	assert.Equal(t, req.IpAddress, resp.SessionInfo.IpAddress)
 
@@ -101,10 +108,26 @@ func TestLogin(t *testing.T) {
 	}).AddRow(
 		userID, "Wafi", email, "admin", string(hashedPassword), "", true, time.Now().Unix(), time.Now().Unix(), time.Now().Unix(), true,
 	)
+	expectLoginAllowed(mock)
 	mock.ExpectQuery(`SELECT`).
 		WithArgs(email).
 		WillReturnRows(rows)
 
+	mock.ExpectQuery(`FROM user_mfa`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	mock.ExpectExec(`DELETE FROM login_attempts`).
+		WithArgs("account", email).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectLoginRefunded(mock, "ip")
+
+	mock.ExpectQuery(`FROM user_roles`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role", "permission", "requires_mfa"}).
+			AddRow("admin", "catalog:write", false).
+			AddRow("admin", "roles:manage", false))
+
 	mock.ExpectQuery(`SELECT`).
 		WithArgs(userID, "Android").
 		WillReturnError(sql.ErrNoRows)
@@ -117,24 +140,29 @@ func TestLogin(t *testing.T) {
 			sqlmock.AnyArg(),
 			"127.0.0.1",      // ip_address
 			"Android",        // device_info
-			sqlmock.AnyArg(), // created_at
-			sqlmock.AnyArg(), // last_activity_at
 			true,             // is_active
 			sqlmock.AnyArg(), // expires_at
+			sqlmock.AnyArg(), // last_activity_at
+			sqlmock.AnyArg(), // created_at
+			false,            // mfa_verified
 		).
 		WillReturnError(fmt.Errorf("duplicate key")) // Simulasikan INSERT gagal
 
 	// Mock query UPDATE sessions (akan dijalankan setelah INSERT gagal)
-	mock.ExpectExec(`UPDATE sessions`).
+	mock.ExpectQuery(`UPDATE sessions`).
 		WithArgs(
 			sqlmock.AnyArg(), // access_token
-			sqlmock.AnyArg(), // refresh_token
+			sqlmock.AnyArg(), // refresh_token hash
 			"127.0.0.1",      // ip_address
 			sqlmock.AnyArg(), // last_activity_at
+			sqlmock.AnyArg(), // expires_at
+			false,            // mfa_verified
 			userID,           // user_id
 			"Android",        // device_info
 		).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnRows(sqlmock.NewRows([]string{"session_id"}).AddRow("session-1"))
+
+	expectNewRefreshFamily(mock, "session-1", userID)
 
 	mock.ExpectExec(`UPDATE users`).
 		WithArgs(userID).
@@ -152,6 +180,15 @@ func TestLogin(t *testing.T) {
 	assert.NotNil(t, resp)
 	assert.Equal(t, userID, resp.UserId)
 	assert.NotEmpty(t, resp.AccessToken)
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.NotEqual(t, resp.AccessToken, resp.RefreshToken)
+	assert.Equal(t, "session-1", resp.SessionInfo.SessionId)
+
+	claims, err := middleware.ValidateToken(resp.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.Equal(t, []string{"catalog:write", "roles:manage"}, claims.Permissions)
+	assert.Equal(t, "session-1", claims.SessionID)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
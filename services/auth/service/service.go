@@ -6,8 +6,6 @@ import (
 	"github.com/wafi04/common/pkg/logger"
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"github.com/wafi04/golang-backend/services/auth/repository"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService struct {
@@ -16,15 +14,11 @@ type UserService struct {
 }
 
 func (s *UserService) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (pb.CreateUserResponse, error) {
-
-	hashPw, err := bcrypt.GenerateFromPassword([]byte(req.Password), 10)
-	if err != nil {
-		s.log.Log(logger.ErrorLevel, "Failes Password : %v", err)
-	}
+	// The repository hashes the password.
 	return s.UserRepository.CreateUser(ctx, &pb.CreateUserRequest{
 		Name:     req.Name,
 		Email:    req.Email,
-		Password: string(hashPw),
+		Password: req.Password,
 		Role:     "",
 		IpAddress: req.IpAddress,
 		DeviceInfo: req.DeviceInfo,
@@ -35,6 +29,34 @@ func (s *UserService) Login(ctx context.Context, login *pb.LoginRequest) (*pb.Lo
 	return s.UserRepository.Login(ctx, login)
 }
 
+func (s *UserService) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginResponse, error) {
+	return s.UserRepository.VerifyLoginMFA(ctx, req)
+}
+
+func (s *UserService) EnrollMFA(ctx context.Context, req *pb.EnrollMFARequest) (*pb.EnrollMFAResponse, error) {
+	return s.UserRepository.EnrollMFA(ctx, req)
+}
+
+func (s *UserService) ConfirmMFA(ctx context.Context, req *pb.ConfirmMFARequest) (*pb.ConfirmMFAResponse, error) {
+	return s.UserRepository.ConfirmMFA(ctx, req)
+}
+
+func (s *UserService) DisableMFA(ctx context.Context, req *pb.DisableMFARequest) (*pb.DisableMFAResponse, error) {
+	return s.UserRepository.DisableMFA(ctx, req)
+}
+
+func (s *UserService) ListOIDCProviders(ctx context.Context, req *pb.ListOIDCProvidersRequest) (*pb.ListOIDCProvidersResponse, error) {
+	return s.UserRepository.ListOIDCProviders(ctx, req)
+}
+
+func (s *UserService) StartOIDCLogin(ctx context.Context, req *pb.StartOIDCLoginRequest) (*pb.StartOIDCLoginResponse, error) {
+	return s.UserRepository.StartOIDCLogin(ctx, req)
+}
+
+func (s *UserService) CompleteOIDCLogin(ctx context.Context, req *pb.CompleteOIDCLoginRequest) (*pb.LoginResponse, error) {
+	return s.UserRepository.CompleteOIDCLogin(ctx, req)
+}
+
 func (s *UserService) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
 	return s.UserRepository.GetUser(ctx, req)
 }
@@ -49,6 +71,21 @@ func (s *UserService) ResendVerification(ctx context.Context, req *pb.ResendVeri
 func (s *UserService) Logout(ctx context.Context, req *pb.LogoutRequest)(*pb.LogoutResponse,error){
 	return s.UserRepository.Logout(ctx, req)
 }
+func (s *UserService) LogoutAll(ctx context.Context, req *pb.LogoutAllRequest) (*pb.LogoutAllResponse, error) {
+	return s.UserRepository.LogoutAll(ctx, req)
+}
+func (s *UserService) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.RequestPasswordResetResponse, error) {
+	return s.UserRepository.RequestPasswordReset(ctx, req)
+}
+func (s *UserService) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	return s.UserRepository.ResetPassword(ctx, req)
+}
+func (s *UserService) RequestMagicLink(ctx context.Context, req *pb.RequestMagicLinkRequest) (*pb.RequestMagicLinkResponse, error) {
+	return s.UserRepository.RequestMagicLink(ctx, req)
+}
+func (s *UserService) ConsumeMagicLink(ctx context.Context, req *pb.ConsumeMagicLinkRequest) (*pb.LoginResponse, error) {
+	return s.UserRepository.ConsumeMagicLink(ctx, req)
+}
 func (s *UserService) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest)(*pb.RevokeSessionResponse,error){
 	return s.UserRepository.RevokeSession(ctx, req)
 }
@@ -58,3 +95,47 @@ func (s *UserService) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequ
 func (s *UserService) ListSessions(ctx context.Context, req *pb.ListSessionsRequest)(*pb.ListSessionsResponse,error){
 	return s.UserRepository.ListSessions(ctx, req)
 }
+
+func (s *UserService) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	return s.UserRepository.ListRoles(ctx, req)
+}
+
+func (s *UserService) UpsertRole(ctx context.Context, req *pb.Role) (*pb.Role, error) {
+	return s.UserRepository.UpsertRole(ctx, req)
+}
+
+func (s *UserService) GetUserRoles(ctx context.Context, req *pb.GetUserRequest) (*pb.UserRolesResponse, error) {
+	return s.UserRepository.GetUserRoles(ctx, req)
+}
+
+func (s *UserService) AssignRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	return s.UserRepository.AssignRole(ctx, req)
+}
+
+func (s *UserService) RevokeRole(ctx context.Context, req *pb.AssignRoleRequest) (*pb.UserRolesResponse, error) {
+	return s.UserRepository.RevokeRole(ctx, req)
+}
+
+func (s *UserService) UnlockAccount(ctx context.Context, req *pb.UnlockAccountRequest) (*pb.UnlockAccountResponse, error) {
+	return s.UserRepository.UnlockAccount(ctx, req)
+}
+
+func (s *UserService) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	return s.UserRepository.CreateAPIKey(ctx, req)
+}
+
+func (s *UserService) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	return s.UserRepository.ListAPIKeys(ctx, req)
+}
+
+func (s *UserService) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.APIKey, error) {
+	return s.UserRepository.RevokeAPIKey(ctx, req)
+}
+
+func (s *UserService) ValidateAPIKey(ctx context.Context, req *pb.ValidateAPIKeyRequest) (*pb.ValidateAPIKeyResponse, error) {
+	return s.UserRepository.ValidateAPIKey(ctx, req)
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	return s.UserRepository.UpdateUser(ctx, req)
+}
@@ -0,0 +1,219 @@
+// Package signing manages the keys the auth service signs access tokens
+// with. Keys live in the signing_keys table so every replica signs with the
+// same key; the current key is rotated on a schedule and the keys it
+// replaces stay published for an overlap window.
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+)
+
+// Config selects the signing algorithm and the rotation schedule. Overlap
+// must be at least the access token lifetime.
+type Config struct {
+	Algorithm        string
+	RotationInterval time.Duration
+	Overlap          time.Duration
+}
+
+// DefaultConfig signs with Ed25519 keys rotated weekly.
+func DefaultConfig() Config {
+	return Config{
+		Algorithm:        middleware.AlgEdDSA,
+		RotationInterval: 7 * 24 * time.Hour,
+		Overlap:          24 * time.Hour,
+	}
+}
+
+type Manager struct {
+	db     *sqlx.DB
+	config Config
+	keys   *middleware.KeySet
+	logger *common.Logger
+}
+
+func NewManager(db *sqlx.DB, config Config) (*Manager, error) {
+	if config.Algorithm != middleware.AlgEdDSA && config.Algorithm != middleware.AlgRS256 {
+		return nil, fmt.Errorf("unsupported signing algorithm %q", config.Algorithm)
+	}
+	if config.RotationInterval <= 0 || config.Overlap <= 0 {
+		return nil, errors.New("rotation interval and overlap must be positive")
+	}
+	return &Manager{
+		db:     db,
+		config: config,
+		logger: common.NewLogger(),
+	}, nil
+}
+
+// Keys returns the loaded key set. It is nil until Load succeeded.
+func (m *Manager) Keys() *middleware.KeySet {
+	return m.keys
+}
+
+// Load rotates the current key when it is due and loads the keys that are
+// still published.
+func (m *Manager) Load(ctx context.Context) error {
+	if err := m.rotateIfDue(ctx); err != nil {
+		return err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT kid, algorithm, private_key, not_after IS NULL
+		FROM signing_keys
+		WHERE not_after IS NULL OR not_after > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	var (
+		current  *middleware.SigningKey
+		previous []*middleware.SigningKey
+	)
+	for rows.Next() {
+		var (
+			kid, algorithm, encoded string
+			active                  bool
+		)
+		if err := rows.Scan(&kid, &algorithm, &encoded, &active); err != nil {
+			return fmt.Errorf("failed to scan signing key: %w", err)
+		}
+		private, err := decodePrivateKey(encoded)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", kid, err)
+		}
+		key := &middleware.SigningKey{ID: kid, Algorithm: algorithm, Private: private}
+		if active && current == nil {
+			current = key
+		} else {
+			previous = append(previous, key)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load signing keys: %w", err)
+	}
+	if current == nil {
+		return errors.New("no current signing key")
+	}
+
+	if m.keys == nil {
+		m.keys = middleware.NewKeySet(current, previous...)
+	} else {
+		m.keys.Replace(current, previous...)
+	}
+	return nil
+}
+
+// Run reloads the keys every interval until ctx is done, which also picks
+// up rotations done by other replicas.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Load(ctx); err != nil {
+				m.logger.Log(common.ErrorLevel, "Failed to reload signing keys: %v", err)
+			}
+		}
+	}
+}
+
+// rotateIfDue creates a new current key when there is none or the current
+// one is older than the rotation interval. The previous key is kept until
+// the overlap window has passed. An advisory lock keeps replicas from
+// rotating at the same time.
+func (m *Manager) rotateIfDue(ctx context.Context) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext('signing_keys'))"); err != nil {
+		return fmt.Errorf("failed to lock signing keys: %w", err)
+	}
+
+	var createdAt time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT created_at FROM signing_keys
+		WHERE not_after IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1`).Scan(&createdAt)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get current signing key: %w", err)
+	}
+	if err == nil && time.Since(createdAt) < m.config.RotationInterval {
+		return nil
+	}
+
+	key, err := middleware.GenerateSigningKey(m.config.Algorithm)
+	if err != nil {
+		return err
+	}
+	encoded, err := encodePrivateKey(key.Private)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if _, err := tx.ExecContext(ctx, "DELETE FROM signing_keys WHERE not_after < $1", now); err != nil {
+		return fmt.Errorf("failed to delete retired signing keys: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE signing_keys SET not_after = $1 WHERE not_after IS NULL", now.Add(m.config.Overlap))
+	if err != nil {
+		return fmt.Errorf("failed to retire signing key: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO signing_keys (kid, algorithm, private_key, created_at)
+		VALUES ($1, $2, $3, $4)`,
+		key.ID, key.Algorithm, encoded, now)
+	if err != nil {
+		return fmt.Errorf("failed to store signing key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	m.logger.Log(common.InfoLevel, "Rotated signing key, new kid %s", key.ID)
+	return nil
+}
+
+func encodePrivateKey(key crypto.Signer) (string, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})), nil
+}
+
+func decodePrivateKey(encoded string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("invalid PEM")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported key type")
+	}
+	return signer, nil
+}
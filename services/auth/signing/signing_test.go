@@ -0,0 +1,88 @@
+package signing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+)
+
+func newTestManager(t *testing.T) (*Manager, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	m, err := NewManager(sqlx.NewDb(db, "sqlmock"), DefaultConfig())
+	require.NoError(t, err)
+	return m, mock
+}
+
+func TestLoadRotatesWhenNoKey(t *testing.T) {
+	m, mock := newTestManager(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT created_at FROM signing_keys`).WillReturnRows(sqlmock.NewRows([]string{"created_at"}))
+	mock.ExpectExec(`DELETE FROM signing_keys`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE signing_keys SET not_after`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	mock.ExpectExec(`INSERT INTO signing_keys`).
+		WithArgs(sqlmock.AnyArg(), middleware.AlgEdDSA, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	key, err := middleware.GenerateSigningKey(middleware.AlgEdDSA)
+	require.NoError(t, err)
+	stored, err := encodePrivateKey(key.Private)
+	require.NoError(t, err)
+	mock.ExpectQuery(`FROM signing_keys`).WillReturnRows(sqlmock.NewRows([]string{"kid", "algorithm", "private_key", "active"}).
+		AddRow("new", middleware.AlgEdDSA, stored, true))
+
+	require.NoError(t, m.Load(context.Background()))
+	assert.Equal(t, "new", m.Keys().Current().ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLoadKeepsPreviousKeysPublished(t *testing.T) {
+	m, mock := newTestManager(t)
+
+	current, err := middleware.GenerateSigningKey(middleware.AlgEdDSA)
+	require.NoError(t, err)
+	previous, err := middleware.GenerateSigningKey(middleware.AlgRS256)
+	require.NoError(t, err)
+	currentPEM, err := encodePrivateKey(current.Private)
+	require.NoError(t, err)
+	previousPEM, err := encodePrivateKey(previous.Private)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT created_at FROM signing_keys`).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now().Add(-time.Hour)))
+	mock.ExpectRollback()
+
+	mock.ExpectQuery(`FROM signing_keys`).WillReturnRows(sqlmock.NewRows([]string{"kid", "algorithm", "private_key", "active"}).
+		AddRow(current.ID, current.Algorithm, currentPEM, true).
+		AddRow(previous.ID, previous.Algorithm, previousPEM, false))
+
+	require.NoError(t, m.Load(context.Background()))
+	assert.Equal(t, current.ID, m.Keys().Current().ID)
+
+	jwks := m.Keys().JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, current.ID, jwks.Keys[0].Kid)
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewManagerRejectsHMAC(t *testing.T) {
+	config := DefaultConfig()
+	config.Algorithm = "HS256"
+	_, err := NewManager(nil, config)
+	assert.Error(t, err)
+}
@@ -43,4 +43,64 @@ func (h *CategoryHandler)  UpdateCategory(ctx context.Context, req *pb.UpdateCat
 func (h *CategoryHandler) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error){
 	h.logger.Log(logger.InfoLevel, "Delete  Category Callled")
 	return h.categoryservice.DeleteCategory(ctx,req)
-}
\ No newline at end of file
+}
+
+func (h *CategoryHandler) GetCategoryBySlug(ctx context.Context, req *pb.GetCategoryBySlugRequest) (*pb.Category, error) {
+	h.logger.Log(logger.InfoLevel, "Get Category By Slug Called")
+	return h.categoryservice.GetCategoryBySlug(ctx, req)
+}
+
+func (h *CategoryHandler) UpsertCategoryTranslation(ctx context.Context, req *pb.CategoryTranslation) (*pb.CategoryTranslation, error) {
+	h.logger.Log(logger.InfoLevel, "Upsert Category Translation Called")
+	return h.categoryservice.UpsertCategoryTranslation(ctx, req)
+}
+
+func (h *CategoryHandler) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.Category, error) {
+	h.logger.Log(logger.InfoLevel, "Get Category Called")
+	return h.categoryservice.GetCategory(ctx, req)
+}
+
+func (h *CategoryHandler) GetCategoryHierarchy(ctx context.Context, req *pb.GetCategoryRequest) (*pb.CategoryHierarchyResponse, error) {
+	h.logger.Log(logger.InfoLevel, "Get Category Hierarchy Called")
+	return h.categoryservice.GetCategoryHierarchy(ctx, req)
+}
+
+func (h *CategoryHandler) MoveCategory(ctx context.Context, req *pb.MoveCategoryRequest) (*pb.Category, error) {
+	h.logger.Log(logger.InfoLevel, "Move Category Called")
+	return h.categoryservice.MoveCategory(ctx, req)
+}
+
+func (h *CategoryHandler) ListCategoryAncestors(ctx context.Context, req *pb.GetCategoryRequest) (*pb.ListCategoriesResponse, error) {
+	h.logger.Log(logger.InfoLevel, "List Category Ancestors Called")
+	return h.categoryservice.ListCategoryAncestors(ctx, req)
+}
+
+func (h *CategoryHandler) ListCategoryDescendants(ctx context.Context, req *pb.GetCategoryRequest) (*pb.ListCategoriesResponse, error) {
+	h.logger.Log(logger.InfoLevel, "List Category Descendants Called")
+	return h.categoryservice.ListCategoryDescendants(ctx, req)
+}
+
+func (h *CategoryHandler) GetCategoryBreadcrumb(ctx context.Context, req *pb.GetCategoryRequest) (*pb.CategoryBreadcrumbResponse, error) {
+	h.logger.Log(logger.InfoLevel, "Get Category Breadcrumb Called")
+	return h.categoryservice.GetCategoryBreadcrumb(ctx, req)
+}
+
+func (h *CategoryHandler) ApplyProductEvents(ctx context.Context, req *pb.ApplyProductEventsRequest) (*pb.ApplyProductEventsResponse, error) {
+	h.logger.Log(logger.InfoLevel, "Apply Product Events Called")
+	return h.categoryservice.ApplyProductEvents(ctx, req)
+}
+
+func (h *CategoryHandler) ReorderCategories(ctx context.Context, req *pb.ReorderCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	h.logger.Log(logger.InfoLevel, "Reorder Categories Called")
+	return h.categoryservice.ReorderCategories(ctx, req)
+}
+
+func (h *CategoryHandler) SetCategoryAttributes(ctx context.Context, req *pb.SetCategoryAttributesRequest) (*pb.CategoryAttributesResponse, error) {
+	h.logger.Log(logger.InfoLevel, "Set Category Attributes Called")
+	return h.categoryservice.SetCategoryAttributes(ctx, req)
+}
+
+func (h *CategoryHandler) GetCategoryAttributes(ctx context.Context, req *pb.GetCategoryRequest) (*pb.CategoryAttributesResponse, error) {
+	h.logger.Log(logger.InfoLevel, "Get Category Attributes Called")
+	return h.categoryservice.GetCategoryAttributes(ctx, req)
+}
@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
 	"github.com/wafi04/common/pkg/logger"
 	"github.com/wafi04/golang-backend/configs/database"
 	"github.com/wafi04/golang-backend/grpc/pb"
@@ -19,18 +21,40 @@ import (
 	"github.com/wafi04/golang-backend/services/category/service"
 	"github.com/wafi04/golang-backend/services/common"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type Config struct {
 	DatabaseURL string
 	Port        string
+	MaxDepth    int32
+	// ProductServiceURL is asked about products before categories are deleted.
+	ProductServiceURL string
+	// RedisAddr caches the category tree; empty disables the cache.
+	RedisAddr     string
+	RedisPassword string
+	TreeCacheTTL  time.Duration
 }
 
 func loadConfig() Config {
-	return Config{
-		DatabaseURL: common.LoadEnv("DATABASE_CATEGORY"),
-		Port:        common.LoadEnv("CATEGORY_PORT"),
+	config := Config{
+		DatabaseURL:       common.LoadEnv("DATABASE_CATEGORY"),
+		Port:              common.LoadEnv("CATEGORY_PORT"),
+		ProductServiceURL: common.LoadEnv("PRODUCT_SERVICE_URL"),
 	}
+
+	// CATEGORY_MAX_DEPTH is optional; unset or 0 leaves the tree unbounded.
+	maxDepth, _ := strconv.Atoi(os.Getenv("CATEGORY_MAX_DEPTH"))
+	config.MaxDepth = int32(maxDepth)
+
+	config.RedisAddr = os.Getenv("REDIS_ADDR")
+	config.RedisPassword = os.Getenv("REDIS_PASSWORD")
+	config.TreeCacheTTL, _ = time.ParseDuration(os.Getenv("CATEGORY_TREE_CACHE_TTL"))
+	if config.TreeCacheTTL <= 0 {
+		config.TreeCacheTTL = 10 * time.Minute
+	}
+
+	return config
 }
 
 func main() {
@@ -48,6 +72,28 @@ func main() {
 	log.Log(logger.InfoLevel, "Database health: %v", health["status"])
 
 	categoryService := service.NewCategoryService(db.DB)
+	categoryService.MaxDepth = config.MaxDepth
+
+	productConn, err := grpc.NewClient(config.ProductServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to create product service client: %v", err)
+		return
+	}
+	defer productConn.Close()
+	categoryService.Products = pb.NewProductServiceClient(productConn)
+
+	if config.RedisAddr != "" {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     config.RedisAddr,
+			Password: config.RedisPassword,
+		})
+		defer redisClient.Close()
+		categoryService.Cache = redisClient
+		categoryService.CacheTTL = config.TreeCacheTTL
+	} else {
+		log.Log(logger.InfoLevel, "REDIS_ADDR not set, category tree cache disabled")
+	}
+
 	categoryHandler := handler.NewCategoryHandler(categoryService)
 
 	grpcServer := grpc.NewServer(
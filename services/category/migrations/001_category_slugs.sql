@@ -0,0 +1,19 @@
+-- Human-readable, unique category slugs plus redirects for renamed categories.
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+
+-- Backfill existing rows; the id suffix keeps them unique.
+UPDATE categories
+SET slug = trim(both '-' from regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g')) || '-' || left(id, 8)
+WHERE slug IS NULL;
+
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS categories_slug_key ON categories (slug);
+
+CREATE TABLE IF NOT EXISTS category_slug_history (
+    slug         VARCHAR(255) PRIMARY KEY,
+    category_id  VARCHAR(255) NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS category_slug_history_category_id_idx ON category_slug_history (category_id);
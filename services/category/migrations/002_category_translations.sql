@@ -0,0 +1,10 @@
+-- Per-locale category content. categories.name and categories.description
+-- hold the default locale (DEFAULT_LOCALE, "id" unless configured).
+CREATE TABLE IF NOT EXISTS category_translations (
+    category_id  VARCHAR(255) NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    locale       VARCHAR(35) NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (category_id, locale)
+);
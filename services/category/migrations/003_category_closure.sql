@@ -0,0 +1,30 @@
+-- Closure table: one row per (ancestor, descendant) pair, including each
+-- category paired with itself at distance 0.
+CREATE TABLE IF NOT EXISTS category_closure (
+    ancestor_id    VARCHAR(255) NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    descendant_id  VARCHAR(255) NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    distance       INT NOT NULL CHECK (distance >= 0),
+    PRIMARY KEY (ancestor_id, descendant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_closure_descendant
+    ON category_closure (descendant_id, distance);
+
+-- Backfill from parent_id.
+WITH RECURSIVE paths AS (
+    SELECT id AS ancestor_id, id AS descendant_id, 0 AS distance
+    FROM categories
+    UNION ALL
+    SELECT p.ancestor_id, c.id, p.distance + 1
+    FROM categories c
+    INNER JOIN paths p ON c.parent_id = p.descendant_id
+)
+INSERT INTO category_closure (ancestor_id, descendant_id, distance)
+SELECT ancestor_id, descendant_id, distance FROM paths
+ON CONFLICT DO NOTHING;
+
+-- depth may have drifted from parent_id before the closure existed; derive
+-- it from the closure so both agree.
+UPDATE categories c SET depth = (
+    SELECT MAX(distance) FROM category_closure WHERE descendant_id = c.id
+);
@@ -0,0 +1,10 @@
+-- Number of products directly in each category, kept up to date from the
+-- product service's product events. Totals including descendants are summed
+-- over category_closure at read time.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS product_count INT NOT NULL DEFAULT 0;
+
+-- Event ids already applied, so redelivered events are not counted twice.
+CREATE TABLE IF NOT EXISTS processed_product_events (
+    event_id      VARCHAR(255) PRIMARY KEY,
+    processed_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
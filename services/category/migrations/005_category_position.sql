@@ -0,0 +1,13 @@
+-- Menu order among siblings.
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+-- Keep the previous creation order for existing categories.
+UPDATE categories c
+SET position = ordered.position
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY created_at, id) - 1 AS position
+    FROM categories
+) ordered
+WHERE c.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_position ON categories (parent_id, position);
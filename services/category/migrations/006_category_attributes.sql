@@ -0,0 +1,10 @@
+-- Variant attribute schema per category, inherited by descendants.
+CREATE TABLE IF NOT EXISTS category_attributes (
+    category_id     VARCHAR(255) NOT NULL REFERENCES categories (id) ON DELETE CASCADE,
+    name            VARCHAR(100) NOT NULL,
+    type            VARCHAR(20) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    allowed_values  JSONB NOT NULL DEFAULT '[]',
+    required        BOOLEAN NOT NULL DEFAULT false,
+    position        INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (category_id, name)
+);
@@ -0,0 +1,18 @@
+-- Bucket for products whose category was deleted with orphan_products
+-- "uncategorized". The id is fixed; the category service refuses to delete it.
+INSERT INTO categories (id, name, description, parent_id, depth, slug, position, created_at)
+VALUES (
+    'uncategorized',
+    'Uncategorized',
+    'Products whose category was deleted.',
+    NULL,
+    0,
+    'uncategorized',
+    (SELECT COALESCE(MAX(position) + 1, 0) FROM categories WHERE parent_id IS NULL),
+    CURRENT_TIMESTAMP
+)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO category_closure (ancestor_id, descendant_id, distance)
+VALUES ('uncategorized', 'uncategorized', 0)
+ON CONFLICT DO NOTHING;
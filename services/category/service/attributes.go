@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+var attributeTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"boolean": true,
+	"enum":    true,
+}
+
+func validateAttributeSchema(attributes []*pb.CategoryAttribute) error {
+	seen := make(map[string]bool, len(attributes))
+	for _, attr := range attributes {
+		if attr.Name == "" {
+			return fmt.Errorf("invalid attribute: name is required")
+		}
+		if seen[attr.Name] {
+			return fmt.Errorf("invalid attribute %s: defined twice", attr.Name)
+		}
+		seen[attr.Name] = true
+
+		if !attributeTypes[attr.Type] {
+			return fmt.Errorf("invalid attribute %s: unknown type %q", attr.Name, attr.Type)
+		}
+		if attr.Type == "enum" && len(attr.AllowedValues) == 0 {
+			return fmt.Errorf("invalid attribute %s: enum needs allowed values", attr.Name)
+		}
+	}
+	return nil
+}
+
+// SetCategoryAttributes replaces the attributes defined directly on a
+// category. Descendants inherit them unless they define the same name.
+func (s *CategoryService) SetCategoryAttributes(ctx context.Context, req *pb.SetCategoryAttributesRequest) (*pb.CategoryAttributesResponse, error) {
+	if err := validateAttributeSchema(req.Attributes); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", req.CategoryId).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check category existence: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM category_attributes WHERE category_id = $1", req.CategoryId); err != nil {
+		return nil, fmt.Errorf("failed to clear category attributes: %v", err)
+	}
+
+	attributes := make([]*pb.CategoryAttribute, 0, len(req.Attributes))
+	for position, attr := range req.Attributes {
+		allowed := attr.AllowedValues
+		if allowed == nil {
+			allowed = []string{}
+		}
+		allowedJSON, err := json.Marshal(allowed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode allowed values: %v", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+            INSERT INTO category_attributes (category_id, name, type, allowed_values, required, position)
+            VALUES ($1, $2, $3, $4, $5, $6)`,
+			req.CategoryId, attr.Name, attr.Type, allowedJSON, attr.Required, position)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert category attribute: %v", err)
+		}
+
+		attributes = append(attributes, &pb.CategoryAttribute{
+			Name:          attr.Name,
+			Type:          attr.Type,
+			AllowedValues: allowed,
+			Required:      attr.Required,
+			CategoryId:    req.CategoryId,
+		})
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &pb.CategoryAttributesResponse{
+		Attributes: attributes,
+	}, nil
+}
+
+// GetCategoryAttributes returns the effective schema of a category: its own
+// attributes plus those of its ancestors, the nearest definition of a name
+// winning. Attributes are listed root first, in definition order.
+func (s *CategoryService) GetCategoryAttributes(ctx context.Context, req *pb.GetCategoryRequest) (*pb.CategoryAttributesResponse, error) {
+	if err := s.categoryExists(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT category_id, name, type, allowed_values, required
+        FROM (
+            SELECT DISTINCT ON (a.name)
+                a.category_id, a.name, a.type, a.allowed_values, a.required, a.position, cc.distance
+            FROM category_closure cc
+            JOIN category_attributes a ON a.category_id = cc.ancestor_id
+            WHERE cc.descendant_id = $1
+            ORDER BY a.name, cc.distance
+        ) effective
+        ORDER BY distance DESC, position`,
+		req.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category attributes: %v", err)
+	}
+	defer rows.Close()
+
+	attributes := make([]*pb.CategoryAttribute, 0)
+	for rows.Next() {
+		attr := &pb.CategoryAttribute{}
+		var allowedJSON []byte
+		if err := rows.Scan(&attr.CategoryId, &attr.Name, &attr.Type, &allowedJSON, &attr.Required); err != nil {
+			return nil, fmt.Errorf("failed to scan category attribute: %v", err)
+		}
+		if err := json.Unmarshal(allowedJSON, &attr.AllowedValues); err != nil {
+			return nil, fmt.Errorf("failed to decode allowed values: %v", err)
+		}
+		attributes = append(attributes, attr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating category attributes: %v", err)
+	}
+
+	return &pb.CategoryAttributesResponse{
+		Attributes: attributes,
+	}, nil
+}
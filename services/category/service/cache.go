@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// The serialized category tree is cached in Redis, one hash per tree
+// version with a field per (locale, parent) combination. Every change to the
+// tree bumps the version instead of deleting keys, so a reader that built a
+// tree from data read before the change can only store it under the old,
+// no longer read version. Old versions expire after CacheTTL.
+//
+// The cache is best effort: when Redis is unavailable, trees are built from
+// the database.
+
+const (
+	treeCacheVersionKey = "categories:tree:version"
+	treeCachePrefix     = "categories:tree:"
+)
+
+func treeCacheField(req *pb.ListCategoriesRequest) string {
+	parentID := ""
+	if req.ParentId != nil {
+		parentID = *req.ParentId
+	}
+	return req.Locale + "|" + parentID
+}
+
+func (s *CategoryService) treeCacheKey(ctx context.Context) (string, error) {
+	version, err := s.Cache.Get(ctx, treeCacheVersionKey).Result()
+	if err == redis.Nil {
+		version = "0"
+	} else if err != nil {
+		return "", err
+	}
+	return treeCachePrefix + version, nil
+}
+
+// cachedTree returns the cached root categories for req and the key they
+// were looked up under, to store a freshly built tree with storeTree.
+func (s *CategoryService) cachedTree(ctx context.Context, req *pb.ListCategoriesRequest) ([]*pb.Category, string, bool) {
+	if s.Cache == nil {
+		return nil, "", false
+	}
+
+	key, err := s.treeCacheKey(ctx)
+	if err != nil {
+		return nil, "", false
+	}
+
+	data, err := s.Cache.HGet(ctx, key, treeCacheField(req)).Bytes()
+	if err != nil {
+		return nil, key, false
+	}
+
+	var tree pb.ListCategoriesResponse
+	if err := proto.Unmarshal(data, &tree); err != nil {
+		return nil, key, false
+	}
+	return tree.Categories, key, true
+}
+
+func (s *CategoryService) storeTree(ctx context.Context, key string, req *pb.ListCategoriesRequest, roots []*pb.Category) {
+	if s.Cache == nil || key == "" {
+		return
+	}
+
+	data, err := proto.Marshal(&pb.ListCategoriesResponse{Categories: roots})
+	if err != nil {
+		return
+	}
+
+	pipe := s.Cache.TxPipeline()
+	pipe.HSet(ctx, key, treeCacheField(req), data)
+	pipe.Expire(ctx, key, s.CacheTTL)
+	pipe.Exec(ctx)
+}
+
+// invalidateTree drops every cached tree. Call it after committing a change
+// to categories, their order, translations or product counts. If Redis is
+// down the stale trees live until they expire.
+func (s *CategoryService) invalidateTree(ctx context.Context) {
+	if s.Cache == nil {
+		return
+	}
+	s.Cache.Incr(ctx, treeCacheVersionKey)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// category_closure holds one row for every (ancestor, descendant) pair in the
+// tree, including the (id, id) row at distance 0. It is kept in step with
+// parent_id on create and move; deletes cascade from categories.
+
+// categoryColumns selects a category from "categories c". The total product
+// count sums the direct counts over the closure of c.
+const categoryColumns = `c.id, c.name, c.description, c.image, c.depth, c.parent_id, c.slug, c.created_at,
+        c.position, c.product_count,
+        (SELECT COALESCE(SUM(d.product_count), 0)
+            FROM category_closure dc
+            JOIN categories d ON d.id = dc.descendant_id
+            WHERE dc.ancestor_id = c.id) AS total_product_count`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCategory reads a row selected with categoryColumns, followed by extra.
+func scanCategory(row scanner, extra ...interface{}) (*pb.Category, error) {
+	var category pb.Category
+	var parentID, image sql.NullString
+	var createdAt sql.NullTime
+
+	dest := []interface{}{
+		&category.Id,
+		&category.Name,
+		&category.Description,
+		&image,
+		&category.Depth,
+		&parentID,
+		&category.Slug,
+		&createdAt,
+		&category.Position,
+		&category.ProductCount,
+		&category.TotalProductCount,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if image.Valid {
+		category.Image = &image.String
+	}
+	if parentID.Valid {
+		category.ParentId = &parentID.String
+	}
+	if createdAt.Valid {
+		category.CreatedAt = timestamppb.New(createdAt.Time)
+	}
+
+	return &category, nil
+}
+
+func insertClosure(ctx context.Context, tx *sql.Tx, id string, parentID *string) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO category_closure (ancestor_id, descendant_id, distance)
+        SELECT $1::varchar, $1::varchar, 0
+        UNION ALL
+        SELECT ancestor_id, $1::varchar, distance + 1
+        FROM category_closure
+        WHERE descendant_id = $2`,
+		id, parentID)
+	if err != nil {
+		return fmt.Errorf("failed to insert category path: %v", err)
+	}
+	return nil
+}
+
+// moveClosure detaches the subtree of id from its old ancestors and attaches
+// it below parentID (nil for the root).
+func moveClosure(ctx context.Context, tx *sql.Tx, id string, parentID interface{}) error {
+	_, err := tx.ExecContext(ctx, `
+        DELETE FROM category_closure
+        WHERE descendant_id IN (SELECT descendant_id FROM category_closure WHERE ancestor_id = $1)
+        AND ancestor_id IN (SELECT ancestor_id FROM category_closure WHERE descendant_id = $1 AND ancestor_id <> $1)`,
+		id)
+	if err != nil {
+		return fmt.Errorf("failed to detach category paths: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO category_closure (ancestor_id, descendant_id, distance)
+        SELECT super.ancestor_id, sub.descendant_id, super.distance + sub.distance + 1
+        FROM category_closure super
+        CROSS JOIN category_closure sub
+        WHERE super.descendant_id = $2 AND sub.ancestor_id = $1`,
+		id, parentID)
+	if err != nil {
+		return fmt.Errorf("failed to attach category paths: %v", err)
+	}
+
+	return nil
+}
+
+// getAncestors returns the ancestors of a category from the root down,
+// optionally ending with the category itself.
+func (s *CategoryService) getAncestors(ctx context.Context, id string, includeSelf bool) ([]*pb.Category, error) {
+	minDistance := 1
+	if includeSelf {
+		minDistance = 0
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT `+categoryColumns+`
+        FROM category_closure cc
+        JOIN categories c ON c.id = cc.ancestor_id
+        WHERE cc.descendant_id = $1 AND cc.distance >= $2
+        ORDER BY cc.distance DESC`,
+		id, minDistance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ancestors: %v", err)
+	}
+	defer rows.Close()
+
+	categories := make([]*pb.Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+func (s *CategoryService) categoryExists(ctx context.Context, id string) error {
+	var exists bool
+	err := s.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check category existence: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("category not found")
+	}
+	return nil
+}
+
+// ListCategoryAncestors returns the ancestors of a category, root first.
+func (s *CategoryService) ListCategoryAncestors(ctx context.Context, req *pb.GetCategoryRequest) (*pb.ListCategoriesResponse, error) {
+	if err := s.categoryExists(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	ancestors, err := s.getAncestors(ctx, req.Id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.localizeCategories(ctx, req.Locale, ancestors...); err != nil {
+		return nil, err
+	}
+
+	return &pb.ListCategoriesResponse{
+		Categories: ancestors,
+		Total:      int32(len(ancestors)),
+	}, nil
+}
+
+// ListCategoryDescendants returns every category below the requested one as
+// a flat list, nearest first. Product search by category uses it to expand a
+// category into its whole subtree.
+func (s *CategoryService) ListCategoryDescendants(ctx context.Context, req *pb.GetCategoryRequest) (*pb.ListCategoriesResponse, error) {
+	if err := s.categoryExists(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT `+categoryColumns+`
+        FROM category_closure cc
+        JOIN categories c ON c.id = cc.descendant_id
+        WHERE cc.ancestor_id = $1 AND cc.distance > 0
+        ORDER BY cc.distance, c.position, c.id`,
+		req.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query descendants: %v", err)
+	}
+	defer rows.Close()
+
+	descendants := make([]*pb.Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		descendants = append(descendants, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %v", err)
+	}
+	rows.Close()
+
+	if err := s.localizeCategories(ctx, req.Locale, descendants...); err != nil {
+		return nil, err
+	}
+
+	return &pb.ListCategoriesResponse{
+		Categories: descendants,
+		Total:      int32(len(descendants)),
+	}, nil
+}
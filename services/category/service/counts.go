@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// GetCategoryBreadcrumb returns the path from the root down to the category.
+func (s *CategoryService) GetCategoryBreadcrumb(ctx context.Context, req *pb.GetCategoryRequest) (*pb.CategoryBreadcrumbResponse, error) {
+	items, err := s.getAncestors(ctx, req.Id, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	if err := s.localizeCategories(ctx, req.Locale, items...); err != nil {
+		return nil, err
+	}
+
+	return &pb.CategoryBreadcrumbResponse{
+		Items: items,
+	}, nil
+}
+
+// ApplyProductEvents updates categories.product_count from the product
+// service's product events. Each event is applied at most once, so the
+// product service may safely redeliver a batch.
+func (s *CategoryService) ApplyProductEvents(ctx context.Context, req *pb.ApplyProductEventsRequest) (*pb.ApplyProductEventsResponse, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var applied int32
+	for _, event := range req.Events {
+		if event.EventId == "" {
+			return nil, fmt.Errorf("invalid event: event id is required")
+		}
+
+		result, err := tx.ExecContext(ctx, `
+            INSERT INTO processed_product_events (event_id, processed_at)
+            VALUES ($1, CURRENT_TIMESTAMP)
+            ON CONFLICT (event_id) DO NOTHING`,
+			event.EventId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record product event: %v", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			continue
+		}
+
+		if event.OldCategoryId == event.NewCategoryId {
+			applied++
+			continue
+		}
+
+		if event.OldCategoryId != "" {
+			_, err := tx.ExecContext(ctx, `
+                UPDATE categories SET product_count = GREATEST(product_count - 1, 0)
+                WHERE id = $1`,
+				event.OldCategoryId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update product count: %v", err)
+			}
+		}
+
+		if event.NewCategoryId != "" {
+			_, err := tx.ExecContext(ctx, `
+                UPDATE categories SET product_count = product_count + 1
+                WHERE id = $1`,
+				event.NewCategoryId)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update product count: %v", err)
+			}
+		}
+
+		applied++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	if applied > 0 {
+		s.invalidateTree(ctx)
+	}
+
+	return &pb.ApplyProductEventsResponse{
+		Applied: applied,
+	}, nil
+}
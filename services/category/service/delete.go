@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// uncategorizedID is the bucket for products whose category was deleted with
+// orphan_products "uncategorized". It is created by the migrations and
+// cannot be deleted.
+const uncategorizedID = "uncategorized"
+
+// DeleteCategory deletes a category, or with delete_children its whole
+// subtree. Products in the deleted categories are handled as requested by
+// orphan_products before anything is deleted. The product service is called
+// before the tree lock is taken, so products added to the categories in the
+// meantime are moved once the delete has committed. The move is not undone
+// when the delete fails afterwards, e.g. with "category tree changed while
+// deleting, retry": the products stay in the target category.
+func (s *CategoryService) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
+	if req.Id == uncategorizedID {
+		return nil, fmt.Errorf("invalid request: the uncategorized category cannot be deleted")
+	}
+
+	mode := req.OrphanProducts
+	if mode == "" {
+		mode = "block"
+	}
+	switch mode {
+	case "block", "parent", "category", "uncategorized":
+	default:
+		return nil, fmt.Errorf("invalid orphan_products %q: must be block, parent, category or uncategorized", req.OrphanProducts)
+	}
+	if s.Products == nil {
+		return nil, fmt.Errorf("failed to check products: product service not configured")
+	}
+
+	parentID, ids, err := deletedIDs(ctx, s.DB, req)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.Products.CountProductsByCategory(ctx, &pb.CountProductsByCategoryRequest{CategoryIds: ids})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count products: %v", err)
+	}
+
+	var target string
+	var affected int64
+	if count.Count > 0 {
+		target, err = orphanTarget(ctx, s.DB, mode, parentID, req.TargetCategoryId, ids)
+		if err != nil {
+			return nil, fmt.Errorf("cannot delete category with %d products: %v", count.Count, err)
+		}
+
+		// Products are moved before the categories are deleted; if the delete
+		// then fails they sit in a category that still exists.
+		res, err := s.Products.ReassignProducts(ctx, &pb.ReassignProductsRequest{
+			FromCategoryIds: ids,
+			ToCategoryId:    target,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to reassign products: %v", err)
+		}
+		affected = res.Updated
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", treeLockKey); err != nil {
+		return nil, fmt.Errorf("failed to lock category tree: %v", err)
+	}
+
+	// The tree may have changed while the products were moved.
+	_, locked, err := deletedIDs(ctx, tx, req)
+	if err != nil {
+		return nil, err
+	}
+	if !sameIDs(ids, locked) {
+		return nil, fmt.Errorf("category tree changed while deleting, retry")
+	}
+	if target != "" {
+		if _, err := orphanTarget(ctx, tx, "category", parentID, &target, ids); err != nil {
+			return nil, fmt.Errorf("failed to keep reassigned products: %v", err)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM categories WHERE id = ANY($1)", ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete category: %v", err)
+	}
+	deletedCount, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	s.invalidateTree(ctx)
+
+	affected += s.moveStragglers(ctx, ids, target)
+
+	return &pb.DeleteCategoryResponse{
+		Success:          true,
+		DeletedCount:     deletedCount,
+		AffectedProducts: affected,
+	}, nil
+}
+
+// querier is satisfied by both the database and a transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// deletedIDs checks that the requested category can be deleted and returns
+// its parent and the ids of the categories the delete removes.
+func deletedIDs(ctx context.Context, db querier, req *pb.DeleteCategoryRequest) (sql.NullString, []string, error) {
+	var parentID sql.NullString
+	err := db.QueryRowContext(ctx, "SELECT parent_id FROM categories WHERE id = $1", req.Id).Scan(&parentID)
+	if err == sql.ErrNoRows {
+		return parentID, nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return parentID, nil, fmt.Errorf("failed to check category existence: %v", err)
+	}
+
+	if !req.DeleteChildren {
+		var hasChildren bool
+		err = db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = $1)", req.Id).Scan(&hasChildren)
+		if err != nil {
+			return parentID, nil, fmt.Errorf("failed to check for children: %v", err)
+		}
+		if hasChildren {
+			return parentID, nil, fmt.Errorf("cannot delete category with children, set DeleteChildren to true to delete all")
+		}
+	}
+
+	ids, err := subtreeIDs(ctx, db, req.Id)
+	if err != nil {
+		return parentID, nil, err
+	}
+	if contains(ids, uncategorizedID) {
+		return parentID, nil, fmt.Errorf("invalid request: the uncategorized category cannot be deleted")
+	}
+	return parentID, ids, nil
+}
+
+// moveStragglers moves products that were put in the deleted categories
+// after they were counted to target. With no target (orphan_products
+// "block") they are left where they are and reported. The delete has
+// already committed, so failures are only logged.
+func (s *CategoryService) moveStragglers(ctx context.Context, ids []string, target string) int64 {
+	count, err := s.Products.CountProductsByCategory(ctx, &pb.CountProductsByCategoryRequest{CategoryIds: ids})
+	if err != nil {
+		log.Printf("Failed to recount products of deleted categories %v: %v", ids, err)
+		return 0
+	}
+	if count.Count == 0 {
+		return 0
+	}
+	if target == "" {
+		log.Printf("Deleted categories %v still have %d products added during the delete; they were not moved", ids, count.Count)
+		return 0
+	}
+	res, err := s.Products.ReassignProducts(ctx, &pb.ReassignProductsRequest{
+		FromCategoryIds: ids,
+		ToCategoryId:    target,
+	})
+	if err != nil {
+		log.Printf("Failed to move %d products out of deleted categories %v: %v", count.Count, ids, err)
+		return 0
+	}
+	return res.Updated
+}
+
+// subtreeIDs returns the id of a category and of all its descendants.
+func subtreeIDs(ctx context.Context, db querier, id string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT descendant_id FROM category_closure WHERE ancestor_id = $1 ORDER BY descendant_id", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get descendants: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var descendantID string
+		if err := rows.Scan(&descendantID); err != nil {
+			return nil, fmt.Errorf("failed to scan descendant: %v", err)
+		}
+		ids = append(ids, descendantID)
+	}
+	return ids, rows.Err()
+}
+
+// orphanTarget resolves the category that products of the deleted categories
+// are moved to.
+func orphanTarget(ctx context.Context, db querier, mode string, parentID sql.NullString, targetID *string, deleted []string) (string, error) {
+	var target string
+	switch mode {
+	case "parent":
+		if !parentID.Valid {
+			return "", fmt.Errorf("invalid orphan_products: a root category has no parent")
+		}
+		target = parentID.String
+	case "category":
+		if targetID == nil || *targetID == "" {
+			return "", fmt.Errorf("invalid orphan_products: target_category_id is required")
+		}
+		target = *targetID
+		if contains(deleted, target) {
+			return "", fmt.Errorf("invalid target category: it is being deleted")
+		}
+	case "uncategorized":
+		target = uncategorizedID
+	default:
+		return "", fmt.Errorf("choose how to handle them with orphan_products")
+	}
+
+	var exists bool
+	err := db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", target).Scan(&exists)
+	if err != nil {
+		return "", fmt.Errorf("failed to check target category: %v", err)
+	}
+	if !exists {
+		return "", fmt.Errorf("invalid target category: %s does not exist", target)
+	}
+	return target, nil
+}
+
+// sameIDs reports whether a and b hold the same ids in the same order.
+func sameIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// GetCategory returns a single category with its direct children.
+func (s *CategoryService) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.Category, error) {
+	category, err := s.getCategoryByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+        SELECT ` + categoryColumns + `
+        FROM categories c
+        WHERE c.parent_id = $1
+        ORDER BY c.position, c.id`
+
+	rows, err := s.DB.QueryContext(ctx, query, req.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query children: %v", err)
+	}
+	defer rows.Close()
+
+	category.Children = make([]*pb.Category, 0)
+	for rows.Next() {
+		child, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		category.Children = append(category.Children, child)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %v", err)
+	}
+	rows.Close()
+
+	if err := s.localizeCategories(ctx, req.Locale, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// GetCategoryHierarchy returns the subtree rooted at the requested category.
+// max_depth counts the levels below the root, so a leaf has max_depth 0.
+func (s *CategoryService) GetCategoryHierarchy(ctx context.Context, req *pb.GetCategoryRequest) (*pb.CategoryHierarchyResponse, error) {
+	query := `
+        SELECT ` + categoryColumns + `, cc.distance
+        FROM category_closure cc
+        JOIN categories c ON c.id = cc.descendant_id
+        WHERE cc.ancestor_id = $1
+        ORDER BY cc.distance, c.position, c.id`
+
+	rows, err := s.DB.QueryContext(ctx, query, req.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query category tree: %v", err)
+	}
+	defer rows.Close()
+
+	categoryMap := make(map[string]*pb.Category)
+	var root *pb.Category
+	var maxLevel int32
+
+	for rows.Next() {
+		var level int32
+		cat, err := scanCategory(rows, &level)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+
+		categoryMap[cat.Id] = cat
+		if level > maxLevel {
+			maxLevel = level
+		}
+
+		// Rows come ordered by distance, so a parent is always seen before
+		// its children.
+		if level == 0 {
+			root = cat
+		} else if parent := categoryMap[*cat.ParentId]; parent != nil {
+			parent.Children = append(parent.Children, cat)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %v", err)
+	}
+	rows.Close()
+
+	if root == nil {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	if err := s.localizeCategories(ctx, req.Locale, root); err != nil {
+		return nil, err
+	}
+
+	return &pb.CategoryHierarchyResponse{
+		RootCategory:     root,
+		TotalDescendants: int32(len(categoryMap) - 1),
+		MaxDepth:         maxLevel,
+	}, nil
+}
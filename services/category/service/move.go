@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// treeLockKey serializes structural changes to the category tree, so two
+// concurrent moves cannot together create a cycle.
+const treeLockKey = "categories_tree"
+
+// MoveCategory re-parents a category. newParentID nil or empty makes it a
+// root category.
+func (s *CategoryService) MoveCategory(ctx context.Context, req *pb.MoveCategoryRequest) (*pb.Category, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.moveCategory(ctx, tx, req.Id, req.NewParentId); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	s.invalidateTree(ctx)
+
+	return s.getCategoryByID(ctx, req.Id)
+}
+
+// moveCategory sets parent_id of the category and shifts depth of its whole
+// subtree. The new parent must exist and must not be inside the subtree.
+// Callers that lock rows before calling it must take the tree lock first;
+// the advisory lock is reentrant, so taking it again here is harmless.
+func (s *CategoryService) moveCategory(ctx context.Context, tx *sql.Tx, id string, newParentID *string) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", treeLockKey); err != nil {
+		return fmt.Errorf("failed to lock category tree: %v", err)
+	}
+
+	var currentDepth int32
+	err := tx.QueryRowContext(ctx, "SELECT depth FROM categories WHERE id = $1 FOR UPDATE", id).Scan(&currentDepth)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get category: %v", err)
+	}
+
+	var parent interface{}
+	var newDepth int32
+	if newParentID != nil && *newParentID != "" {
+		if *newParentID == id {
+			return fmt.Errorf("invalid move: category cannot be its own parent")
+		}
+
+		var parentDepth int32
+		err := tx.QueryRowContext(ctx, "SELECT depth FROM categories WHERE id = $1", *newParentID).Scan(&parentDepth)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("parent category not found")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get parent category: %v", err)
+		}
+
+		var isDescendant bool
+		err = tx.QueryRowContext(ctx, `
+            SELECT EXISTS(
+                SELECT 1 FROM category_closure
+                WHERE ancestor_id = $1 AND descendant_id = $2
+            )`,
+			id, *newParentID).Scan(&isDescendant)
+		if err != nil {
+			return fmt.Errorf("failed to check category tree: %v", err)
+		}
+		if isDescendant {
+			return fmt.Errorf("invalid move: new parent is a descendant of the category")
+		}
+
+		parent = *newParentID
+		newDepth = parentDepth + 1
+	}
+
+	delta := newDepth - currentDepth
+
+	if s.MaxDepth > 0 && delta > 0 {
+		var deepest int32
+		err := tx.QueryRowContext(ctx, `
+            SELECT MAX(c.depth)
+            FROM category_closure cc
+            JOIN categories c ON c.id = cc.descendant_id
+            WHERE cc.ancestor_id = $1`,
+			id).Scan(&deepest)
+		if err != nil {
+			return fmt.Errorf("failed to check category tree: %v", err)
+		}
+		if deepest+delta > s.MaxDepth {
+			return fmt.Errorf("invalid move: tree would exceed the maximum depth of %d", s.MaxDepth)
+		}
+	}
+
+	// The moved category goes last among its new siblings.
+	_, err = tx.ExecContext(ctx, `
+        UPDATE categories SET parent_id = $1, position = (
+            SELECT COALESCE(MAX(position) + 1, 0) FROM categories
+            WHERE parent_id IS NOT DISTINCT FROM $1 AND id <> $2
+        )
+        WHERE id = $2`,
+		parent, id)
+	if err != nil {
+		return fmt.Errorf("failed to move category: %v", err)
+	}
+
+	if err := moveClosure(ctx, tx, id, parent); err != nil {
+		return err
+	}
+
+	if delta != 0 {
+		_, err := tx.ExecContext(ctx, `
+            UPDATE categories SET depth = depth + $2
+            WHERE id IN (SELECT descendant_id FROM category_closure WHERE ancestor_id = $1)`,
+			id, delta)
+		if err != nil {
+			return fmt.Errorf("failed to update depth: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// ReorderCategories sets the order of the children of a parent (the roots
+// when parent_id is unset). The request must list every child exactly once.
+func (s *CategoryService) ReorderCategories(ctx context.Context, req *pb.ReorderCategoriesRequest) (*pb.ListCategoriesResponse, error) {
+	var parent interface{}
+	if req.ParentId != nil && *req.ParentId != "" {
+		parent = *req.ParentId
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", treeLockKey); err != nil {
+		return nil, fmt.Errorf("failed to lock category tree: %v", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM categories WHERE parent_id IS NOT DISTINCT FROM $1", parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %v", err)
+	}
+	remaining := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		remaining[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %v", err)
+	}
+
+	if len(remaining) != len(req.CategoryIds) {
+		return nil, fmt.Errorf("invalid order: expected %d categories, got %d", len(remaining), len(req.CategoryIds))
+	}
+	for _, id := range req.CategoryIds {
+		if !remaining[id] {
+			return nil, fmt.Errorf("invalid order: category %s is not a child of the parent or is listed twice", id)
+		}
+		delete(remaining, id)
+	}
+
+	for position, id := range req.CategoryIds {
+		if _, err := tx.ExecContext(ctx, "UPDATE categories SET position = $1 WHERE id = $2", position, id); err != nil {
+			return nil, fmt.Errorf("failed to reorder categories: %v", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	s.invalidateTree(ctx)
+
+	query := `
+        SELECT ` + categoryColumns + `
+        FROM categories c
+        WHERE c.parent_id IS NOT DISTINCT FROM $1
+        ORDER BY c.position, c.id`
+
+	rows, err = s.DB.QueryContext(ctx, query, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %v", err)
+	}
+	defer rows.Close()
+
+	categories := make([]*pb.Category, 0, len(req.CategoryIds))
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan category: %v", err)
+		}
+		categories = append(categories, category)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating categories: %v", err)
+	}
+
+	return &pb.ListCategoriesResponse{
+		Categories: categories,
+		Total:      int32(len(categories)),
+	}, nil
+}
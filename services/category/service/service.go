@@ -5,15 +5,24 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type CategoryService struct {
     DB     *sqlx.DB
+    // MaxDepth is the deepest allowed depth (roots are 0); 0 means no limit.
+    MaxDepth int32
+    // Products is asked about the products of categories being deleted.
+    Products pb.ProductServiceClient
+    // Cache holds serialized category trees for CacheTTL; nil disables it.
+    Cache    *redis.Client
+    CacheTTL time.Duration
 }
 
 func NewCategoryService(db *sqlx.DB) *CategoryService {
@@ -29,6 +38,12 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CreateCate
     }
     defer tx.Rollback()
 
+    // Positions are assigned as MAX(position)+1 among siblings; the tree lock
+    // keeps concurrent creates, moves and reorders from picking the same one.
+    if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", treeLockKey); err != nil {
+        return nil, fmt.Errorf("failed to lock category tree: %v", err)
+    }
+
     // Generate unique ID
     categoryID := uuid.New().String()
     
@@ -46,6 +61,15 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CreateCate
         depth = parentDepth + 1
     }
 
+    if s.MaxDepth > 0 && depth > s.MaxDepth {
+        return nil, fmt.Errorf("invalid parent: category would exceed the maximum depth of %d", s.MaxDepth)
+    }
+
+    slug, err := uniqueCategorySlug(ctx, tx, req.Name, categoryID)
+    if err != nil {
+        return nil, err
+    }
+
     query := `
         INSERT INTO categories (
             id,
@@ -54,11 +78,15 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CreateCate
             image,
             parent_id,
             depth,
+            slug,
+            position,
             created_at
         ) VALUES (
-            $1, $2, $3, $4, $5, $6,  CURRENT_TIMESTAMP
+            $1, $2, $3, $4, $5, $6, $7,
+            (SELECT COALESCE(MAX(position) + 1, 0) FROM categories WHERE parent_id IS NOT DISTINCT FROM $5),
+            CURRENT_TIMESTAMP
         )
-        RETURNING id, name, description, image, parent_id, depth, created_at`
+        RETURNING id, name, description, image, parent_id, depth, slug, position, created_at`
 
     var category pb.Category
     var createdAt sql.NullTime
@@ -71,13 +99,16 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CreateCate
         req.Image,
         req.ParentId,
         depth,
+        slug,
     ).Scan(
         &category.Id,
         &category.Name,
         &category.Description,
         &image,
         &parentID,
-        &depth,
+        &category.Depth,
+        &category.Slug,
+        &category.Position,
         &createdAt,
     )
 
@@ -85,9 +116,14 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CreateCate
         return nil, fmt.Errorf("failed to insert category: %v", err)
     }
 
+    if err := insertClosure(ctx, tx, categoryID, req.ParentId); err != nil {
+        return nil, err
+    }
+
     if err = tx.Commit(); err != nil {
         return nil, fmt.Errorf("failed to commit transaction: %v", err)
     }
+    s.invalidateTree(ctx)
 
     if parentID.Valid {
         category.ParentId = &parentID.String
@@ -106,29 +142,9 @@ func (s *CategoryService) CreateCategory(ctx context.Context, req *pb.CreateCate
 func (s *CategoryService) GetCategories(ctx context.Context, req *pb.ListCategoriesRequest) (*pb.ListCategoriesResponse, error) {
 
     query := `
-        WITH RECURSIVE category_tree AS (
-            SELECT 
-                c.id, c.name, c.description, c.image,  
-                c.parent_id, c.depth, c.created_at,
-                ARRAY[]::VARCHAR[] AS path,
-                0 as level
-            FROM categories c
-            WHERE c.parent_id IS NULL
-            UNION ALL
-            SELECT 
-                c.id, c.name, c.description, c.image,
-                c.parent_id, c.depth, c.created_at,
-                path || c.parent_id,
-                ct.level + 1
-            FROM categories c
-            INNER JOIN category_tree ct ON ct.id = c.parent_id
-        )
-        SELECT 
-            id, name, description, image, 
-            parent_id, depth, created_at,
-            path
-        FROM category_tree
-        ORDER BY path, level`
+        SELECT ` + categoryColumns + `
+        FROM categories c
+        ORDER BY c.depth, c.position, c.id`
 
     rows, err := s.DB.QueryContext(ctx, query)
     if err != nil {
@@ -137,53 +153,36 @@ func (s *CategoryService) GetCategories(ctx context.Context, req *pb.ListCategor
     defer rows.Close()
 
     categoryMap := make(map[string]*pb.Category)
+    var ordered []*pb.Category
     var rootCategories []*pb.Category
 
     for rows.Next() {
-        var cat pb.Category
-        var createdAt sql.NullTime
-        var parentID, image sql.NullString
-        var path []string
-
-        err := rows.Scan(
-            &cat.Id,
-            &cat.Name,
-            &cat.Description,
-            &image,
-            &parentID,
-            &cat.Depth,
-            &createdAt,
-            &path,
-        )
+        cat, err := scanCategory(rows)
         if err != nil {
             return nil, fmt.Errorf("failed to scan category: %v", err)
         }
 
-        if parentID.Valid {
-            cat.ParentId = &parentID.String
-        }
-        
-        if image.Valid {
-            cat.Image = &image.String
-        }
-        if createdAt.Valid {
-            cat.CreatedAt = timestamppb.New(createdAt.Time)
-        }
+        categoryMap[cat.Id] = cat
+        ordered = append(ordered, cat)
+    }
 
-        categoryMap[cat.Id] = &cat
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating categories: %v", err)
+    }
+    rows.Close()
 
-        if !parentID.Valid {
-            rootCategories = append(rootCategories, &cat)
-        } else {
-            parent := categoryMap[parentID.String]
-            if parent != nil {
-                parent.Children = append(parent.Children, &cat)
-            }
+    // Link children once every row is loaded, so the tree does not depend
+    // on parents being read first.
+    for _, cat := range ordered {
+        if cat.ParentId == nil {
+            rootCategories = append(rootCategories, cat)
+        } else if parent := categoryMap[*cat.ParentId]; parent != nil {
+            parent.Children = append(parent.Children, cat)
         }
     }
 
-    if err = rows.Err(); err != nil {
-        return nil, fmt.Errorf("error iterating categories: %v", err)
+    if err := s.localizeCategories(ctx, req.Locale, rootCategories...); err != nil {
+        return nil, err
     }
 
     return &pb.ListCategoriesResponse{
@@ -200,16 +199,41 @@ func (s *CategoryService) ListCategories(ctx context.Context, req *pb.ListCatego
         req.Limit = 10
     }
 
+    rootCategories, cacheKey, ok := s.cachedTree(ctx, req)
+    if !ok {
+        var err error
+        rootCategories, err = s.loadCategoryTree(ctx, req)
+        if err != nil {
+            return nil, err
+        }
+        s.storeTree(ctx, cacheKey, req, rootCategories)
+    }
+
+    // Apply pagination to root categories
+    total := int32(len(rootCategories))
+    start := (req.Page - 1) * req.Limit
+    end := start + req.Limit
+    if start >= int32(len(rootCategories)) {
+        rootCategories = []*pb.Category{}
+    } else {
+        if end > int32(len(rootCategories)) {
+            end = int32(len(rootCategories))
+        }
+        rootCategories = rootCategories[start:end]
+    }
+
+    return &pb.ListCategoriesResponse{
+        Categories: rootCategories,
+        Total:     total,
+    }, nil
+}
+
+// loadCategoryTree builds the localized tree of all root categories, or of
+// req.ParentId and its children, from the database.
+func (s *CategoryService) loadCategoryTree(ctx context.Context, req *pb.ListCategoriesRequest) ([]*pb.Category, error) {
     query := `
-        SELECT 
-            id, 
-            name, 
-            description, 
-            image, 
-            depth,
-            parent_id,
-            created_at
-        FROM categories
+        SELECT ` + categoryColumns + `
+        FROM categories c
         WHERE 1=1
     `
     
@@ -217,10 +241,13 @@ func (s *CategoryService) ListCategories(ctx context.Context, req *pb.ListCatego
     
     // Add parent_id filter if provided to only get specific tree
     if req.ParentId != nil {
-        query += " AND (id = $1 OR parent_id = $1)"
+        query += " AND (c.id = $1 OR c.parent_id = $1)"
         args = append(args, *req.ParentId)
     }
 
+    // Siblings come in menu order
+    query += " ORDER BY c.depth, c.position, c.id"
+
     rows, err := s.DB.QueryContext(ctx, query, args...)
     if err != nil {
         return nil, fmt.Errorf("failed to query categories: %v", err)
@@ -229,42 +256,26 @@ func (s *CategoryService) ListCategories(ctx context.Context, req *pb.ListCatego
 
     // Store all categories in a map for easier lookup
     categoriesMap := make(map[string]*pb.Category)
+    var ordered []*pb.Category
     var rootCategories []*pb.Category
 
     // First pass: create all category objects
     for rows.Next() {
-        var cat pb.Category
-        var parentID, image sql.NullString
-        var createdAt sql.NullTime
-
-        err := rows.Scan(
-            &cat.Id,
-            &cat.Name,
-            &cat.Description,
-            &image,
-            &cat.Depth,
-            &parentID,
-            &createdAt,
-        )
+        cat, err := scanCategory(rows)
         if err != nil {
             return nil, fmt.Errorf("failed to scan category: %v", err)
         }
 
-        if image.Valid {
-            cat.Image = &image.String
-        }
-        if parentID.Valid {
-            cat.ParentId = &parentID.String
-        }
-        if createdAt.Valid {
-            cat.CreatedAt = timestamppb.New(createdAt.Time)
-        }
-
-        categoriesMap[cat.Id] = &cat
+        categoriesMap[cat.Id] = cat
+        ordered = append(ordered, cat)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to query categories: %v", err)
     }
+    rows.Close()
 
-    // Second pass: build the tree structure
-    for _, cat := range categoriesMap {
+    // Second pass: build the tree structure, keeping the query order
+    for _, cat := range ordered {
         if cat.ParentId == nil || *cat.ParentId == "" {
             // This is a root category
             rootCategories = append(rootCategories, cat)
@@ -279,27 +290,29 @@ func (s *CategoryService) ListCategories(ctx context.Context, req *pb.ListCatego
         }
     }
 
-    // Apply pagination to root categories
-    total := int32(len(rootCategories))
-    start := (req.Page - 1) * req.Limit
-    end := start + req.Limit
-    if start >= int32(len(rootCategories)) {
-        rootCategories = []*pb.Category{}
-    } else {
-        if end > int32(len(rootCategories)) {
-            end = int32(len(rootCategories))
-        }
-        rootCategories = rootCategories[start:end]
+    if err := s.localizeCategories(ctx, req.Locale, rootCategories...); err != nil {
+        return nil, err
     }
 
-    return &pb.ListCategoriesResponse{
-        Categories: rootCategories,
-        Total:     total,
-    }, nil
+    return rootCategories, nil
 }
 
 
 func (s *CategoryService) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.Category, error) {
+    tx, err := s.DB.BeginTx(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to begin transaction: %v", err)
+    }
+    defer tx.Rollback()
+
+    // A re-parent takes the tree lock in moveCategory; take it before any
+    // row lock so this cannot deadlock against a concurrent MoveCategory.
+    if req.ParentId != nil {
+        if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", treeLockKey); err != nil {
+            return nil, fmt.Errorf("failed to lock category tree: %v", err)
+        }
+    }
+
     // Build update query dynamically based on provided fields
     query := `UPDATE categories SET `
     
@@ -311,6 +324,30 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, req *pb.UpdateCate
         updates = append(updates, fmt.Sprintf("name = $%d", argCount))
         args = append(args, *req.Name)
         argCount++
+
+        var currentName, currentSlug string
+        err := tx.QueryRowContext(ctx, "SELECT name, slug FROM categories WHERE id = $1 FOR UPDATE", req.Id).
+            Scan(&currentName, &currentSlug)
+        if err == sql.ErrNoRows {
+            return nil, fmt.Errorf("category not found")
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to get category: %v", err)
+        }
+
+        if *req.Name != currentName {
+            slug, err := uniqueCategorySlug(ctx, tx, *req.Name, req.Id)
+            if err != nil {
+                return nil, err
+            }
+            if err := recordCategorySlugChange(ctx, tx, req.Id, currentSlug, slug); err != nil {
+                return nil, err
+            }
+
+            updates = append(updates, fmt.Sprintf("slug = $%d", argCount))
+            args = append(args, slug)
+            argCount++
+        }
     }
 
     if req.Description != nil {
@@ -326,9 +363,18 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, req *pb.UpdateCate
     }
 
     if req.ParentId != nil {
-        updates = append(updates, fmt.Sprintf("parent_id = $%d", argCount))
-        args = append(args, *req.ParentId)
-        argCount++
+        // Re-parenting goes through the same checks as MoveCategory.
+        if err := s.moveCategory(ctx, tx, req.Id, req.ParentId); err != nil {
+            return nil, err
+        }
+
+        if len(updates) == 0 {
+            if err = tx.Commit(); err != nil {
+                return nil, fmt.Errorf("failed to commit transaction: %v", err)
+            }
+            s.invalidateTree(ctx)
+            return s.getCategoryByID(ctx, req.Id)
+        }
     }
 
     // If no fields to update
@@ -338,23 +384,12 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, req *pb.UpdateCate
 
     // Combine all updates
     query += strings.Join(updates, ", ")
-    query += fmt.Sprintf(" WHERE id = $%d RETURNING id, name, description, image, depth, parent_id, created_at", argCount)
+    query += fmt.Sprintf(" WHERE id = $%d RETURNING id", argCount)
     args = append(args, req.Id)
 
-    // Execute update and get updated row
-    var category pb.Category
-    var parentID, image sql.NullString
-    var createdAt sql.NullTime
-
-    err := s.DB.QueryRowContext(ctx, query, args...).Scan(
-        &category.Id,
-        &category.Name,
-        &category.Description,
-        &image,
-        &category.Depth,
-        &parentID,
-        &createdAt,
-    )
+    // Execute update; the full row, with product counts, is read back after commit
+    var updatedID string
+    err = tx.QueryRowContext(ctx, query, args...).Scan(&updatedID)
 
     if err == sql.ErrNoRows {
         return nil, fmt.Errorf("category not found")
@@ -363,100 +398,10 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, req *pb.UpdateCate
         return nil, fmt.Errorf("failed to update category: %v", err)
     }
 
-    // Handle nullable fields
-    if image.Valid {
-        category.Image = &image.String
-    }
-    if parentID.Valid {
-        category.ParentId = &parentID.String
-    }
-    if createdAt.Valid {
-        category.CreatedAt = timestamppb.New(createdAt.Time)
-    }
-
-    return &category, nil
-}
-
-
-
-func (s *CategoryService) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.DeleteCategoryResponse, error) {
-    // Start a transaction
-    tx, err := s.DB.BeginTx(ctx, nil)
-    if err != nil {
-        return nil, fmt.Errorf("failed to begin transaction: %v", err)
-    }
-    defer tx.Rollback()
-
-    // First check if category exists
-    var exists bool
-    err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", req.Id).Scan(&exists)
-    if err != nil {
-        return nil, fmt.Errorf("failed to check category existence: %v", err)
-    }
-    if !exists {
-        return nil, fmt.Errorf("category not found")
-    }
-
-    var deletedCount int64
-    if req.DeleteChildren {
-        // Delete the category and all its descendants
-        withDescendantsQuery := `
-            WITH RECURSIVE category_tree AS (
-                -- Base case: the category we want to delete
-                SELECT id FROM categories WHERE id = $1
-                UNION ALL
-                -- Recursive case: get all children
-                SELECT c.id 
-                FROM categories c
-                INNER JOIN category_tree ct ON c.parent_id = ct.id
-            )
-            DELETE FROM categories 
-            WHERE id IN (SELECT id FROM category_tree)
-            RETURNING id`
-        
-        result, err := tx.ExecContext(ctx, withDescendantsQuery, req.Id)
-        if err != nil {
-            return nil, fmt.Errorf("failed to delete category and its children: %v", err)
-        }
-        deletedCount, err = result.RowsAffected()
-        if err != nil {
-            return nil, fmt.Errorf("failed to get affected rows: %v", err)
-        }
-    } else {
-        // Check if category has children
-        var hasChildren bool
-        err = tx.QueryRowContext(ctx, 
-            "SELECT EXISTS(SELECT 1 FROM categories WHERE parent_id = $1)", 
-            req.Id,
-        ).Scan(&hasChildren)
-        if err != nil {
-            return nil, fmt.Errorf("failed to check for children: %v", err)
-        }
-        if hasChildren {
-            return nil, fmt.Errorf("cannot delete category with children, set DeleteChildren to true to delete all")
-        }
-
-        // Delete single category
-        result, err := tx.ExecContext(ctx, 
-            "DELETE FROM categories WHERE id = $1", 
-            req.Id,
-        )
-        if err != nil {
-            return nil, fmt.Errorf("failed to delete category: %v", err)
-        }
-        deletedCount, err = result.RowsAffected()
-        if err != nil {
-            return nil, fmt.Errorf("failed to get affected rows: %v", err)
-        }
-    }
-
-    // Commit transaction
     if err = tx.Commit(); err != nil {
         return nil, fmt.Errorf("failed to commit transaction: %v", err)
     }
+    s.invalidateTree(ctx)
 
-    return &pb.DeleteCategoryResponse{
-        Success: true,
-        DeletedCount: deletedCount,
-    }, nil
-}
\ No newline at end of file
+    return s.getCategoryByID(ctx, updatedID)
+}
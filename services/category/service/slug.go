@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+const maxSlugAttempts = 100
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// uniqueCategorySlug finds the first free slug for name, skipping slugs that are
+// in use (or kept as redirects) by any category other than excludeID.
+func uniqueCategorySlug(ctx context.Context, q rowQuerier, name, excludeID string) (string, error) {
+	base := common.Slugify(name)
+
+	query := `
+        SELECT EXISTS(SELECT 1 FROM categories WHERE slug = $1 AND id <> $2)
+            OR EXISTS(SELECT 1 FROM category_slug_history WHERE slug = $1 AND category_id <> $2)`
+
+	for i := 1; i <= maxSlugAttempts; i++ {
+		candidate := common.SlugCandidate(base, i)
+
+		var taken bool
+		if err := q.QueryRowContext(ctx, query, candidate, excludeID).Scan(&taken); err != nil {
+			return "", fmt.Errorf("failed to check slug: %v", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find a free slug for %q", name)
+}
+
+// recordCategorySlugChange keeps oldSlug redirecting to the category and frees
+// newSlug if it was previously kept as a redirect.
+func recordCategorySlugChange(ctx context.Context, tx execer, categoryID, oldSlug, newSlug string) error {
+	if oldSlug == "" || oldSlug == newSlug {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO category_slug_history (slug, category_id, created_at)
+        VALUES ($1, $2, CURRENT_TIMESTAMP)
+        ON CONFLICT (slug) DO UPDATE SET category_id = EXCLUDED.category_id`,
+		oldSlug, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to save slug history: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM category_slug_history WHERE slug = $1", newSlug)
+	if err != nil {
+		return fmt.Errorf("failed to save slug history: %v", err)
+	}
+
+	return nil
+}
+
+func (s *CategoryService) GetCategoryBySlug(ctx context.Context, req *pb.GetCategoryBySlugRequest) (*pb.Category, error) {
+	query := `
+        SELECT id FROM categories WHERE slug = $1
+        UNION ALL
+        SELECT category_id FROM category_slug_history WHERE slug = $1
+        LIMIT 1`
+
+	var categoryID string
+	err := s.DB.QueryRowContext(ctx, query, req.Slug).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %v", err)
+	}
+
+	category, err := s.getCategoryByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.localizeCategories(ctx, req.Locale, category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (s *CategoryService) getCategoryByID(ctx context.Context, id string) (*pb.Category, error) {
+	query := `
+        SELECT ` + categoryColumns + `
+        FROM categories c
+        WHERE c.id = $1`
+
+	category, err := scanCategory(s.DB.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category: %v", err)
+	}
+
+	return category, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// The name and description columns on categories hold the default locale;
+// category_translations holds the other locales.
+
+func (s *CategoryService) UpsertCategoryTranslation(ctx context.Context, req *pb.CategoryTranslation) (*pb.CategoryTranslation, error) {
+	locale := common.NormalizeLocale(req.Locale)
+	if locale == "" {
+		return nil, fmt.Errorf("locale is required")
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	var exists bool
+	if err := s.DB.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1)", req.CategoryId).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check category: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	translation := &pb.CategoryTranslation{}
+	err := s.DB.QueryRowContext(ctx, `
+        INSERT INTO category_translations (category_id, locale, name, description, updated_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (category_id, locale)
+        DO UPDATE SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+        RETURNING category_id, locale, name, description`,
+		req.CategoryId, locale, req.Name, req.Description, time.Now()).Scan(
+		&translation.CategoryId,
+		&translation.Locale,
+		&translation.Name,
+		&translation.Description,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert category translation: %v", err)
+	}
+	s.invalidateTree(ctx)
+
+	return translation, nil
+}
+
+// localizeCategories overwrites name and description of the categories and
+// all their children with the best translation for locale, following
+// common.LocaleCandidates.
+func (s *CategoryService) localizeCategories(ctx context.Context, locale string, categories ...*pb.Category) error {
+	byID := make(map[string][]*pb.Category)
+	var collect func(cats []*pb.Category)
+	collect = func(cats []*pb.Category) {
+		for _, cat := range cats {
+			byID[cat.Id] = append(byID[cat.Id], cat)
+			collect(cat.Children)
+		}
+	}
+	collect(categories)
+	if len(byID) == 0 {
+		return nil
+	}
+
+	candidates := common.LocaleCandidates(locale)
+	rank := make(map[string]int, len(candidates))
+	for i, candidate := range candidates {
+		rank[candidate] = i
+	}
+
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT category_id, locale, name, description
+        FROM category_translations
+        WHERE category_id = ANY($1) AND locale = ANY($2)`,
+		ids, candidates)
+	if err != nil {
+		return fmt.Errorf("failed to get category translations: %v", err)
+	}
+	defer rows.Close()
+
+	best := make(map[string]*pb.CategoryTranslation)
+	for rows.Next() {
+		t := &pb.CategoryTranslation{}
+		if err := rows.Scan(&t.CategoryId, &t.Locale, &t.Name, &t.Description); err != nil {
+			return fmt.Errorf("failed to scan category translation: %v", err)
+		}
+		if current, ok := best[t.CategoryId]; !ok || rank[t.Locale] < rank[current.Locale] {
+			best[t.CategoryId] = t
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating category translations: %v", err)
+	}
+
+	for id, t := range best {
+		for _, cat := range byID[id] {
+			cat.Name = t.Name
+			if t.Description != "" {
+				cat.Description = t.Description
+			}
+		}
+	}
+
+	return nil
+}
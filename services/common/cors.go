@@ -11,7 +11,7 @@ func CorsMiddleware(next http.Handler) http.Handler {
         // Set CORS headers for all responses
         w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
         w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-        w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+        w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key")
         w.Header().Set("Access-Control-Allow-Credentials", "true")
 
         // Handle preflight
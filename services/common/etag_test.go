@@ -0,0 +1,43 @@
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMatchesETag(t *testing.T) {
+	etag := ETag([]byte(`{"data":[]}`))
+
+	cases := map[string]bool{
+		etag:               true,
+		`"other", ` + etag: true,
+		"W/" + etag:        true,
+		"*":                true,
+		"":                 false,
+		`"other"`:          false,
+	}
+	for header, want := range cases {
+		if got := MatchesETag(header, etag); got != want {
+			t.Errorf("MatchesETag(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestWriteWithETag(t *testing.T) {
+	body := []byte(`{"data":[]}`)
+
+	w := httptest.NewRecorder()
+	WriteWithETag(w, httptest.NewRequest(http.MethodGet, "/", nil), body)
+	if w.Code != http.StatusOK || w.Body.String() != string(body) {
+		t.Fatalf("got %d %q, want 200 with body", w.Code, w.Body.String())
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	w = httptest.NewRecorder()
+	WriteWithETag(w, r, body)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("got %d %q, want 304 without body", w.Code, w.Body.String())
+	}
+}
@@ -1,26 +1,76 @@
 package common
 
 import (
+	"net"
 	"net/http"
+	"os"
 	"strings"
 )
 
+// GetClientIP returns the address of the client. X-Forwarded-For and
+// X-Real-IP are only believed when the request comes from a proxy listed in
+// TRUSTED_PROXIES (comma-separated IPs or CIDRs); otherwise any client could
+// pick the address its requests are throttled under.
 func GetClientIP(r *http.Request) string {
-	forwardedFor := r.Header.Get("X-Forwarded-For")
-	if forwardedFor != "" {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+
+	trusted := trustedProxies()
+	if !isTrustedProxy(trusted, remoteIP) {
+		return remoteIP
+	}
+
+	// Each proxy appends the address it got the request from, so the client
+	// is the right-most entry that is not one of our proxies.
+	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
 		ips := strings.Split(forwardedFor, ",")
-		return strings.TrimSpace(ips[0])
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			if ip != "" && !isTrustedProxy(trusted, ip) {
+				return ip
+			}
+		}
 	}
 
-	realIP := r.Header.Get("X-Real-IP")
-	if realIP != "" {
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
 		return realIP
 	}
 
-	remoteAddr := r.RemoteAddr
-	if strings.Contains(remoteAddr, ":") {
-		return strings.Split(remoteAddr, ":")[0]
+	return remoteIP
+}
+
+func trustedProxies() []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			proxies = append(proxies, network)
+		}
 	}
+	return proxies
+}
 
-	return remoteAddr
+func isTrustedProxy(proxies []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
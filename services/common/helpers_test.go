@@ -0,0 +1,40 @@
+package common
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetClientIP(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.1")
+
+	cases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		realIP       string
+		want         string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "", "203.0.113.7"},
+		{"spoofed header from untrusted client", "203.0.113.7:5000", "198.51.100.1", "198.51.100.2", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:5000", "203.0.113.7", "", "203.0.113.7"},
+		{"client-supplied entry before the proxy's", "192.168.1.1:5000", "198.51.100.1, 203.0.113.7", "", "203.0.113.7"},
+		{"chain of trusted proxies", "10.1.2.3:5000", "203.0.113.7, 10.4.5.6", "", "203.0.113.7"},
+		{"real ip from trusted proxy", "10.1.2.3:5000", "", "203.0.113.7", "203.0.113.7"},
+		{"ipv6 client", "[2001:db8::1]:5000", "", "", "2001:db8::1"},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = c.remoteAddr
+		if c.forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", c.forwardedFor)
+		}
+		if c.realIP != "" {
+			r.Header.Set("X-Real-IP", c.realIP)
+		}
+		if got := GetClientIP(r); got != c.want {
+			t.Errorf("%s: GetClientIP() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const fallbackLocale = "id"
+
+// DefaultLocale is the locale of the untranslated catalog columns. It can be
+// overridden with DEFAULT_LOCALE.
+func DefaultLocale() string {
+	if locale := NormalizeLocale(os.Getenv("DEFAULT_LOCALE")); locale != "" {
+		return locale
+	}
+	return fallbackLocale
+}
+
+// NormalizeLocale lower-cases a language tag and uses "-" as separator, so
+// "en_US" and "en-us" are stored and looked up the same way.
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// LocaleCandidates lists the locales to look up in order of preference: the
+// requested locale, its base language and finally the default locale.
+func LocaleCandidates(locale string) []string {
+	var candidates []string
+	add := func(l string) {
+		if l == "" {
+			return
+		}
+		for _, c := range candidates {
+			if c == l {
+				return
+			}
+		}
+		candidates = append(candidates, l)
+	}
+
+	locale = NormalizeLocale(locale)
+	add(locale)
+	if base, _, found := strings.Cut(locale, "-"); found {
+		add(base)
+	}
+	add(DefaultLocale())
+
+	return candidates
+}
+
+// ParseAcceptLanguage returns the preferred locale of an Accept-Language
+// header, or "" when the header names none.
+func ParseAcceptLanguage(header string) string {
+	type tag struct {
+		locale string
+		q      float64
+	}
+
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(part, ";")
+		locale = NormalizeLocale(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		tags = append(tags, tag{locale, q})
+	}
+
+	if len(tags) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	return tags[0].locale
+}
+
+// RequestLocale is the locale the client asked for in Accept-Language. The
+// response varies on the header, so shared caches key on it.
+func RequestLocale(w http.ResponseWriter, r *http.Request) string {
+	w.Header().Add("Vary", "Accept-Language")
+	return ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
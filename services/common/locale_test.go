@@ -0,0 +1,40 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":                        "",
+		"en-US,en;q=0.9,id;q=0.8": "en-us",
+		"id;q=0.5, en;q=0.9":      "en",
+		"*":                       "",
+		"fr;q=0, id_ID":           "id-id",
+		"en;q=invalid, id;q=0.1":  "id",
+	}
+
+	for in, want := range cases {
+		if got := ParseAcceptLanguage(in); got != want {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLocaleCandidates(t *testing.T) {
+	t.Setenv("DEFAULT_LOCALE", "id")
+
+	cases := map[string][]string{
+		"en-US": {"en-us", "en", "id"},
+		"en":    {"en", "id"},
+		"id":    {"id"},
+		"":      {"id"},
+	}
+
+	for in, want := range cases {
+		if got := LocaleCandidates(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("LocaleCandidates(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+const (
+	// APIKeyHeader carries the API key of a service account. A key may
+	// also be sent as the Bearer token.
+	APIKeyHeader = "X-API-Key"
+	// APIKeyPrefix starts every API key issued by the auth service.
+	APIKeyPrefix = "gbk_"
+	// ServiceRole is the role of the principals API keys act as.
+	ServiceRole = "service"
+)
+
+// ErrInvalidAPIKey is returned by an APIKeyValidator for keys that are
+// unknown, expired or revoked.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyValidator resolves an API key to the service principal it acts as.
+type APIKeyValidator interface {
+	ValidateAPIKey(ctx context.Context, key, ipAddress string) (*pb.UserInfo, error)
+}
+
+// maxAPIKeyEntries bounds the local cache; expired entries are swept once
+// it grows past this size.
+const maxAPIKeyEntries = 1000
+
+type apiKeyEntry struct {
+	principal *pb.UserInfo
+	expires   time.Time
+}
+
+// APIKeyCache keeps the principals of valid keys for a short time so
+// AuthMiddleware does not call the auth service on every request. A
+// revoked key is accepted until its entry expires. Invalid keys are not
+// cached.
+type APIKeyCache struct {
+	validator APIKeyValidator
+	ttl       time.Duration
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]apiKeyEntry
+}
+
+func NewAPIKeyCache(validator APIKeyValidator, ttl time.Duration) *APIKeyCache {
+	return &APIKeyCache{
+		validator: validator,
+		ttl:       ttl,
+		entries:   make(map[[sha256.Size]byte]apiKeyEntry),
+	}
+}
+
+func (c *APIKeyCache) ValidateAPIKey(ctx context.Context, key, ipAddress string) (*pb.UserInfo, error) {
+	// Keys are held hashed, like the auth service stores them.
+	id := sha256.Sum256([]byte(key))
+	c.mu.Lock()
+	entry, ok := c.entries[id]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.principal, nil
+	}
+
+	principal, err := c.validator.ValidateAPIKey(ctx, key, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= maxAPIKeyEntries {
+		for id, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[id] = apiKeyEntry{principal: principal, expires: now.Add(c.ttl)}
+	return principal, nil
+}
+
+var apiKeys APIKeyValidator
+
+// SetAPIKeyValidator makes AuthMiddleware accept API keys as an
+// alternative to access tokens.
+func SetAPIKeyValidator(validator APIKeyValidator) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	apiKeys = validator
+}
+
+// apiKeyFromRequest returns the API key a request is made with, if any.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return strings.TrimSpace(key)
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if strings.HasPrefix(token, APIKeyPrefix) {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// serveAPIKey authenticates a request made with an API key. The principal
+// has no session, so routes that act on the caller's session refuse it.
+func serveAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, key string) {
+	keysMu.RLock()
+	validator := apiKeys
+	keysMu.RUnlock()
+	if validator == nil {
+		http.Error(w, "API keys are not accepted", http.StatusUnauthorized)
+		return
+	}
+
+	principal, err := validator.ValidateAPIKey(r.Context(), key, common.GetClientIP(r))
+	if errors.Is(err, ErrInvalidAPIKey) {
+		http.Error(w, "Invalid, expired or revoked API key", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to validate API key: %v", err)
+		http.Error(w, "Failed to validate API key", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserContextKey, principal)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,357 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Signing algorithms, as they appear in the "alg" header of a token.
+const (
+	AlgEdDSA = "EdDSA"
+	AlgRS256 = "RS256"
+)
+
+const rsaKeyBits = 2048
+
+// SigningKey is a private key tokens are signed with. Its ID is sent as the
+// "kid" header so verifiers can pick the matching public key.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+}
+
+// GenerateSigningKey creates a new key for algorithm with a random ID.
+func GenerateSigningKey(algorithm string) (*SigningKey, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate key id: %w", err)
+	}
+
+	var private crypto.Signer
+	switch algorithm {
+	case AlgEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		private = key
+	case AlgRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+
+	return &SigningKey{
+		ID:        hex.EncodeToString(id),
+		Algorithm: algorithm,
+		Private:   private,
+	}, nil
+}
+
+// JWK returns the public half of the key.
+func (k *SigningKey) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Alg: k.Algorithm, Use: "sig"}
+	switch public := k.Private.Public().(type) {
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	}
+	return jwk
+}
+
+// JWK is a public key in JSON Web Key form (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// PublicKey decodes the key.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || k.Crv != "Ed25519" || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key %q", k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA key %q", k.Kid)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, fmt.Errorf("invalid RSA key %q", k.Kid)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// JWKS is a JSON Web Key Set, as served on /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeyLookup finds the public key a token was signed with.
+type KeyLookup interface {
+	PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// KeySet holds the key new tokens are signed with and the keys it replaced
+// that are still inside their overlap window, so tokens signed before a
+// rotation stay valid until they expire.
+type KeySet struct {
+	mu      sync.RWMutex
+	current *SigningKey
+	keys    map[string]*SigningKey
+}
+
+func NewKeySet(current *SigningKey, previous ...*SigningKey) *KeySet {
+	s := &KeySet{}
+	s.Replace(current, previous...)
+	return s
+}
+
+// Replace swaps in a new current key and the set of previous keys.
+func (s *KeySet) Replace(current *SigningKey, previous ...*SigningKey) {
+	keys := map[string]*SigningKey{current.ID: current}
+	for _, key := range previous {
+		keys[key.ID] = key
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current = current
+	s.keys = keys
+}
+
+// Current returns the key new tokens are signed with.
+func (s *KeySet) Current() *SigningKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.current
+}
+
+func (s *KeySet) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key.Private.Public(), nil
+}
+
+// JWKS returns the public keys of the set, current key first.
+func (s *KeySet) JWKS() *JWKS {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jwks := &JWKS{Keys: []JWK{s.current.JWK()}}
+	for id, key := range s.keys {
+		if id != s.current.ID {
+			jwks.Keys = append(jwks.Keys, key.JWK())
+		}
+	}
+	return jwks
+}
+
+// KeyFetcher returns the current JWKS document of the token issuer.
+type KeyFetcher func(ctx context.Context) (*JWKS, error)
+
+// HTTPKeyFetcher fetches the JWKS from url, typically the gateway's
+// /.well-known/jwks.json.
+func HTTPKeyFetcher(url string) KeyFetcher {
+	client := &http.Client{Timeout: 5 * time.Second}
+	return func(ctx context.Context) (*JWKS, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch JWKS: %s", resp.Status)
+		}
+
+		var jwks JWKS
+		if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+			return nil, fmt.Errorf("invalid JWKS: %w", err)
+		}
+		return &jwks, nil
+	}
+}
+
+// minKeyRefresh bounds how often a token with an unknown kid triggers a
+// refetch, so forged kids cannot be used to hammer the issuer.
+const minKeyRefresh = 10 * time.Second
+
+// KeyCache verifies tokens against a JWKS fetched from the issuer. The
+// document is refetched once it is older than the TTL, or early when a
+// token names a key the cache does not know yet, e.g. right after a
+// rotation.
+type KeyCache struct {
+	fetch KeyFetcher
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	jwks    *JWKS
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+func NewKeyCache(fetch KeyFetcher, ttl time.Duration) *KeyCache {
+	return &KeyCache{fetch: fetch, ttl: ttl}
+}
+
+func (c *KeyCache) PublicKey(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	age := time.Since(c.fetched)
+	key, ok := c.keys[kid]
+	if age > c.ttl || (!ok && age > minKeyRefresh) {
+		if err := c.refresh(ctx); err != nil {
+			// Keep verifying with the keys we have while the issuer is down.
+			if ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		key, ok = c.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// JWKS returns the cached document, fetching it when it is stale.
+func (c *KeyCache) JWKS(ctx context.Context) (*JWKS, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.jwks == nil || time.Since(c.fetched) > c.ttl {
+		if err := c.refresh(ctx); err != nil && c.jwks == nil {
+			return nil, err
+		}
+	}
+	return c.jwks, nil
+}
+
+func (c *KeyCache) refresh(ctx context.Context) error {
+	jwks, err := c.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		key, err := jwk.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("JWKS contains no usable keys")
+	}
+
+	c.jwks = jwks
+	c.keys = keys
+	c.fetched = time.Now()
+	return nil
+}
+
+var (
+	keysMu           sync.RWMutex
+	signingKeys      *KeySet
+	verificationKeys KeyLookup
+	devKeysOnce      sync.Once
+	devKeys          *KeySet
+)
+
+// SetSigningKeys sets the keys GenerateToken signs with. Only the auth
+// service issues tokens.
+func SetSigningKeys(keys *KeySet) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	signingKeys = keys
+}
+
+// SetVerificationKeys sets where ValidateToken looks up public keys,
+// usually a KeyCache over the issuer's JWKS.
+func SetVerificationKeys(keys KeyLookup) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	verificationKeys = keys
+}
+
+// developmentKeys is used when no keys were configured: an in-memory key
+// that only verifies tokens signed by the same process.
+func developmentKeys() *KeySet {
+	devKeysOnce.Do(func() {
+		key, err := GenerateSigningKey(AlgEdDSA)
+		if err != nil {
+			panic(err)
+		}
+		devKeys = NewKeySet(key)
+	})
+	return devKeys
+}
+
+func currentSigningKeys() *KeySet {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	if signingKeys != nil {
+		return signingKeys
+	}
+	return developmentKeys()
+}
+
+func currentVerificationKeys() KeyLookup {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	if verificationKeys != nil {
+		return verificationKeys
+	}
+	if signingKeys != nil {
+		return signingKeys
+	}
+	return developmentKeys()
+}
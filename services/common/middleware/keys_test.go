@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+func useKeys(t *testing.T, signing *KeySet, verification KeyLookup) {
+	SetSigningKeys(signing)
+	SetVerificationKeys(verification)
+	t.Cleanup(func() {
+		SetSigningKeys(nil)
+		SetVerificationKeys(nil)
+	})
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{AlgEdDSA, AlgRS256} {
+		key, err := GenerateSigningKey(algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := NewKeySet(key)
+		useKeys(t, keys, keys)
+
+		token, err := GenerateToken(&pb.UserInfo{UserId: "u1", Permissions: []string{PermCatalogWrite}}, "s1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		claims, err := ValidateToken(token)
+		if err != nil {
+			t.Fatalf("%s: %v", algorithm, err)
+		}
+		if claims.UserID != "u1" || len(claims.Permissions) != 1 {
+			t.Errorf("%s: unexpected claims %+v", algorithm, claims)
+		}
+	}
+}
+
+func TestValidateTokenAfterRotation(t *testing.T) {
+	old, _ := GenerateSigningKey(AlgEdDSA)
+	keys := NewKeySet(old)
+	useKeys(t, keys, keys)
+
+	token, err := GenerateToken(&pb.UserInfo{UserId: "u1"}, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Inside the overlap window the old key is still published.
+	next, _ := GenerateSigningKey(AlgEdDSA)
+	keys.Replace(next, old)
+	if _, err := ValidateToken(token); err != nil {
+		t.Fatalf("token signed before rotation rejected: %v", err)
+	}
+
+	// Once it is dropped, its tokens are rejected.
+	keys.Replace(next)
+	if _, err := ValidateToken(token); err == nil {
+		t.Fatal("token signed with a retired key accepted")
+	}
+}
+
+func TestValidateTokenRejectsHMAC(t *testing.T) {
+	key, _ := GenerateSigningKey(AlgEdDSA)
+	keys := NewKeySet(key)
+	useKeys(t, keys, keys)
+
+	// A token "signed" with the public key as an HMAC secret must not pass.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTClaims{UserID: "u1"})
+	forged.Header["kid"] = key.ID
+	token, err := forged.SignedString([]byte(key.JWK().X))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateToken(token); err == nil {
+		t.Fatal("HS256 token accepted")
+	}
+}
+
+func TestKeyCacheRefetchesUnknownKid(t *testing.T) {
+	old, _ := GenerateSigningKey(AlgEdDSA)
+	keys := NewKeySet(old)
+
+	fetches := 0
+	cache := NewKeyCache(func(ctx context.Context) (*JWKS, error) {
+		fetches++
+		return keys.JWKS(), nil
+	}, time.Hour)
+	useKeys(t, keys, cache)
+
+	if _, err := cache.PublicKey(context.Background(), old.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// A rotation happens at the issuer; the cache picks up the new key the
+	// first time it sees its kid, once minKeyRefresh has passed.
+	next, _ := GenerateSigningKey(AlgEdDSA)
+	keys.Replace(next, old)
+	cache.fetched = time.Now().Add(-minKeyRefresh - time.Second)
+
+	token, err := GenerateToken(&pb.UserInfo{UserId: "u1"}, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateToken(token); err != nil {
+		t.Fatalf("token signed with the new key rejected: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2", fetches)
+	}
+
+	// Unknown kids do not refetch again right away.
+	if _, err := cache.PublicKey(context.Background(), "forged"); err == nil {
+		t.Fatal("unknown kid accepted")
+	}
+	if fetches != 2 {
+		t.Errorf("fetches = %d, want 2", fetches)
+	}
+}
+
+func TestKeyCacheServesCachedKeysWhenIssuerIsDown(t *testing.T) {
+	key, _ := GenerateSigningKey(AlgEdDSA)
+	keys := NewKeySet(key)
+
+	down := false
+	cache := NewKeyCache(func(ctx context.Context) (*JWKS, error) {
+		if down {
+			return nil, errors.New("unavailable")
+		}
+		return keys.JWKS(), nil
+	}, time.Minute)
+
+	if _, err := cache.PublicKey(context.Background(), key.ID); err != nil {
+		t.Fatal(err)
+	}
+	down = true
+	cache.fetched = time.Now().Add(-time.Hour)
+	if _, err := cache.PublicKey(context.Background(), key.ID); err != nil {
+		t.Fatalf("cached key not served: %v", err)
+	}
+}
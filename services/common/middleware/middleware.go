@@ -12,7 +12,9 @@ import (
 	"github.com/wafi04/golang-backend/grpc/pb"
 )
 
-var jwtSecretKey = []byte("jsjxakabxjaigisyqyg189")
+// AccessTokenTTL is the lifetime of access tokens. They are short-lived;
+// clients renew them with their refresh token.
+var AccessTokenTTL = 15 * time.Minute
 
 type JWTClaims struct {
 	UserID          string `json:"user_id"`
@@ -21,15 +23,29 @@ type JWTClaims struct {
 	Role            string `json:"role"`
 	IsActive        bool   `json:"is_active"`
 	IsEmailVerified bool   `json:"is_email_verified"`
+	// Roles and the permissions they grant, as of when the token was issued.
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	// SessionID is the session the token was issued for; revoking the
+	// session revokes the token.
+	SessionID string `json:"sid,omitempty"`
 	jwt.StandardClaims
 }
 
+// ValidateToken verifies a token signed by the auth service. The key is
+// picked by the token's kid header; only asymmetric algorithms are
+// accepted.
 func ValidateToken(tokenString string) (*JWTClaims, error) {
+	keys := currentVerificationKeys()
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		if alg := token.Method.Alg(); alg != AlgEdDSA && alg != AlgRS256 {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return jwtSecretKey, nil
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no key id")
+		}
+		return keys.PublicKey(context.Background(), kid)
 	})
 
 	if err != nil {
@@ -44,7 +60,8 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 	return claims, nil
 }
 
-func GenerateToken(user *pb.UserInfo) (string, error) {
+// GenerateToken issues an access token for user within a session.
+func GenerateToken(user *pb.UserInfo, sessionID string) (string, error) {
 	isEmailVerified := false
 	if user.IsEmailVerified {
 		isEmailVerified = true
@@ -56,15 +73,20 @@ func GenerateToken(user *pb.UserInfo) (string, error) {
 		Name:            user.Name,
 		Role:            user.Role,
 		IsEmailVerified: isEmailVerified,
+		Roles:           user.Roles,
+		Permissions:     user.Permissions,
+		SessionID:       sessionID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
+			ExpiresAt: time.Now().Add(AccessTokenTTL).Unix(),
 			IssuedAt:  time.Now().Unix(),
 			Issuer:    "wafiuddin",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(jwtSecretKey)
+	key := currentSigningKeys().Current()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	signedToken, err := token.SignedString(key.Private)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -74,7 +96,10 @@ func GenerateToken(user *pb.UserInfo) (string, error) {
 
 type contextKey string
 
-const UserContextKey contextKey = "user"
+const (
+	UserContextKey    contextKey = "user"
+	SessionContextKey contextKey = "session"
+)
 
 func GetUserFromContext(ctx context.Context) (*pb.UserInfo, error) {
 	user, ok := ctx.Value(UserContextKey).(*pb.UserInfo)
@@ -83,8 +108,24 @@ func GetUserFromContext(ctx context.Context) (*pb.UserInfo, error) {
 	}
 	return user, nil
 }
+
+// GetSessionIDFromContext returns the session of the authenticated token.
+func GetSessionIDFromContext(ctx context.Context) (string, error) {
+	sessionID, ok := ctx.Value(SessionContextKey).(string)
+	if !ok || sessionID == "" {
+		return "", errors.New("session not found in context")
+	}
+	return sessionID, nil
+}
+
 func AuthMiddleware(next http.Handler) http.Handler {
     return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        // Service accounts authenticate with an API key instead of a token
+        if key := apiKeyFromRequest(r); key != "" {
+            serveAPIKey(w, r, next, key)
+            return
+        }
+
         authHeader := r.Header.Get("Authorization")
         if authHeader == "" {
             http.Error(w, "Authorization header missing", http.StatusUnauthorized)
@@ -109,6 +150,11 @@ func AuthMiddleware(next http.Handler) http.Handler {
             return
         }
 
+        if sessionRevoked(r.Context(), claims.SessionID) {
+            http.Error(w, "Session has been revoked", http.StatusUnauthorized)
+            return
+        }
+
         // Create a new UserInfo with the same fields
         user := &pb.UserInfo{
             UserId:          claims.UserID,
@@ -116,10 +162,13 @@ func AuthMiddleware(next http.Handler) http.Handler {
             Name:            claims.Name,
             Role:            claims.Role,
             IsEmailVerified: claims.IsEmailVerified,
+            Roles:           claims.Roles,
+            Permissions:     claims.Permissions,
         }
 
         // Use the exact type expected by GetUserFromContext
         ctx := context.WithValue(r.Context(), UserContextKey, user)
+        ctx = context.WithValue(ctx, SessionContextKey, claims.SessionID)
         next.ServeHTTP(w, r.WithContext(ctx))
     })
 }
\ No newline at end of file
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// Permissions granted through roles in the auth service. Routes declare the
+// ones they need with RequirePermissions.
+const (
+	PermCatalogWrite  = "catalog:write"
+	PermStockAdjust   = "stock:adjust"
+	PermOrdersReadAll = "orders:read_all"
+	PermRolesManage   = "roles:manage"
+	PermUsersManage   = "users:manage"
+	PermAPIKeysManage = "api_keys:manage"
+)
+
+// HasPermissions reports whether the user holds every one of perms.
+func HasPermissions(granted []string, perms ...string) bool {
+	for _, perm := range perms {
+		found := false
+		for _, g := range granted {
+			if g == perm {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// RequirePermissions lets a request through only if the authenticated user
+// holds all of perms. It must run after AuthMiddleware.
+func RequirePermissions(perms ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, err := GetUserFromContext(r.Context())
+			if err != nil {
+				common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+			if !HasPermissions(user.Permissions, perms...) {
+				common.SendErrorResponse(w, http.StatusForbidden, "Forbidden")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
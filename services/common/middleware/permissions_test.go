@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+func TestRequirePermissions(t *testing.T) {
+	handler := RequirePermissions(PermCatalogWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	cases := map[string]struct {
+		user *pb.UserInfo
+		want int
+	}{
+		"anonymous":      {nil, http.StatusUnauthorized},
+		"no permissions": {&pb.UserInfo{UserId: "u1"}, http.StatusForbidden},
+		"other":          {&pb.UserInfo{UserId: "u1", Permissions: []string{PermStockAdjust}}, http.StatusForbidden},
+		"granted":        {&pb.UserInfo{UserId: "u1", Permissions: []string{PermStockAdjust, PermCatalogWrite}}, http.StatusNoContent},
+	}
+	for name, c := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/category", nil)
+		if c.user != nil {
+			r = r.WithContext(context.WithValue(r.Context(), UserContextKey, c.user))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != c.want {
+			t.Errorf("%s: got %d, want %d", name, w.Code, c.want)
+		}
+	}
+}
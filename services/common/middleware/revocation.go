@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revokedSessionPrefix = "session:revoked:"
+	// revocationChannel announces revoked session ids so local caches can
+	// drop them without waiting for their entries to expire.
+	revocationChannel = "sessions:revoked"
+)
+
+// RevocationChecker reports whether the session a token belongs to has
+// been revoked.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+// RevocationStore is the Redis denylist of revoked sessions. Entries only
+// need to outlive the access tokens of the session, so they expire after
+// the access token lifetime.
+type RevocationStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+func NewRevocationStore(client *redis.Client, ttl time.Duration) *RevocationStore {
+	return &RevocationStore{client: client, ttl: ttl}
+}
+
+// Revoke denylists sessions and announces them on the revocation channel.
+func (s *RevocationStore) Revoke(ctx context.Context, sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	pipe := s.client.Pipeline()
+	for _, id := range sessionIDs {
+		pipe.Set(ctx, revokedSessionPrefix+id, 1, s.ttl)
+		pipe.Publish(ctx, revocationChannel, id)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *RevocationStore) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	n, err := s.client.Exists(ctx, revokedSessionPrefix+sessionID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Subscribe calls revoked for every session announced on the revocation
+// channel until ctx is done.
+func (s *RevocationStore) Subscribe(ctx context.Context, revoked func(sessionID string)) {
+	sub := s.client.Subscribe(ctx, revocationChannel)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			revoked(msg.Payload)
+		}
+	}
+}
+
+// maxRevocationEntries bounds the local cache; expired entries are swept
+// once it grows past this size.
+const maxRevocationEntries = 10000
+
+type revocationEntry struct {
+	revoked bool
+	expires time.Time
+}
+
+// RevocationCache keeps answers of a RevocationChecker for a short time so
+// AuthMiddleware does not hit Redis on every request. Sessions announced
+// through MarkRevoked are rejected at once.
+type RevocationCache struct {
+	checker RevocationChecker
+	ttl     time.Duration
+
+	mu      sync.Mutex
+	entries map[string]revocationEntry
+}
+
+func NewRevocationCache(checker RevocationChecker, ttl time.Duration) *RevocationCache {
+	return &RevocationCache{
+		checker: checker,
+		ttl:     ttl,
+		entries: make(map[string]revocationEntry),
+	}
+}
+
+func (c *RevocationCache) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[sessionID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.revoked, nil
+	}
+
+	revoked, err := c.checker.IsRevoked(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	c.store(sessionID, revoked)
+	return revoked, nil
+}
+
+// MarkRevoked records a revocation announced by the auth service.
+func (c *RevocationCache) MarkRevoked(sessionID string) {
+	c.store(sessionID, true)
+}
+
+func (c *RevocationCache) store(sessionID string, revoked bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.entries) >= maxRevocationEntries {
+		for id, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	// Revoked sessions stay revoked, so they are kept until their tokens
+	// have expired.
+	ttl := c.ttl
+	if revoked {
+		ttl = AccessTokenTTL
+	}
+	c.entries[sessionID] = revocationEntry{revoked: revoked, expires: now.Add(ttl)}
+}
+
+var revocations RevocationChecker
+
+// SetRevocationChecker makes AuthMiddleware reject tokens of revoked
+// sessions.
+func SetRevocationChecker(checker RevocationChecker) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	revocations = checker
+}
+
+// sessionRevoked reports whether a token's session is revoked. Without a
+// checker nothing is revoked. If the check fails the request is let
+// through: tokens are short-lived and an outage of Redis should not lock
+// every user out.
+func sessionRevoked(ctx context.Context, sessionID string) bool {
+	keysMu.RLock()
+	checker := revocations
+	keysMu.RUnlock()
+	if checker == nil {
+		return false
+	}
+	if sessionID == "" {
+		return true
+	}
+
+	revoked, err := checker.IsRevoked(ctx, sessionID)
+	if err != nil {
+		log.Printf("Failed to check session revocation: %v", err)
+		return false
+	}
+	return revoked
+}
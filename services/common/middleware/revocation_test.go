@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+type fakeRevocations struct {
+	revoked map[string]bool
+	calls   int
+}
+
+func (f *fakeRevocations) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	f.calls++
+	return f.revoked[sessionID], nil
+}
+
+func TestRevocationCache(t *testing.T) {
+	store := &fakeRevocations{revoked: map[string]bool{}}
+	cache := NewRevocationCache(store, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if revoked, _ := cache.IsRevoked(ctx, "s1"); revoked {
+			t.Fatal("active session reported revoked")
+		}
+	}
+	if store.calls != 1 {
+		t.Errorf("store calls = %d, want 1", store.calls)
+	}
+
+	// An announced revocation applies at once, without waiting for the
+	// cached answer to expire.
+	cache.MarkRevoked("s1")
+	if revoked, _ := cache.IsRevoked(ctx, "s1"); !revoked {
+		t.Fatal("revoked session accepted")
+	}
+}
+
+func TestAuthMiddlewareRejectsRevokedSessions(t *testing.T) {
+	key, _ := GenerateSigningKey(AlgEdDSA)
+	keys := NewKeySet(key)
+	useKeys(t, keys, keys)
+
+	store := &fakeRevocations{revoked: map[string]bool{"stolen": true}}
+	SetRevocationChecker(store)
+	t.Cleanup(func() { SetRevocationChecker(nil) })
+
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID, err := GetSessionIDFromContext(r.Context())
+		if err != nil {
+			t.Error(err)
+		}
+		w.Write([]byte(sessionID))
+	}))
+
+	cases := map[string]struct {
+		sessionID string
+		want      int
+	}{
+		"active":     {"s1", http.StatusOK},
+		"revoked":    {"stolen", http.StatusUnauthorized},
+		"no session": {"", http.StatusUnauthorized},
+	}
+	for name, c := range cases {
+		token, err := GenerateToken(&pb.UserInfo{UserId: "u1"}, c.sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := httptest.NewRequest(http.MethodGet, "/auth/profile", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != c.want {
+			t.Errorf("%s: status = %d, want %d", name, w.Code, c.want)
+		}
+	}
+}
@@ -41,3 +41,23 @@ func IsSkuValid(sku string) bool {
 	pattern := regexp.MustCompile(`^[A-Z]{3}-\d{4}-\d{4}$`)
 	return pattern.MatchString(sku)
 }
+
+var slugSeparators = regexp.MustCompile("[^a-z0-9]+")
+
+// Slugify turns a display name into a lowercase, URL-safe slug.
+func Slugify(name string) string {
+	slug := slugSeparators.ReplaceAllString(strings.ToLower(name), "-")
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "item"
+	}
+	return slug
+}
+
+// SlugCandidate returns the n-th collision candidate for base: base, base-2, base-3, ...
+func SlugCandidate(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, n)
+}
@@ -0,0 +1,27 @@
+package common
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	cases := map[string]string{
+		"Starter Kit":          "starter-kit",
+		"  Sepatu Lari / EU ":  "sepatu-lari-eu",
+		"T-Shirt (Black) 2025": "t-shirt-black-2025",
+		"!!!":                  "item",
+	}
+
+	for in, want := range cases {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSlugCandidate(t *testing.T) {
+	if got := SlugCandidate("shoes", 1); got != "shoes" {
+		t.Errorf("SlugCandidate(shoes, 1) = %q", got)
+	}
+	if got := SlugCandidate("shoes", 3); got != "shoes-3" {
+		t.Errorf("SlugCandidate(shoes, 3) = %q", got)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
 
 	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
 	"github.com/wafi04/golang-backend/services/gateway/server"
 	authhandler "github.com/wafi04/golang-backend/services/gateway/server/auth"
 	categoryhandler "github.com/wafi04/golang-backend/services/gateway/server/category"
@@ -30,6 +33,22 @@ func main() {
 	if err != nil {
 		logs.Log(common.ErrorLevel, "Failed to connect Auth Service : %v", err)
 	}
+	// Tokens of revoked sessions are rejected; answers are cached for a few
+	// seconds and revocations announced by the auth service apply at once.
+	if redisAddr := os.Getenv("REDIS_ADDR"); redisAddr != "" {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     redisAddr,
+			Password: os.Getenv("REDIS_PASSWORD"),
+		})
+		defer redisClient.Close()
+		store := middleware.NewRevocationStore(redisClient, middleware.AccessTokenTTL)
+		revocations := middleware.NewRevocationCache(store, 5*time.Second)
+		go store.Subscribe(context.Background(), revocations.MarkRevoked)
+		middleware.SetRevocationChecker(revocations)
+	} else {
+		logs.Log(common.InfoLevel, "REDIS_ADDR not set, session revocation checks disabled")
+	}
+
 	categorygateway, err := categoryhandler.NewCategoryGateway(ctx)
 	if err != nil {
 		logs.Log(common.ErrorLevel, "Failed to connect Category Service : %v", err)
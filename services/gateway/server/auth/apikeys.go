@@ -0,0 +1,104 @@
+package authhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyCacheTTL is how long the gateway trusts a validated API key. A
+// revoked key is refused at the latest this long after its revocation.
+const apiKeyCacheTTL = 30 * time.Second
+
+// ValidateAPIKey asks the auth service for the principal of an API key,
+// making the handler the APIKeyValidator of AuthMiddleware.
+func (h *AuthHandler) ValidateAPIKey(ctx context.Context, key, ipAddress string) (*pb.UserInfo, error) {
+	resp, err := h.authClient.ValidateAPIKey(ctx, &pb.ValidateAPIKeyRequest{
+		Key:       key,
+		IpAddress: ipAddress,
+	})
+	if status.Code(err) == codes.Unauthenticated {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Principal, nil
+}
+
+// API key administration; the routes require the api_keys:manage
+// permission.
+
+// HandleCreateAPIKey issues a key with scopes the caller holds. The key is
+// only ever shown in this response.
+func (h *AuthHandler) HandleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		Name      string   `json:"name"`
+		Scopes    []string `json:"scopes"`
+		ExpiresAt int64    `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.authClient.CreateAPIKey(r.Context(), &pb.CreateAPIKeyRequest{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: user.UserId,
+	})
+	if err != nil {
+		sendAPIKeyError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusCreated, "API key created; store it now, it is not shown again", res)
+}
+
+func (h *AuthHandler) HandleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authClient.ListAPIKeys(r.Context(), &pb.ListAPIKeysRequest{
+		IncludeRevoked: r.URL.Query().Get("include_revoked") == "true",
+	})
+	if err != nil {
+		sendAPIKeyError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "API keys retrieved successfully", res)
+}
+
+func (h *AuthHandler) HandleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authClient.RevokeAPIKey(r.Context(), &pb.RevokeAPIKeyRequest{
+		KeyId: mux.Vars(r)["id"],
+	})
+	if err != nil {
+		sendAPIKeyError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "API key revoked successfully", res)
+}
+
+func sendAPIKeyError(w http.ResponseWriter, err error) {
+	if strings.Contains(err.Error(), "cannot grant scopes") {
+		common.SendErrorResponseWithDetails(w, http.StatusForbidden, "Forbidden", status.Convert(err).Message())
+		return
+	}
+	sendRoleError(w, err)
+}
@@ -1,6 +1,8 @@
 package authhandler
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"sync"
@@ -99,24 +101,15 @@ func (h *AuthHandler)  HandleResendVerification(w http.ResponseWriter, r *http.R
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
         return
 	}
-	token,err := middleware.GenerateToken(&pb.UserInfo{
-		UserId: user.UserId,
-		Role : user.Role,
-		Name: user.Name,
-		Email: user.Email,
-	})
-
-    tokenStore.StoreToken(user.UserId, token)
-	if err != nil {
-        http.Error(w, "Invalid Generate Token", http.StatusBadRequest)
+	// The verification token only identifies the pending verification;
+	// the gateway cannot sign tokens, so it is a random value.
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+        http.Error(w, "Invalid Generate Token", http.StatusInternalServerError)
         return
     }
-
-	_,err = middleware.ValidateToken(token)
-	if err != nil {
-		 http.Error(w, "Token is invalid", http.StatusBadRequest)
-        return
-	} 
+	token := hex.EncodeToString(tokenBytes)
+    tokenStore.StoreToken(user.UserId, token)
 
 	verif,err := h.authClient.ResendVerification(r.Context(), &pb.ResendVerificationRequest{
 		UserId: user.UserId,
@@ -126,6 +119,8 @@ func (h *AuthHandler)  HandleResendVerification(w http.ResponseWriter, r *http.R
 
 	if err != nil {
 		h.logger.Log(common.ErrorLevel, "Failed to validate token : %v",err)
+		http.Error(w, "Failed to send verification email", http.StatusInternalServerError)
+		return
 	}
 	res := common.Success(verif,"Success to Verification Email")
 	if err := json.NewEncoder(w).Encode(res); err != nil {
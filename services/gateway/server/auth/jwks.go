@@ -0,0 +1,54 @@
+package authhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+)
+
+// jwksTTL is how long the gateway caches the auth service's public keys.
+// Tokens signed with a key it has not seen yet trigger an early refetch.
+const jwksTTL = 5 * time.Minute
+
+// fetchJWKS loads the public signing keys from the auth service.
+func (h *AuthHandler) fetchJWKS(ctx context.Context) (*middleware.JWKS, error) {
+	resp, err := h.authClient.GetJWKS(ctx, &pb.GetJWKSRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	jwks := &middleware.JWKS{Keys: make([]middleware.JWK, 0, len(resp.Keys))}
+	for _, key := range resp.Keys {
+		jwks.Keys = append(jwks.Keys, middleware.JWK{
+			Kty: key.Kty,
+			Kid: key.Kid,
+			Alg: key.Alg,
+			Use: key.Use,
+			Crv: key.Crv,
+			X:   key.X,
+			N:   key.N,
+			E:   key.E,
+		})
+	}
+	return jwks, nil
+}
+
+// HandleJWKS serves /.well-known/jwks.json so services can verify access
+// tokens without a shared secret.
+func (h *AuthHandler) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	jwks, err := h.keys.JWKS(r.Context())
+	if err != nil {
+		h.logger.Log(common.ErrorLevel, "Failed to get JWKS: %v", err)
+		http.Error(w, "Signing keys unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	json.NewEncoder(w).Encode(jwks)
+}
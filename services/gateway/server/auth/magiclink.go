@@ -0,0 +1,77 @@
+package authhandler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"google.golang.org/grpc/status"
+)
+
+// HandleRequestMagicLink emails a login link. The response is the same
+// whether or not the email has an account.
+func (h *AuthHandler) HandleRequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.authClient.RequestMagicLink(r.Context(), &pb.RequestMagicLinkRequest{
+		Email: req.Email,
+	})
+	if err != nil {
+		log.Printf("Magic link request failed: %v", err)
+		if strings.Contains(err.Error(), "too many login links") {
+			common.SendErrorResponse(w, http.StatusTooManyRequests, status.Convert(err).Message())
+			return
+		}
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Failed to send login link")
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "If an account exists for this email, a login link has been sent", resp)
+}
+
+// HandleConsumeMagicLink logs in with the token from a login link and
+// answers like HandleLogin.
+func (h *AuthHandler) HandleConsumeMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.authClient.ConsumeMagicLink(r.Context(), &pb.ConsumeMagicLinkRequest{
+		Token:      req.Token,
+		DeviceInfo: r.UserAgent(),
+		IpAddress:  common.GetClientIP(r),
+	})
+	if err != nil {
+		log.Printf("Magic link login failed: %v", err)
+		switch {
+		case strings.Contains(err.Error(), "invalid or expired login link"):
+			common.SendErrorResponse(w, http.StatusUnauthorized, "Invalid or expired login link")
+		case strings.Contains(err.Error(), "account is deactivated"):
+			common.SendErrorResponse(w, http.StatusForbidden, "Account is deactivated")
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	if resp.MfaRequired {
+		common.SendSuccessResponse(w, http.StatusOK, "Two-factor authentication required", resp)
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+resp.AccessToken)
+	common.SendSuccessResponse(w, http.StatusOK, "Login Successfully", resp)
+}
@@ -0,0 +1,136 @@
+package authhandler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/common/middleware"
+	"google.golang.org/grpc/status"
+)
+
+// HandleVerifyLoginMFA completes a login that answered with an mfa
+// challenge, using a code from the authenticator app or a recovery code.
+func (h *AuthHandler) HandleVerifyLoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		MFAChallenge string `json:"mfa_challenge"`
+		Code         string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MFAChallenge == "" || req.Code == "" {
+		http.Error(w, "mfa_challenge and code are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.authClient.VerifyLoginMFA(r.Context(), &pb.VerifyLoginMFARequest{
+		MfaChallenge: req.MFAChallenge,
+		Code:         req.Code,
+	})
+	if err != nil {
+		log.Printf("Login 2FA failed: %v", err)
+		sendMFAError(w, err)
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+resp.AccessToken)
+	common.SendSuccessResponse(w, http.StatusOK, "Login Successfully", resp)
+}
+
+// HandleEnrollMFA starts 2FA enrollment. The otpauth URI is what the QR
+// code shown to the user encodes.
+func (h *AuthHandler) HandleEnrollMFA(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authClient.EnrollMFA(r.Context(), &pb.EnrollMFARequest{
+		UserId: user.UserId,
+	})
+	if err != nil {
+		sendMFAError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Scan the QR code with your authenticator app", resp)
+}
+
+// HandleConfirmMFA enables 2FA and returns the recovery codes; they are
+// not shown again.
+func (h *AuthHandler) HandleConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	code, ok := decodeMFACode(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.authClient.ConfirmMFA(r.Context(), &pb.ConfirmMFARequest{
+		UserId: user.UserId,
+		Code:   code,
+	})
+	if err != nil {
+		sendMFAError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Two-factor authentication enabled", resp)
+}
+
+func (h *AuthHandler) HandleDisableMFA(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	code, ok := decodeMFACode(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := h.authClient.DisableMFA(r.Context(), &pb.DisableMFARequest{
+		UserId: user.UserId,
+		Code:   code,
+	})
+	if err != nil {
+		sendMFAError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Two-factor authentication disabled", resp)
+}
+
+func decodeMFACode(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "code is required", http.StatusBadRequest)
+		return "", false
+	}
+	return req.Code, true
+}
+
+func sendMFAError(w http.ResponseWriter, err error) {
+	switch {
+	case strings.Contains(err.Error(), "mfa challenge"):
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Invalid or expired challenge, please log in again")
+	case strings.Contains(err.Error(), "invalid mfa code"):
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Invalid code")
+	case strings.Contains(err.Error(), "too many login attempts"),
+		strings.Contains(err.Error(), "account temporarily locked"):
+		common.SendErrorResponse(w, http.StatusTooManyRequests, status.Convert(err).Message())
+	case strings.Contains(err.Error(), "2FA"):
+		common.SendErrorResponseWithDetails(w, http.StatusConflict, "Invalid request", err.Error())
+	case strings.Contains(err.Error(), "not found"):
+		common.SendErrorResponse(w, http.StatusNotFound, "User not found")
+	default:
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
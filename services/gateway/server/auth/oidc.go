@@ -0,0 +1,116 @@
+package authhandler
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// oidcStateCookie binds a social login to the browser that started it, so
+// a callback cannot be replayed into someone else's browser.
+const oidcStateCookie = "oidc_state"
+
+// HandleListOIDCProviders lists the providers users can sign in with.
+func (h *AuthHandler) HandleListOIDCProviders(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.authClient.ListOIDCProviders(r.Context(), &pb.ListOIDCProvidersRequest{})
+	if err != nil {
+		sendOIDCError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Login providers", resp)
+}
+
+// HandleOIDCLogin sends the user to the provider's sign-in page.
+func (h *AuthHandler) HandleOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+	resp, err := h.authClient.StartOIDCLogin(r.Context(), &pb.StartOIDCLoginRequest{
+		Provider: provider,
+	})
+	if err != nil {
+		log.Printf("Starting %s login failed: %v", provider, err)
+		sendOIDCError(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    resp.State,
+		Path:     "/api/v1/auth/oidc",
+		Expires:  time.Unix(resp.ExpiresAt, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		// Lax, so the cookie is sent on the provider's redirect back.
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, resp.AuthorizationUrl, http.StatusFound)
+}
+
+// HandleOIDCCallback finishes a social login when the provider sends the
+// user back, and answers like HandleLogin.
+func (h *AuthHandler) HandleOIDCCallback(w http.ResponseWriter, r *http.Request) {
+	provider := mux.Vars(r)["provider"]
+	query := r.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Login was not completed", errCode)
+		return
+	}
+
+	state := query.Get("state")
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Invalid or expired login, please try again")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	resp, err := h.authClient.CompleteOIDCLogin(r.Context(), &pb.CompleteOIDCLoginRequest{
+		Provider:   provider,
+		State:      state,
+		Code:       query.Get("code"),
+		DeviceInfo: r.UserAgent(),
+		IpAddress:  common.GetClientIP(r),
+	})
+	if err != nil {
+		log.Printf("%s login failed: %v", provider, err)
+		sendOIDCError(w, err)
+		return
+	}
+
+	if resp.MfaRequired {
+		common.SendSuccessResponse(w, http.StatusOK, "Two-factor authentication required", resp)
+		return
+	}
+
+	w.Header().Set("Authorization", "Bearer "+resp.AccessToken)
+	common.SendSuccessResponse(w, http.StatusOK, "Login Successfully", resp)
+}
+
+func sendOIDCError(w http.ResponseWriter, err error) {
+	switch {
+	case strings.Contains(err.Error(), "unknown login provider"):
+		common.SendErrorResponse(w, http.StatusNotFound, "Unknown login provider")
+	case strings.Contains(err.Error(), "login provider unavailable"):
+		common.SendErrorResponse(w, http.StatusServiceUnavailable, "Login provider unavailable, please try again later")
+	case strings.Contains(err.Error(), "login state"),
+		strings.Contains(err.Error(), "login failed"):
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Invalid or expired login, please try again")
+	case strings.Contains(err.Error(), "email is not verified"):
+		common.SendErrorResponse(w, http.StatusForbidden, "Your email is not verified with this provider")
+	case strings.Contains(err.Error(), "account is deactivated"):
+		common.SendErrorResponse(w, http.StatusForbidden, "Account is deactivated")
+	default:
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
@@ -0,0 +1,67 @@
+package authhandler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// HandleRequestPasswordReset emails a reset link. The response is the same
+// whether or not the email has an account.
+func (h *AuthHandler) HandleRequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, "email is required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.authClient.RequestPasswordReset(r.Context(), &pb.RequestPasswordResetRequest{
+		Email: req.Email,
+	})
+	if err != nil {
+		log.Printf("Password reset request failed: %v", err)
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "If an account exists for this email, a reset link has been sent", resp)
+}
+
+// HandleResetPassword sets a new password with the token from a reset
+// link. All sessions of the user are ended.
+func (h *AuthHandler) HandleResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" || req.NewPassword == "" {
+		http.Error(w, "token and new_password are required", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.authClient.ResetPassword(r.Context(), &pb.ResetPasswordRequest{
+		ResetToken:  req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		log.Printf("Password reset failed: %v", err)
+		if strings.Contains(err.Error(), "invalid password") {
+			common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Invalid request", err.Error())
+			return
+		}
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Failed to reset password")
+		return
+	}
+	if !resp.Success {
+		common.SendErrorResponse(w, http.StatusBadRequest, resp.Message)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, resp.Message, resp)
+}
@@ -0,0 +1,121 @@
+package authhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// Role administration; the routes require the roles:manage permission.
+
+func (h *AuthHandler) HandleListRoles(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authClient.ListRoles(r.Context(), &pb.ListRolesRequest{})
+	if err != nil {
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list roles")
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Roles retrieved successfully", res)
+}
+
+func (h *AuthHandler) HandleUpsertRole(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Description string   `json:"description"`
+		Permissions []string `json:"permissions"`
+		RequiresMFA bool     `json:"requires_mfa"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.authClient.UpsertRole(r.Context(), &pb.Role{
+		Name:        mux.Vars(r)["name"],
+		Description: req.Description,
+		Permissions: req.Permissions,
+		RequiresMfa: req.RequiresMFA,
+	})
+	if err != nil {
+		sendRoleError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Role saved successfully", role)
+}
+
+func (h *AuthHandler) HandleGetUserRoles(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authClient.GetUserRoles(r.Context(), &pb.GetUserRequest{
+		UserId: mux.Vars(r)["id"],
+	})
+	if err != nil {
+		sendRoleError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "User roles retrieved successfully", res)
+}
+
+func (h *AuthHandler) HandleAssignRole(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.authClient.AssignRole(r.Context(), &pb.AssignRoleRequest{
+		UserId: mux.Vars(r)["id"],
+		Role:   req.Role,
+	})
+	if err != nil {
+		sendRoleError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Role assigned successfully", res)
+}
+
+func (h *AuthHandler) HandleRevokeRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := h.authClient.RevokeRole(r.Context(), &pb.AssignRoleRequest{
+		UserId: vars["id"],
+		Role:   vars["role"],
+	})
+	if err != nil {
+		sendRoleError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Role revoked successfully", res)
+}
+
+// HandleUnlockAccount lifts a login lockout; the route requires the
+// users:manage permission.
+func (h *AuthHandler) HandleUnlockAccount(w http.ResponseWriter, r *http.Request) {
+	res, err := h.authClient.UnlockAccount(r.Context(), &pb.UnlockAccountRequest{
+		UserId: mux.Vars(r)["id"],
+	})
+	if err != nil {
+		sendRoleError(w, err)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Account unlocked successfully", res)
+}
+
+func sendRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		common.SendErrorResponseWithDetails(w, http.StatusNotFound, "Not found", err.Error())
+	case strings.Contains(err.Error(), "invalid"):
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Invalid request", err.Error())
+	default:
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
@@ -3,35 +3,38 @@ package authhandler
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"github.com/wafi04/golang-backend/services/common"
-	"github.com/wafi04/golang-backend/services/common/middleware"
 )
 
 
+// HandleRefreshToken exchanges a refresh token for a new access token. It is
+// a public route: the access token may already have expired. The refresh
+// token is rotated, so clients must keep the one returned.
 func (s *AuthHandler) HandleRefreshToken(w http.ResponseWriter, r *http.Request) {
     s.logger.Log(common.InfoLevel, "Handle Refresh Token incoming")
 
-    session := r.URL.Query().Get("session")
-    refreshToken := r.URL.Query().Get("token")
-
-    if session == "" || refreshToken == "" {
-        http.Error(w, "Invalid session or token", http.StatusBadRequest)
-        return
+    var req struct {
+        RefreshToken string `json:"refresh_token"`
+        SessionID    string `json:"session_id"`
     }
-
-    _, err := middleware.GetUserFromContext(r.Context())
-    if err != nil {
-        http.Error(w, "Unauthorized", http.StatusUnauthorized)
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+        http.Error(w, "refresh_token is required", http.StatusBadRequest)
         return
     }
 
     refreshResp, err := s.authClient.RefreshToken(r.Context(), &pb.RefreshTokenRequest{
-    	RefreshToken: refreshToken,
-        SessionId:    session,
+        RefreshToken: req.RefreshToken,
+        SessionId:    req.SessionID,
     })
     if err != nil {
+        s.logger.Log(common.ErrorLevel, "Token refresh failed: %v", err)
+        if strings.Contains(err.Error(), "refresh token") {
+            http.Error(w, "Invalid or expired refresh token", http.StatusUnauthorized)
+            return
+        }
         http.Error(w, "Token refresh failed", http.StatusInternalServerError)
         return
     }
@@ -45,4 +48,4 @@ func (s *AuthHandler) HandleRefreshToken(w http.ResponseWriter, r *http.Request)
     w.Header().Set("Content-Type", "application/json")
     w.WriteHeader(http.StatusOK)
     json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
@@ -12,11 +12,13 @@ import (
 	"github.com/wafi04/golang-backend/services/common"
 	"github.com/wafi04/golang-backend/services/common/middleware"
 	"github.com/wafi04/golang-backend/services/gateway/server/config"
+	"google.golang.org/grpc/status"
 )
 
 type AuthHandler struct {
 	authClient pb.AuthServiceClient
 	logger     common.Logger
+	keys       *middleware.KeyCache
 }
 
 func NewGateway(ctx context.Context) (*AuthHandler, error) {
@@ -25,9 +27,15 @@ func NewGateway(ctx context.Context) (*AuthHandler, error) {
 		return nil, err
 	}
 
-	return &AuthHandler{
+	h := &AuthHandler{
 		authClient: pb.NewAuthServiceClient(conn),
-	}, nil
+	}
+	h.keys = middleware.NewKeyCache(h.fetchJWKS, jwksTTL)
+	// Access tokens are verified against the auth service's public keys.
+	middleware.SetVerificationKeys(h.keys)
+	// Service accounts authenticate with API keys the auth service issues.
+	middleware.SetAPIKeyValidator(middleware.NewAPIKeyCache(h, apiKeyCacheTTL))
+	return h, nil
 }
 
 func (h *AuthHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
@@ -44,7 +52,7 @@ func (h *AuthHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("Decoded request: %+v", &req)
+	log.Printf("Decoded create user request for %s", req.Email)
 	clientIP := common.GetClientIP(r)
 	userAgent := r.UserAgent()
 
@@ -65,7 +73,7 @@ func (h *AuthHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("Received response from auth service: %+v", resp)
+	log.Printf("Created user %s", resp.UserId)
 
 	w.Header().Set("Content-Type", "application/json")
 
@@ -113,19 +121,29 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Login failed: %v", err)
 
+		// Unknown emails answer like wrong passwords, so the response does
+		// not reveal whether an account exists.
 		switch {
 		case strings.Contains(err.Error(), "invalid credentials"):
 			http.Error(w, "Invalid email or password", http.StatusUnauthorized)
 		case strings.Contains(err.Error(), "account is deactivated"):
 			http.Error(w, "Account is deactivated", http.StatusForbidden)
-		case strings.Contains(err.Error(), "user not found"):
-			http.Error(w, "User not found", http.StatusNotFound)
+		case strings.Contains(err.Error(), "too many login attempts"),
+			strings.Contains(err.Error(), "account temporarily locked"):
+			http.Error(w, status.Convert(err).Message(), http.StatusTooManyRequests)
 		default:
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
 		return
 	}
 
+	// With 2FA enabled the password only earns a challenge, to be
+	// completed at /auth/login/mfa.
+	if resp.MfaRequired {
+		common.SendSuccessResponse(w, http.StatusOK, "Two-factor authentication required", resp)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Authorization", fmt.Sprintf("Bearer %s", resp.AccessToken))
 
@@ -167,20 +185,24 @@ func (h *AuthHandler) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
 
 func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
 	user, err := middleware.GetUserFromContext(r.Context())
-	token := r.URL.Query().Get("token")
-
 	if err != nil {
-		common.Error(http.StatusUnauthorized, "Unauthorized")
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sessionID, err := middleware.GetSessionIDFromContext(r.Context())
+	if err != nil {
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
 
 	logout, err := h.authClient.Logout(r.Context(), &pb.LogoutRequest{
-		AccessToken: token,
-		UserId:      user.UserId,
+		SessionId: sessionID,
+		UserId:    user.UserId,
 	})
 
 	if err != nil {
-		common.Error(http.StatusUnauthorized, "Unauthorized")
+		log.Printf("Logout failed: %v", err)
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Failed to log out")
 		return
 	}
 
@@ -190,3 +212,23 @@ func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// HandleLogoutAll ends every session of the current user, on all devices.
+func (h *AuthHandler) HandleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	user, err := middleware.GetUserFromContext(r.Context())
+	if err != nil {
+		common.SendErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authClient.LogoutAll(r.Context(), &pb.LogoutAllRequest{
+		UserId: user.UserId,
+	})
+	if err != nil {
+		log.Printf("Logout all failed: %v", err)
+		common.SendErrorResponse(w, http.StatusInternalServerError, "Failed to log out")
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Logged out of all sessions", resp)
+}
@@ -0,0 +1,62 @@
+package categoryhandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// HandleGetCategoryAttributes returns the effective attribute schema of a
+// category, including the attributes inherited from its ancestors.
+func (h *CategoryHandler) HandleGetCategoryAttributes(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	res, err := h.categoryClient.GetCategoryAttributes(r.Context(), &pb.GetCategoryRequest{Id: id})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, "Category not found")
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get Category Attributes Success", res)
+}
+
+// HandleSetCategoryAttributes replaces the attributes defined on the category
+// itself.
+func (h *CategoryHandler) HandleSetCategoryAttributes(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req struct {
+		Attributes []*pb.CategoryAttribute `json:"attributes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.categoryClient.SetCategoryAttributes(r.Context(), &pb.SetCategoryAttributesRequest{
+		CategoryId: id,
+		Attributes: req.Attributes,
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, "Category not found")
+		case strings.Contains(err.Error(), "invalid"):
+			common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Invalid attributes", err.Error())
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Set Category Attributes Success", res)
+}
@@ -109,7 +109,9 @@ func (h *CategoryHandler) HandleCreateCategory(w http.ResponseWriter, r *http.Re
 	}
 }
 func (h *CategoryHandler) HandleGetCategories(w http.ResponseWriter, r *http.Request) {
-	categories, err := h.categoryClient.ListCategories(r.Context(), &pb.ListCategoriesRequest{})
+	categories, err := h.categoryClient.ListCategories(r.Context(), &pb.ListCategoriesRequest{
+		Locale: common.RequestLocale(w, r),
+	})
 	if err != nil {
 		common.Error(http.StatusBadRequest, "Failed to retrieve categories")
 		return
@@ -136,6 +138,7 @@ func (h *CategoryHandler) HandleListCategories(w http.ResponseWriter, r *http.Re
 		Page:            int32(page),
 		Limit:           int32(limit),
 		IncludeChildren: includeChildren,
+		Locale:          common.RequestLocale(w, r),
 	}
 
 	if parentID != "" {
@@ -149,7 +152,6 @@ func (h *CategoryHandler) HandleListCategories(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
 	response := common.Success(map[string]interface{}{
 		"categories": resp.Categories,
 		"total":      resp.Total,
@@ -157,11 +159,15 @@ func (h *CategoryHandler) HandleListCategories(w http.ResponseWriter, r *http.Re
 		"limit":      limit,
 	}, "Categories retrieved successfully")
 
-	if err = json.NewEncoder(w).Encode(response); err != nil {
+	body, err := json.Marshal(response)
+	if err != nil {
 		log.Printf("Error encoding response: %v", err)
 		http.Error(w, "Error encoding response", http.StatusInternalServerError)
 		return
 	}
+
+	// Nav rendering revalidates the tree on every page view.
+	common.WriteWithETag(w, r, body)
 }
 
 func (h *CategoryHandler) HandleUpdateCategory(w http.ResponseWriter, r *http.Request) {
@@ -226,9 +232,17 @@ func (h *CategoryHandler) HandleDeleteCategory(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// orphan_products decides what happens to the category's products:
+	// block (default), parent, category (with target_category_id) or
+	// uncategorized.
+	query := r.URL.Query()
 	updateReq := &pb.DeleteCategoryRequest{
 		Id:             id,
-		DeleteChildren: false,
+		DeleteChildren: query.Get("delete_children") == "true",
+		OrphanProducts: query.Get("orphan_products"),
+	}
+	if target := query.Get("target_category_id"); target != "" {
+		updateReq.TargetCategoryId = &target
 	}
 
 	ctx := r.Context()
@@ -239,6 +253,8 @@ func (h *CategoryHandler) HandleDeleteCategory(w http.ResponseWriter, r *http.Re
 			http.Error(w, err.Error(), http.StatusNotFound)
 		case strings.Contains(err.Error(), "invalid"):
 			http.Error(w, err.Error(), http.StatusBadRequest)
+		case strings.Contains(err.Error(), "cannot delete"):
+			http.Error(w, err.Error(), http.StatusConflict)
 		default:
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
 		}
@@ -251,3 +267,33 @@ func (h *CategoryHandler) HandleDeleteCategory(w http.ResponseWriter, r *http.Re
 		return
 	}
 }
+
+func (h *CategoryHandler) HandleGetCategoryBySlug(w http.ResponseWriter, r *http.Request) {
+	slug, ok := mux.Vars(r)["slug"]
+	if !ok {
+		http.Error(w, "Category slug is required", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryClient.GetCategoryBySlug(r.Context(), &pb.GetCategoryBySlugRequest{
+		Slug:   slug,
+		Locale: common.RequestLocale(w, r),
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, "Category not found")
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	// An old slug resolves to the renamed category; send clients to the current URL.
+	if category.Slug != slug {
+		http.Redirect(w, r, "/api/v1/category/slug/"+category.Slug, http.StatusMovedPermanently)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get Category Success", category)
+}
@@ -0,0 +1,183 @@
+package categoryhandler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"google.golang.org/grpc"
+)
+
+func (h *CategoryHandler) HandleGetCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Category ID is required", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryClient.GetCategory(r.Context(), &pb.GetCategoryRequest{
+		Id:     id,
+		Locale: common.RequestLocale(w, r),
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, "Category not found")
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get Category Success", category)
+}
+
+func (h *CategoryHandler) HandleGetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Category ID is required", http.StatusBadRequest)
+		return
+	}
+
+	tree, err := h.categoryClient.GetCategoryHierarchy(r.Context(), &pb.GetCategoryRequest{
+		Id:     id,
+		Locale: common.RequestLocale(w, r),
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, "Category not found")
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get Category Tree Success", tree)
+}
+
+func (h *CategoryHandler) HandleMoveCategory(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Category ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var request struct {
+		ParentID *string `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	category, err := h.categoryClient.MoveCategory(r.Context(), &pb.MoveCategoryRequest{
+		Id:          id,
+		NewParentId: request.ParentID,
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, err.Error())
+		case strings.Contains(err.Error(), "invalid"):
+			common.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Move Category Success", category)
+}
+
+func (h *CategoryHandler) HandleListCategoryAncestors(w http.ResponseWriter, r *http.Request) {
+	h.handleCategoryList(w, r, h.categoryClient.ListCategoryAncestors, "Get Category Ancestors Success")
+}
+
+func (h *CategoryHandler) HandleListCategoryDescendants(w http.ResponseWriter, r *http.Request) {
+	h.handleCategoryList(w, r, h.categoryClient.ListCategoryDescendants, "Get Category Descendants Success")
+}
+
+func (h *CategoryHandler) handleCategoryList(
+	w http.ResponseWriter,
+	r *http.Request,
+	list func(ctx context.Context, in *pb.GetCategoryRequest, opts ...grpc.CallOption) (*pb.ListCategoriesResponse, error),
+	message string,
+) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Category ID is required", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := list(r.Context(), &pb.GetCategoryRequest{
+		Id:     id,
+		Locale: common.RequestLocale(w, r),
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, "Category not found")
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, message, categories)
+}
+
+func (h *CategoryHandler) HandleGetCategoryBreadcrumb(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Category ID is required", http.StatusBadRequest)
+		return
+	}
+
+	breadcrumb, err := h.categoryClient.GetCategoryBreadcrumb(r.Context(), &pb.GetCategoryRequest{
+		Id:     id,
+		Locale: common.RequestLocale(w, r),
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			common.SendErrorResponse(w, http.StatusNotFound, "Category not found")
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get Category Breadcrumb Success", breadcrumb)
+}
+
+func (h *CategoryHandler) HandleReorderCategories(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ParentID    *string  `json:"parent_id"`
+		CategoryIDs []string `json:"category_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	categories, err := h.categoryClient.ReorderCategories(r.Context(), &pb.ReorderCategoriesRequest{
+		ParentId:    request.ParentID,
+		CategoryIds: request.CategoryIDs,
+	})
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "invalid"):
+			common.SendErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			common.SendErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		}
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Reorder Categories Success", categories)
+}
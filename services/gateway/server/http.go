@@ -46,6 +46,12 @@ func init() {
 	prometheus.MustRegister(gauge)
 }
 
+// require wraps a handler so that it only runs for users holding all of
+// perms. Use it on protected routes.
+func require(h http.HandlerFunc, perms ...string) http.HandlerFunc {
+	return middleware.RequirePermissions(perms...)(h).ServeHTTP
+}
+
 func SetupRoutes(
 	authGateway *authhandler.AuthHandler,
 	categoryGateway *categoryhandler.CategoryHandler,
@@ -62,7 +68,7 @@ func SetupRoutes(
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "http://192.168.100.81:3000")
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 			if r.Method == "OPTIONS" {
@@ -78,6 +84,7 @@ func SetupRoutes(
 	// API routes
 	api := r.PathPrefix("/api/v1").Subrouter()
 	r.Handle("/metrics", promhttp.Handler())
+	r.HandleFunc("/.well-known/jwks.json", authGateway.HandleJWKS).Methods("GET")
 	public := api.PathPrefix("").Subrouter()
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		healthStatus := true
@@ -99,6 +106,15 @@ func SetupRoutes(
 	}).Methods("GET")
 	public.HandleFunc("/auth/register", authGateway.HandleCreateUser).Methods("POST", "OPTIONS")
 	public.HandleFunc("/auth/login", authGateway.HandleLogin).Methods("POST", "OPTIONS")
+	public.HandleFunc("/auth/login/mfa", authGateway.HandleVerifyLoginMFA).Methods("POST", "OPTIONS")
+	public.HandleFunc("/auth/login/link", authGateway.HandleRequestMagicLink).Methods("POST", "OPTIONS")
+	public.HandleFunc("/auth/login/link/consume", authGateway.HandleConsumeMagicLink).Methods("POST", "OPTIONS")
+	public.HandleFunc("/auth/password/forgot", authGateway.HandleRequestPasswordReset).Methods("POST", "OPTIONS")
+	public.HandleFunc("/auth/password/reset", authGateway.HandleResetPassword).Methods("POST", "OPTIONS")
+	public.HandleFunc("/auth/oidc/providers", authGateway.HandleListOIDCProviders).Methods("GET", "OPTIONS")
+	public.HandleFunc("/auth/oidc/{provider}/login", authGateway.HandleOIDCLogin).Methods("GET", "OPTIONS")
+	public.HandleFunc("/auth/oidc/{provider}/callback", authGateway.HandleOIDCCallback).Methods("GET", "OPTIONS")
+	public.HandleFunc("/auth/refresh-token", authGateway.HandleRefreshToken).Methods("POST", "OPTIONS")
 
 	// Protected routes
 	protected := api.PathPrefix("").Subrouter()
@@ -106,36 +122,74 @@ func SetupRoutes(
 	// Auth protected routes
 	protected.HandleFunc("/auth/profile", authGateway.HandleGetProfile).Methods("GET", "OPTIONS")
 	protected.HandleFunc("/auth/logout", authGateway.HandleLogout).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/auth/logout-all", authGateway.HandleLogoutAll).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/auth/verification-email", authGateway.HandleVerifyEmail).Methods("POST", "OPTIONS")
-	protected.HandleFunc("/auth/refresh-token", authGateway.HandleRefreshToken).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/auth/resend-verification", authGateway.HandleResendVerification).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/auth/list-sessions", authGateway.HandlerListSessions).Methods("GET", "OPTIONS")
 	protected.HandleFunc("/auth/revoke-session/{id}", authGateway.HandleRevokeSessions).Methods("DELETE", "OPTIONS")
-
-	// Category protected routes
-	public.HandleFunc("/category", categoryGateway.HandleCreateCategory).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/auth/mfa/enroll", authGateway.HandleEnrollMFA).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/auth/mfa/confirm", authGateway.HandleConfirmMFA).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/auth/mfa/disable", authGateway.HandleDisableMFA).Methods("POST", "OPTIONS")
+
+	// Role administration
+	protected.HandleFunc("/admin/roles", require(authGateway.HandleListRoles, middleware.PermRolesManage)).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/admin/roles/{name}", require(authGateway.HandleUpsertRole, middleware.PermRolesManage)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/admin/users/{id}/roles", require(authGateway.HandleGetUserRoles, middleware.PermRolesManage)).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/admin/users/{id}/roles", require(authGateway.HandleAssignRole, middleware.PermRolesManage)).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/admin/users/{id}/roles/{role}", require(authGateway.HandleRevokeRole, middleware.PermRolesManage)).Methods("DELETE", "OPTIONS")
+	protected.HandleFunc("/admin/users/{id}/unlock", require(authGateway.HandleUnlockAccount, middleware.PermUsersManage)).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/admin/api-keys", require(authGateway.HandleListAPIKeys, middleware.PermAPIKeysManage)).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/admin/api-keys", require(authGateway.HandleCreateAPIKey, middleware.PermAPIKeysManage)).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/admin/api-keys/{id}", require(authGateway.HandleRevokeAPIKey, middleware.PermAPIKeysManage)).Methods("DELETE", "OPTIONS")
+
+	// Category routes; writes need catalog:write
+	protected.HandleFunc("/category", require(categoryGateway.HandleCreateCategory, middleware.PermCatalogWrite)).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/category", categoryGateway.HandleGetCategories).Methods("GET", "OPTIONS")
 	public.HandleFunc("/list-categories", categoryGateway.HandleListCategories).Methods("GET", "OPTIONS")
-	protected.HandleFunc("/category/{id}", categoryGateway.HandleUpdateCategory).Methods("PUT", "OPTIONS")
-	protected.HandleFunc("/category/{id}", categoryGateway.HandleDeleteCategory).Methods("DELETE", "OPTIONS")
+	public.HandleFunc("/category/slug/{slug}", categoryGateway.HandleGetCategoryBySlug).Methods("GET", "OPTIONS")
+	public.HandleFunc("/category/{id}", categoryGateway.HandleGetCategory).Methods("GET", "OPTIONS")
+	public.HandleFunc("/category/{id}/tree", categoryGateway.HandleGetCategoryTree).Methods("GET", "OPTIONS")
+	public.HandleFunc("/category/{id}/ancestors", categoryGateway.HandleListCategoryAncestors).Methods("GET", "OPTIONS")
+	public.HandleFunc("/category/{id}/descendants", categoryGateway.HandleListCategoryDescendants).Methods("GET", "OPTIONS")
+	public.HandleFunc("/category/{id}/breadcrumb", categoryGateway.HandleGetCategoryBreadcrumb).Methods("GET", "OPTIONS")
+	public.HandleFunc("/category/{id}/attributes", categoryGateway.HandleGetCategoryAttributes).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/category/order", require(categoryGateway.HandleReorderCategories, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/category/{id}", require(categoryGateway.HandleUpdateCategory, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/category/{id}/move", require(categoryGateway.HandleMoveCategory, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/category/{id}", require(categoryGateway.HandleDeleteCategory, middleware.PermCatalogWrite)).Methods("DELETE", "OPTIONS")
+	protected.HandleFunc("/category/{id}/translations/{locale}", require(categoryGateway.HandleUpsertCategoryTranslation, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/category/{id}/attributes", require(categoryGateway.HandleSetCategoryAttributes, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
 
 	// UploadFile
-	protected.HandleFunc("/upload", fileGateway.HandleUploadFile).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/upload", require(fileGateway.HandleUploadFile, middleware.PermCatalogWrite)).Methods("POST", "OPTIONS")
 
 	// products
-	protected.HandleFunc("/create-product", productGateway.HandleCreateProduct).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/create-product", require(productGateway.HandleCreateProduct, middleware.PermCatalogWrite)).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/product/{id}", productGateway.HandleGetProduct).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/product/{id}", require(productGateway.HandleUpdateProduct, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	public.HandleFunc("/product/slug/{slug}", productGateway.HandleGetProductBySlug).Methods("GET", "OPTIONS")
 	protected.HandleFunc("/product", productGateway.HandleListProducts).Methods("GET", "OPTIONS")
+	public.HandleFunc("/product/{id}/recommendations", productGateway.HandleGetRecommendations).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/product/{id}/related", require(productGateway.HandleSetRelatedProducts, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/product/{id}/translations/{locale}", require(productGateway.HandleUpsertProductTranslation, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
 	// variants
-	protected.HandleFunc("/product/{id}/variants", productGateway.HandleCreateVariants).Methods("POST", "OPTIONS")
-	protected.HandleFunc("/product/variants/{id}", productGateway.HandleUpdateVariants).Methods("PUT", "OPTIONS")
-	protected.HandleFunc("/product/variants/{id}", productGateway.HandleDeleteVariants).Methods("DELETE", "OPTIONS")
+	protected.HandleFunc("/product/{id}/variants", require(productGateway.HandleCreateVariants, middleware.PermCatalogWrite)).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/product/variants/{id}", require(productGateway.HandleUpdateVariants, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/product/variants/{id}", require(productGateway.HandleDeleteVariants, middleware.PermCatalogWrite)).Methods("DELETE", "OPTIONS")
 
 	// images
-	protected.HandleFunc("/product/images/{id}", productGateway.HandleAddProductImage).Methods("POST", "OPTIONS")
-	protected.HandleFunc("/product/images/{id}", productGateway.HandleDeleteProductImage).Methods("DELETE", "OPTIONS")
-
-	protected.HandleFunc("/stock", stockGateway.HandleCreateStock).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/product/images/{id}", require(productGateway.HandleAddProductImage, middleware.PermCatalogWrite)).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/product/images/{id}", require(productGateway.HandleUpdateProductImage, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+	protected.HandleFunc("/product/images/{id}", require(productGateway.HandleDeleteProductImage, middleware.PermCatalogWrite)).Methods("DELETE", "OPTIONS")
+	protected.HandleFunc("/product/variants/{id}/images/order", require(productGateway.HandleReorderProductImages, middleware.PermCatalogWrite)).Methods("PUT", "OPTIONS")
+
+	// bundles
+	protected.HandleFunc("/bundle", require(productGateway.HandleCreateBundle, middleware.PermCatalogWrite)).Methods("POST", "OPTIONS")
+	protected.HandleFunc("/bundle", productGateway.HandleListBundles).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/bundle/{id}", productGateway.HandleGetBundle).Methods("GET", "OPTIONS")
+	protected.HandleFunc("/bundle/{id}", require(productGateway.HandleDeleteBundle, middleware.PermCatalogWrite)).Methods("DELETE", "OPTIONS")
+
+	protected.HandleFunc("/stock", require(stockGateway.HandleCreateStock, middleware.PermStockAdjust)).Methods("POST", "OPTIONS")
 	protected.HandleFunc("/stock/{id}", stockGateway.HandleCheckAvaibility).Methods("GET", "OPTIONS")
 
 	protected.HandleFunc("/order", orderGateway.HandleCreateOrder).Methods("POST", "OPTIONS")
@@ -24,6 +24,9 @@ func containsString(s, substr string) bool {
 func isInsufficientStockError(err error) bool {
 	return err != nil && containsString(err.Error(), "insufficient stock")
 }
+func isBusyError(err error) bool {
+	return err != nil && containsString(err.Error(), "is busy, retry")
+}
 func isValidationError(err error) bool {
 	return err != nil && (containsString(err.Error(), "invalid") ||
 		containsString(err.Error(), "required") ||
@@ -55,6 +58,7 @@ func (h *OrderHandler) HandleCreateOrder(w http.ResponseWriter, r *http.Request)
 	var req struct {
 		Qty       int64  `json:"quantity"`
 		Varinatid string `json:"variant_id"`
+		BundleId  string `json:"bundle_id"`
 	}
 	log.Printf("cahange")
 
@@ -68,17 +72,28 @@ func (h *OrderHandler) HandleCreateOrder(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	order, err := h.orderService.CreateOrder(r.Context(), &pb.CreateOrderRequest{
+	orderReq := &pb.CreateOrderRequest{
 		Quantity:   req.Qty,
 		VariantsId: req.Varinatid,
 		Total:      float64(req.Qty) * 8,
 		UserId:     user.UserId,
-	})
+	}
+
+	// The order service takes the components and price of a bundle from the
+	// product service.
+	if req.BundleId != "" {
+		orderReq.VariantsId = ""
+		orderReq.BundleId = req.BundleId
+	}
+
+	order, err := h.orderService.CreateOrder(r.Context(), orderReq)
 	if err != nil {
 		log.Printf("Error creating order: %v", err)
 		switch {
 		case isInsufficientStockError(err):
 			common.SendErrorResponseWithDetails(w, http.StatusConflict, "Insufficient stock ", err.Error())
+		case isBusyError(err):
+			common.SendErrorResponseWithDetails(w, http.StatusConflict, "Variant is busy, retry", err.Error())
 		case isValidationError(err):
 			common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Validation error", err.Error())
 		default:
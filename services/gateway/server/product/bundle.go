@@ -0,0 +1,135 @@
+package producthandler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+type BundleRequest struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Sku         string  `json:"sku"`
+	Price       float64 `json:"price"`
+	Items       []struct {
+		VariantId string `json:"variant_id"`
+		Quantity  int64  `json:"quantity"`
+	} `json:"items"`
+}
+
+func (h *ProductHandler) HandleCreateBundle(w http.ResponseWriter, r *http.Request) {
+	var req BundleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Sku == "" {
+		req.Sku = common.GenerateSku(req.Name)
+	} else if !common.IsSkuValid(req.Sku) {
+		common.SendErrorResponse(w, http.StatusBadRequest, "Invalid SKU format")
+		return
+	}
+
+	bundle := &pb.Bundle{
+		Name:        req.Name,
+		Description: req.Description,
+		Sku:         req.Sku,
+		Price:       req.Price,
+	}
+	for _, item := range req.Items {
+		bundle.Items = append(bundle.Items, &pb.BundleItem{
+			VariantId: item.VariantId,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	resp, err := h.productclient.CreateBundle(r.Context(), &pb.CreateBundleRequest{
+		Bundle: bundle,
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Failed to create bundle", err.Error())
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusCreated, "Created bundle successfully", resp)
+}
+
+func (h *ProductHandler) HandleGetBundle(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Bundle ID is required", http.StatusBadRequest)
+		return
+	}
+
+	bundle, err := h.productclient.GetBundle(r.Context(), &pb.GetBundleRequest{
+		Id: id,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			common.SendErrorResponse(w, http.StatusNotFound, "Bundle not found")
+			return
+		}
+		common.SendErrorResponseWithDetails(w, http.StatusInternalServerError, "Failed to get bundle", err.Error())
+		return
+	}
+
+	components := make([]*pb.StockComponent, 0, len(bundle.Items))
+	for _, item := range bundle.Items {
+		components = append(components, &pb.StockComponent{
+			VariantId: item.VariantId,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	availability, err := h.stockClient.CheckBundleAvailability(r.Context(), &pb.CheckBundleAvailabilityRequest{
+		Components:        components,
+		RequestedQuantity: 1,
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusInternalServerError, "Failed to check bundle availability", err.Error())
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get bundle successfully", map[string]interface{}{
+		"bundle":       bundle,
+		"availability": availability,
+	})
+}
+
+func (h *ProductHandler) HandleListBundles(w http.ResponseWriter, r *http.Request) {
+	res, err := h.productclient.ListBundles(r.Context(), &pb.ListBundlesRequest{
+		PageSize:  10,
+		PageToken: r.URL.Query().Get("page"),
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusInternalServerError, "Failed to get bundles", err.Error())
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get bundles successfully", res)
+}
+
+func (h *ProductHandler) HandleDeleteBundle(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Bundle ID is required", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.productclient.DeleteBundle(r.Context(), &pb.DeleteBundleRequest{
+		Id: id,
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Failed to delete bundle", err.Error())
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Delete bundle successfully", res)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/wafi04/golang-backend/grpc/pb"
@@ -16,6 +17,7 @@ import (
 type ProductHandler struct {
 	productclient pb.ProductServiceClient
 	filesClient   pb.FileServiceClient
+	stockClient   pb.StockServiceClient
 }
 
 func NewProductGateway(ctx context.Context) (*ProductHandler, error) {
@@ -32,9 +34,15 @@ func NewProductGateway(ctx context.Context) (*ProductHandler, error) {
 		return nil, err
 	}
 
+	connStock, err := config.ConnectWithRetry(config.Load().StockServiceURL, "stock")
+	if err != nil {
+		return nil, err
+	}
+
 	return &ProductHandler{
 		productclient: pb.NewProductServiceClient(conn),
 		filesClient:   pb.NewFileServiceClient(connFile),
+		stockClient:   pb.NewStockServiceClient(connStock),
 	}, nil
 }
 
@@ -98,7 +106,8 @@ func (h *ProductHandler) HandleGetProduct(w http.ResponseWriter, r *http.Request
 	}
 
 	res, err := h.productclient.GetProduct(r.Context(), &pb.GetProductRequest{
-		Id: id,
+		Id:     id,
+		Locale: common.RequestLocale(w, r),
 	})
 
 	if err != nil {
@@ -116,6 +125,49 @@ func (h *ProductHandler) HandleGetProduct(w http.ResponseWriter, r *http.Request
 
 }
 
+// HandleUpdateProduct changes the fields sent in the body and keeps the
+// others.
+func (h *ProductHandler) HandleUpdateProduct(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		http.Error(w, "Product ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Name        *string  `json:"name"`
+		SubTitle    *string  `json:"sub_title"`
+		Description *string  `json:"description"`
+		Sku         *string  `json:"sku"`
+		Price       *float64 `json:"price"`
+		CategoryId  *string  `json:"category_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Sku != nil && !common.IsSkuValid(*req.Sku) {
+		common.SendErrorResponse(w, http.StatusBadRequest, "Invalid SKU")
+		return
+	}
+
+	product, err := h.productclient.UpdateProduct(r.Context(), &pb.UpdateProductRequest{
+		Id:          id,
+		Name:        req.Name,
+		SubTitle:    req.SubTitle,
+		Description: req.Description,
+		Sku:         req.Sku,
+		Price:       req.Price,
+		CategoryId:  req.CategoryId,
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Failed to update product", err.Error())
+		return
+	}
+	common.SendSuccessResponse(w, http.StatusOK, "Update product successfully", product)
+}
+
 func (h *ProductHandler) HandleListProducts(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Received get product request: %s %s", r.Method, r.URL.Path)
 	w.Header().Set("Content-Type", "application/json")
@@ -126,6 +178,7 @@ func (h *ProductHandler) HandleListProducts(w http.ResponseWriter, r *http.Reque
 	req := &pb.ListProductsRequest{
 		PageSize:  10,
 		PageToken: "0",
+		Locale:    common.RequestLocale(w, r),
 	}
 
 	res, err := h.productclient.ListProducts(r.Context(), req)
@@ -145,3 +198,35 @@ func (h *ProductHandler) HandleListProducts(w http.ResponseWriter, r *http.Reque
 	}
 
 }
+
+func (h *ProductHandler) HandleGetProductBySlug(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Received get product by slug request: %s %s", r.Method, r.URL.Path)
+
+	slug, ok := mux.Vars(r)["slug"]
+	if !ok {
+		http.Error(w, "Product slug is required", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.productclient.GetProductBySlug(r.Context(), &pb.GetProductBySlugRequest{
+		Slug:   slug,
+		Locale: common.RequestLocale(w, r),
+	})
+	if err != nil {
+		log.Printf("Failed to get Product: %v", err)
+		if strings.Contains(err.Error(), "not found") {
+			common.SendErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		common.SendErrorResponseWithDetails(w, http.StatusInternalServerError, "Failed to get products", err.Error())
+		return
+	}
+
+	// An old slug resolves to the renamed product; send clients to the current URL.
+	if res.Slug != slug {
+		http.Redirect(w, r, "/api/v1/product/slug/"+res.Slug, http.StatusMovedPermanently)
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get product successfully", res)
+}
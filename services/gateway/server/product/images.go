@@ -1,6 +1,7 @@
 package producthandler
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"math/rand"
@@ -56,10 +57,18 @@ func (p *ProductHandler) HandleAddProductImage(w http.ResponseWriter, r *http.Re
 		imageUrlPtr = &imageUrl
 	}
 
+	if imageUrlPtr == nil {
+		common.SendErrorResponse(w, http.StatusBadRequest, "Image file is required")
+		return
+	}
+
+	// The product service makes the first image of a variant main on its own.
+	isMain := r.FormValue("is_main") == "true"
+
 	productImage, err := p.productclient.AddProductImage(r.Context(), &pb.AddProductImageRequest{
 		VariantId: VariantId,
 		Url:       *imageUrlPtr,
-		IsMain:    true,
+		IsMain:    isMain,
 	})
 	if err != nil {
 		common.SendErrorResponse(w, http.StatusBadRequest, "Failed to created product image")
@@ -86,3 +95,60 @@ func (p *ProductHandler) HandleDeleteProductImage(w http.ResponseWriter, r *http
 	}
 	common.SendSuccessResponse(w, http.StatusOK, "Delete Product Image succesfully", productImage)
 }
+
+func (p *ProductHandler) HandleUpdateProductImage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	ProductImageId, ok := vars["id"]
+	if !ok {
+		http.Error(w, "Product images Id is required", http.StatusBadRequest)
+		return
+	}
+
+	// Fields left out of the body keep their current value.
+	var req struct {
+		Url    *string `json:"url"`
+		IsMain *bool   `json:"is_main"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	productImage, err := p.productclient.UpdateProductImage(r.Context(), &pb.UpdateProductImageRequest{
+		Id:     ProductImageId,
+		Url:    req.Url,
+		IsMain: req.IsMain,
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Failed to update product image", err.Error())
+		return
+	}
+	common.SendSuccessResponse(w, http.StatusOK, "Update Product Image succesfully", productImage)
+}
+
+func (p *ProductHandler) HandleReorderProductImages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	VariantId, ok := vars["id"]
+	if !ok {
+		http.Error(w, "Product Variants Id is required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		ImageIds []string `json:"image_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	images, err := p.productclient.ReorderProductImages(r.Context(), &pb.ReorderProductImagesRequest{
+		VariantId: VariantId,
+		ImageIds:  req.ImageIds,
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Failed to reorder product images", err.Error())
+		return
+	}
+	common.SendSuccessResponse(w, http.StatusOK, "Reorder Product Images succesfully", images)
+}
@@ -0,0 +1,60 @@
+package producthandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+func (h *ProductHandler) HandleGetRecommendations(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Product ID is required", http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	res, err := h.productclient.GetRecommendations(r.Context(), &pb.GetRecommendationsRequest{
+		ProductId: id,
+		Limit:     int32(limit),
+		Locale:    common.RequestLocale(w, r),
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusInternalServerError, "Failed to get recommendations", err.Error())
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Get recommendations successfully", res)
+}
+
+func (h *ProductHandler) HandleSetRelatedProducts(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		http.Error(w, "Product ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		RelatedProductIds []string `json:"related_product_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.productclient.SetRelatedProducts(r.Context(), &pb.SetRelatedProductsRequest{
+		ProductId:         id,
+		RelatedProductIds: req.RelatedProductIds,
+	})
+	if err != nil {
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Failed to set related products", err.Error())
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Set related products successfully", res)
+}
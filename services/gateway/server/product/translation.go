@@ -0,0 +1,41 @@
+package producthandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+func (h *ProductHandler) HandleUpsertProductTranslation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.productclient.UpsertProductTranslation(r.Context(), &pb.ProductTranslation{
+		ProductId:   vars["id"],
+		Locale:      vars["locale"],
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			common.SendErrorResponse(w, http.StatusNotFound, "Product not found")
+			return
+		}
+		common.SendErrorResponseWithDetails(w, http.StatusBadRequest, "Failed to save translation", err.Error())
+		return
+	}
+
+	common.SendSuccessResponse(w, http.StatusOK, "Save translation successfully", res)
+}
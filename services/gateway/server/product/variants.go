@@ -12,8 +12,9 @@ import (
 
 func (h *ProductHandler) HandleCreateVariants(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Color string `json:"color"`
-		Sku   string `json:"sku"`
+		Color      string            `json:"color"`
+		Sku        string            `json:"sku"`
+		Attributes map[string]string `json:"attributes"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -36,9 +37,10 @@ func (h *ProductHandler) HandleCreateVariants(w http.ResponseWriter, r *http.Req
 	}
 
 	variants, err := h.productclient.CreateProductVariant(r.Context(), &pb.CreateProductVariantRequest{
-		ProductId: id,
-		Color:     req.Color,
-		Sku:       req.Sku,
+		ProductId:  id,
+		Color:      req.Color,
+		Sku:        req.Sku,
+		Attributes: req.Attributes,
 	})
 
 	if err != nil {
@@ -52,9 +54,10 @@ func (h *ProductHandler) HandleCreateVariants(w http.ResponseWriter, r *http.Req
 func (p *ProductHandler) HandleUpdateVariants(w http.ResponseWriter, r *http.Request) {
 
 	var req struct {
-		Color     string `json:"color"`
-		ProductID string `json:"product_id"`
-		Sku       string `json:"sku"`
+		Color      string            `json:"color"`
+		ProductID  string            `json:"product_id"`
+		Sku        string            `json:"sku"`
+		Attributes map[string]string `json:"attributes"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("Error decoding request body: %v", err)
@@ -77,10 +80,11 @@ func (p *ProductHandler) HandleUpdateVariants(w http.ResponseWriter, r *http.Req
 
 	update, err := p.productclient.UpdateProductVariant(r.Context(), &pb.UpdateProductVariantRequest{
 		Variant: &pb.ProductVariant{
-			Id:        id,
-			Color:     req.Color,
-			Sku:       req.Sku,
-			ProductId: req.ProductID,
+			Id:         id,
+			Color:      req.Color,
+			Sku:        req.Sku,
+			ProductId:  req.ProductID,
+			Attributes: req.Attributes,
 		},
 	})
 
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// createBundleOrder decrements the stock of every bundle component in one
+// transaction, so either all components are taken or none are. The
+// components and the total come from the product service, not the request.
+func (s *OrderRepository) createBundleOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
+	log.Printf("Incoming order request for bundle %s", req.BundleId)
+
+	if req.BundleId == "" {
+		return nil, fmt.Errorf("bundle id is required for orders with items")
+	}
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("invalid quantity: must be positive")
+	}
+	if s.Products == nil {
+		return nil, fmt.Errorf("failed to check bundle: product service not configured")
+	}
+	bundle, err := s.Products.GetBundle(ctx, &pb.GetBundleRequest{Id: req.BundleId})
+	if err != nil {
+		if msg := status.Convert(err).Message(); strings.Contains(msg, "not found") {
+			return nil, fmt.Errorf("invalid bundle %s: %s", req.BundleId, msg)
+		}
+		return nil, fmt.Errorf("failed to get bundle %s: %v", req.BundleId, err)
+	}
+	if len(bundle.Items) == 0 {
+		return nil, fmt.Errorf("invalid bundle %s: it has no items", req.BundleId)
+	}
+	total := float64(req.Quantity) * bundle.Price
+
+	// Merge duplicate variants and lock in a stable order so concurrent
+	// bundle orders sharing components cannot deadlock.
+	quantities := make(map[string]int64, len(bundle.Items))
+	for _, item := range bundle.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity for variant %s: must be positive", item.VariantId)
+		}
+		quantities[item.VariantId] += item.Quantity * req.Quantity
+	}
+	variantIDs := make([]string, 0, len(quantities))
+	for variantID := range quantities {
+		variantIDs = append(variantIDs, variantID)
+	}
+	sort.Strings(variantIDs)
+
+	for i, variantID := range variantIDs {
+		locked, err := s.acquireLock(ctx, variantID)
+		if err != nil || !locked {
+			for _, held := range variantIDs[:i] {
+				s.releaseLock(ctx, held)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to acquire lock: %v", err)
+			}
+			return nil, fmt.Errorf("variant %s is busy, retry", variantID)
+		}
+	}
+	defer func() {
+		for _, variantID := range variantIDs {
+			if unlockErr := s.releaseLock(ctx, variantID); unlockErr != nil {
+				log.Printf("failed to release lock: %v", unlockErr)
+			}
+		}
+	}()
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	remaining := make(map[string]int64, len(variantIDs))
+	for _, variantID := range variantIDs {
+		var currentStock int64
+		err = tx.QueryRowContext(ctx, `
+			SELECT quantity 
+			FROM stock 
+			WHERE variant_id = $1 
+			FOR UPDATE`,
+			variantID).Scan(&currentStock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check current stock: %v", err)
+		}
+
+		if currentStock < quantities[variantID] {
+			return nil, fmt.Errorf("insufficient stock for variant %s: available %d, requested %d",
+				variantID, currentStock, quantities[variantID])
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE stock 
+			SET quantity = quantity - $1,
+			    updated_at = $2
+			WHERE variant_id = $3`,
+			quantities[variantID], now, variantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update stock: %v", err)
+		}
+
+		remaining[variantID] = currentStock - quantities[variantID]
+	}
+
+	orderID := common.GenerateRandomId("order")
+	var order pb.Order
+	var createdAt, updatedAt time.Time
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO orders (id, variants_id, bundle_id, quantity, total, user_id, created_at, updated_at)
+		VALUES ($1, '', $2, $3, $4, $5, $6, $7)
+		RETURNING id, bundle_id, quantity, total, user_id, created_at, updated_at`,
+		orderID, req.BundleId, req.Quantity, total, req.UserId, now, now).
+		Scan(&order.OrderId, &order.BundleId, &order.Quantity, &order.Total, &order.UserId, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %v", err)
+	}
+
+	for _, variantID := range variantIDs {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO order_items (order_id, variant_id, quantity)
+			VALUES ($1, $2, $3)`,
+			orderID, variantID, quantities[variantID])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order item: %v", err)
+		}
+
+		order.Items = append(order.Items, &pb.OrderItem{
+			VariantId: variantID,
+			Quantity:  quantities[variantID],
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	for variantID, quantity := range remaining {
+		redisKey := fmt.Sprintf("stock:%s", variantID)
+		stockData := map[string]interface{}{
+			"quantity":  quantity,
+			"updatedAt": now.Unix(),
+		}
+		if err := s.redisClient.HMSet(ctx, redisKey, stockData).Err(); err != nil {
+			log.Printf("Warning: Failed to update Redis cache: %v", err)
+		}
+	}
+
+	order.CreatedAt = timestamppb.New(createdAt)
+	order.UpdatedAt = timestamppb.New(updatedAt)
+
+	log.Printf("Successfully created order %s for bundle %s", orderID, req.BundleId)
+	return &order, nil
+}
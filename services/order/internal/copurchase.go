@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+const (
+	// defaultCoPurchaseLimit is the number of co-purchased variants kept
+	// per variant when the request does not ask for one.
+	defaultCoPurchaseLimit = 20
+	// maxCoPurchaseLimit keeps the response well under the gRPC message
+	// size limit.
+	maxCoPurchaseLimit = 100
+	// defaultCoPurchasePairWindow is how far apart two purchases by the
+	// same customer may be and still count as bought together.
+	defaultCoPurchasePairWindow = 30 * 24 * time.Hour
+)
+
+// ListCoPurchases counts, for every pair of variants, the customers that
+// bought both within the pairing window, whether in one order or across
+// several, and keeps the most frequent partners of each variant. Bundle
+// orders contribute each of their component variants.
+func (s *OrderRepository) ListCoPurchases(ctx context.Context, req *pb.ListCoPurchasesRequest) (*pb.ListCoPurchasesResponse, error) {
+	limit := int(req.LimitPerVariant)
+	if limit <= 0 {
+		limit = defaultCoPurchaseLimit
+	}
+	if limit > maxCoPurchaseLimit {
+		limit = maxCoPurchaseLimit
+	}
+	pairWindow := time.Duration(req.PairWindowSeconds) * time.Second
+	if pairWindow <= 0 {
+		pairWindow = defaultCoPurchasePairWindow
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH purchases AS (
+			SELECT user_id, variants_id AS variant_id, created_at
+			FROM orders
+			WHERE variants_id <> '' AND created_at >= $1
+			UNION
+			SELECT o.user_id, oi.variant_id, o.created_at
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.created_at >= $1
+		),
+		pairs AS (
+			SELECT a.variant_id, b.variant_id AS other_variant_id, COUNT(DISTINCT a.user_id) AS customers
+			FROM purchases a
+			JOIN purchases b ON a.user_id = b.user_id
+				AND a.variant_id <> b.variant_id
+				AND b.created_at BETWEEN a.created_at - $3 * INTERVAL '1 second' AND a.created_at + $3 * INTERVAL '1 second'
+			GROUP BY a.variant_id, b.variant_id
+		),
+		ranked AS (
+			SELECT variant_id, other_variant_id, customers,
+				ROW_NUMBER() OVER (PARTITION BY variant_id ORDER BY customers DESC, other_variant_id) AS rank
+			FROM pairs
+		)
+		SELECT variant_id, other_variant_id, customers
+		FROM ranked
+		WHERE rank <= $2
+		ORDER BY variant_id, rank`,
+		time.Unix(req.Since, 0), limit, int64(pairWindow/time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query co-purchases: %v", err)
+	}
+	defer rows.Close()
+
+	var pairs []*pb.CoPurchase
+	for rows.Next() {
+		pair := &pb.CoPurchase{}
+		if err := rows.Scan(&pair.VariantId, &pair.OtherVariantId, &pair.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan co-purchase: %v", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating co-purchases: %v", err)
+	}
+
+	return &pb.ListCoPurchasesResponse{
+		Pairs: pairs,
+	}, nil
+}
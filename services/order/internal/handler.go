@@ -20,3 +20,7 @@ func NewOrderHandler(orderRepository *OrderRepository) *OrderHandler {
 func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
 	return h.orderRepository.CreateOrder(ctx, req)
 }
+
+func (h *OrderHandler) ListCoPurchases(ctx context.Context, req *pb.ListCoPurchasesRequest) (*pb.ListCoPurchasesResponse, error) {
+	return h.orderRepository.ListCoPurchases(ctx, req)
+}
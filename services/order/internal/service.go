@@ -23,6 +23,8 @@ const (
 type OrderRepository struct {
 	db          *sqlx.DB
 	redisClient *redis.Client
+	// Products is asked for the components and price of ordered bundles.
+	Products pb.ProductServiceClient
 }
 
 func NewOrderService(db *sqlx.DB, redisClient *redis.Client) *OrderRepository {
@@ -48,7 +50,9 @@ func (s *OrderRepository) acquireLock(ctx context.Context, variantID string) (bo
 		time.Sleep(retryTimeout)
 	}
 
-	return false, fmt.Errorf("timeout acquiring lock for variant %s", variantID)
+	// Another order is still holding the variant; callers ask the client to
+	// retry.
+	return false, nil
 }
 
 func (s *OrderRepository) releaseLock(ctx context.Context, variantID string) error {
@@ -57,12 +61,19 @@ func (s *OrderRepository) releaseLock(ctx context.Context, variantID string) err
 }
 
 func (s *OrderRepository) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
+	if req.BundleId != "" || len(req.Items) > 0 {
+		return s.createBundleOrder(ctx, req)
+	}
+
 	log.Printf("Incoming order request for variant %s", req.VariantsId)
 
 	locked, err := s.acquireLock(ctx, req.VariantsId)
-	if err != nil || !locked {
+	if err != nil {
 		return nil, fmt.Errorf("failed to acquire lock: %v", err)
 	}
+	if !locked {
+		return nil, fmt.Errorf("variant %s is busy, retry", req.VariantsId)
+	}
 	defer func() {
 		if unlockErr := s.releaseLock(ctx, req.VariantsId); unlockErr != nil {
 			log.Printf("failed to release lock: %v", unlockErr)
@@ -17,6 +17,7 @@ import (
 	"github.com/wafi04/golang-backend/configs/database"
 	"github.com/wafi04/golang-backend/grpc/pb"
 	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/gateway/server/config"
 	handler "github.com/wafi04/golang-backend/services/order/internal"
 	"google.golang.org/grpc"
 )
@@ -43,6 +44,14 @@ func main() {
 	log.Log(common.InfoLevel, "change : %s", common.LoadEnv("DATABASE_STOCK"))
 
 	orderservice := handler.NewOrderService(db.DB, redisClient)
+
+	productConn, err := config.ConnectWithRetry(config.Load().ProductServiceURL, "product")
+	if err != nil {
+		log.Log(common.ErrorLevel, "Failed to connect to product service: %v", err)
+		return
+	}
+	defer productConn.Close()
+	orderservice.Products = pb.NewProductServiceClient(productConn)
 	orderhandler := handler.NewOrderHandler(orderservice)
 
 	grpcServer := grpc.NewServer(
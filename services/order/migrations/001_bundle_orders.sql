@@ -0,0 +1,10 @@
+-- Bundle orders: one order row plus one item row per component variant.
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS bundle_id VARCHAR(255);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    order_id    VARCHAR(255) NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    variant_id  VARCHAR(255) NOT NULL,
+    quantity    BIGINT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (order_id, variant_id)
+);
@@ -0,0 +1,5 @@
+-- Co-purchases are counted over a window of recent orders, pairing the
+-- purchases of each customer.
+
+CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders (created_at);
+CREATE INDEX IF NOT EXISTS idx_orders_user_id_created_at ON orders (user_id, created_at);
@@ -29,6 +29,21 @@ func (h *ProductHandler) GetProduct(ctx context.Context, req *pb.GetProductReque
 	h.log.Log(logger.InfoLevel, "incoming request ")
 	return h.productService.GetProduct(ctx, req)
 }
+func (h *ProductHandler) GetProductBySlug(ctx context.Context, req *pb.GetProductBySlugRequest) (*pb.Product, error) {
+	h.log.Log(logger.InfoLevel, "incoming request slug")
+	return h.productService.GetProductBySlug(ctx, req)
+}
+
+func (h *ProductHandler) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+	h.log.Log(logger.InfoLevel, "incoming request update")
+	return h.productService.UpdateProduct(ctx, req)
+}
+
+func (h *ProductHandler) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	h.log.Log(logger.InfoLevel, "incoming request delete")
+	return h.productService.DeleteProduct(ctx, req)
+}
+
 func (h *ProductHandler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
 	h.log.Log(logger.InfoLevel, "incoming request list")
 	return h.productService.ListProducts(ctx, req)
@@ -63,3 +78,53 @@ func (h *ProductHandler) DeleteProductImage(ctx context.Context, req *pb.DeleteP
 	h.log.Log(logger.InfoLevel, "Incoming Request Delete image")
 	return h.productService.DeleteProductImage(ctx, req)
 }
+
+func (h *ProductHandler) ReorderProductImages(ctx context.Context, req *pb.ReorderProductImagesRequest) (*pb.ReorderProductImagesResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Reorder images")
+	return h.productService.ReorderProductImages(ctx, req)
+}
+
+func (h *ProductHandler) CreateBundle(ctx context.Context, req *pb.CreateBundleRequest) (*pb.Bundle, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Create Bundle")
+	return h.productService.CreateBundle(ctx, req)
+}
+
+func (h *ProductHandler) GetBundle(ctx context.Context, req *pb.GetBundleRequest) (*pb.Bundle, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Get Bundle")
+	return h.productService.GetBundle(ctx, req)
+}
+
+func (h *ProductHandler) ListBundles(ctx context.Context, req *pb.ListBundlesRequest) (*pb.ListBundlesResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request List Bundles")
+	return h.productService.ListBundles(ctx, req)
+}
+
+func (h *ProductHandler) DeleteBundle(ctx context.Context, req *pb.DeleteBundleRequest) (*pb.DeleteProductResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Delete Bundle")
+	return h.productService.DeleteBundle(ctx, req)
+}
+
+func (h *ProductHandler) GetRecommendations(ctx context.Context, req *pb.GetRecommendationsRequest) (*pb.GetRecommendationsResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Get Recommendations")
+	return h.productService.GetRecommendations(ctx, req)
+}
+
+func (h *ProductHandler) SetRelatedProducts(ctx context.Context, req *pb.SetRelatedProductsRequest) (*pb.SetRelatedProductsResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Set Related Products")
+	return h.productService.SetRelatedProducts(ctx, req)
+}
+
+func (h *ProductHandler) UpsertProductTranslation(ctx context.Context, req *pb.ProductTranslation) (*pb.ProductTranslation, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Upsert Product Translation")
+	return h.productService.UpsertProductTranslation(ctx, req)
+}
+
+func (h *ProductHandler) CountProductsByCategory(ctx context.Context, req *pb.CountProductsByCategoryRequest) (*pb.CountProductsByCategoryResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Count Products By Category")
+	return h.productService.CountProductsByCategory(ctx, req)
+}
+
+func (h *ProductHandler) ReassignProducts(ctx context.Context, req *pb.ReassignProductsRequest) (*pb.ReassignProductsResponse, error) {
+	h.log.Log(logger.InfoLevel, "Incoming Request Reassign Products")
+	return h.productService.ReassignProducts(ctx, req)
+}
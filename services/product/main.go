@@ -15,21 +15,94 @@ import (
 	"github.com/wafi04/common/pkg/logger"
 	"github.com/wafi04/golang-backend/configs/database"
 	"github.com/wafi04/golang-backend/grpc/pb"
-	"github.com/wafi04/golang-backend/services/category/handler"
-	"github.com/wafi04/golang-backend/services/category/service"
 	"github.com/wafi04/golang-backend/services/common"
+	"github.com/wafi04/golang-backend/services/product/handler"
+	productrepo "github.com/wafi04/golang-backend/services/product/repository"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 type Config struct {
-	DatabaseURL string
-	Port        string
+	DatabaseURL     string
+	Port            string
+	OrderServiceURL string
+	// CategoryServiceURL receives product events for category product counts.
+	CategoryServiceURL string
+	EventsInterval     time.Duration
+	// How often co-purchase recommendations are rebuilt, how much order
+	// history they are built from, and how far apart a customer's purchases
+	// may be to count as bought together.
+	RecommendationInterval   time.Duration
+	RecommendationWindow     time.Duration
+	RecommendationPairWindow time.Duration
 }
 
 func loadConfig() Config {
 	return Config{
-		DatabaseURL: common.LoadEnv("DATABASE_PRODUCT"),
-		Port:        common.LoadEnv("PRODUCT_PORT"),
+		DatabaseURL:              common.LoadEnv("DATABASE_PRODUCT"),
+		Port:                     common.LoadEnv("PRODUCT_PORT"),
+		OrderServiceURL:          common.LoadEnv("ORDER_SERVICE_URL"),
+		CategoryServiceURL:       common.LoadEnv("CATEGORY_SERVICE_URL"),
+		EventsInterval:           loadDuration("PRODUCT_EVENTS_INTERVAL", 5*time.Second),
+		RecommendationInterval:   loadDuration("RECOMMENDATION_REFRESH_INTERVAL", time.Hour),
+		RecommendationWindow:     loadDuration("RECOMMENDATION_WINDOW", 90*24*time.Hour),
+		RecommendationPairWindow: loadDuration("RECOMMENDATION_PAIR_WINDOW", 30*24*time.Hour),
+	}
+}
+
+func loadDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// runRecommendationJob rebuilds the co-purchase recommendations on start and
+// then on every tick until ctx is cancelled.
+func runRecommendationJob(ctx context.Context, log *logger.Logger, service *productrepo.ProductService, orders pb.OrderServiceClient, config Config) {
+	ticker := time.NewTicker(config.RecommendationInterval)
+	defer ticker.Stop()
+
+	for {
+		count, err := service.RefreshCoPurchases(ctx, orders, config.RecommendationWindow, config.RecommendationPairWindow)
+		if err != nil {
+			log.Log(logger.ErrorLevel, "Failed to refresh recommendations: %v", err)
+		} else {
+			log.Log(logger.InfoLevel, "Refreshed %d co-purchase recommendations", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runEventRelay delivers the product events outbox to the category service
+// until ctx is cancelled. Full batches are followed by another batch right
+// away; otherwise it waits for the next tick.
+func runEventRelay(ctx context.Context, log *logger.Logger, service *productrepo.ProductService, categories pb.CategoryServiceClient, config Config) {
+	const batchSize = 100
+
+	ticker := time.NewTicker(config.EventsInterval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := service.DeliverProductEvents(ctx, categories, batchSize)
+		if err != nil {
+			log.Log(logger.ErrorLevel, "Failed to deliver product events: %v", err)
+		}
+		if err == nil && sent == batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
@@ -47,14 +120,37 @@ func main() {
 	health := db.Health()
 	log.Log(logger.InfoLevel, "Database health: %v", health["status"])
 
-	categoryService := service.NewCategoryService(db.DB)
-	categoryHandler := handler.NewCategoryHandler(categoryService)
+	// The client is created lazily, so a category service that is not up yet
+	// only fails the calls that need it.
+	categoryConn, err := grpc.NewClient(config.CategoryServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to create category service client: %v", err)
+		return
+	}
+	defer categoryConn.Close()
+	categoryClient := pb.NewCategoryServiceClient(categoryConn)
+
+	productService := productrepo.NewProductService(db.DB, categoryClient)
+	productHandler := handler.NewProductHandler(productService)
 
 	grpcServer := grpc.NewServer(
 		grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
 		grpc.UnaryInterceptor(grpc_prometheus.UnaryServerInterceptor),
 	)
-	pb.RegisterCategoryServiceServer(grpcServer, categoryHandler)
+	pb.RegisterProductServiceServer(grpcServer, productHandler)
+
+	jobCtx, stopJob := context.WithCancel(context.Background())
+	defer stopJob()
+
+	orderConn, err := grpc.NewClient(config.OrderServiceURL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to create order service client: %v", err)
+	} else {
+		defer orderConn.Close()
+		go runRecommendationJob(jobCtx, log, productService, pb.NewOrderServiceClient(orderConn), config)
+	}
+
+	go runEventRelay(jobCtx, log, productService, categoryClient, config)
 
 	http.Handle("/metrics", promhttp.Handler())
 	httpServer := &http.Server{
@@ -88,6 +184,7 @@ func main() {
 
 	log.Log(logger.InfoLevel, "Shutting down servers...")
 
+	stopJob()
 	grpcServer.GracefulStop()
 	log.Log(logger.InfoLevel, "gRPC server stopped")
 
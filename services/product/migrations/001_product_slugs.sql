@@ -0,0 +1,19 @@
+-- Human-readable, unique product slugs plus redirects for renamed products.
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(255);
+
+-- Backfill existing rows; the id suffix keeps them unique.
+UPDATE products
+SET slug = trim(both '-' from regexp_replace(lower(name), '[^a-z0-9]+', '-', 'g')) || '-' || left(id, 8)
+WHERE slug IS NULL;
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS products_slug_key ON products (slug);
+
+CREATE TABLE IF NOT EXISTS product_slug_history (
+    slug        VARCHAR(255) PRIMARY KEY,
+    product_id  VARCHAR(255) NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS product_slug_history_product_id_idx ON product_slug_history (product_id);
@@ -0,0 +1,37 @@
+-- Image ordering and a single main image per variant.
+
+ALTER TABLE product_images ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+
+-- Backfill positions, keeping the current main image first.
+UPDATE product_images img
+SET position = ordered.rn - 1
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY variant_id ORDER BY is_main DESC, id) AS rn
+    FROM product_images
+) ordered
+WHERE img.id = ordered.id;
+
+-- Keep only the first main image of each variant before adding the constraint.
+UPDATE product_images img
+SET is_main = false
+WHERE img.is_main
+  AND EXISTS (
+      SELECT 1 FROM product_images other
+      WHERE other.variant_id = img.variant_id
+        AND other.is_main
+        AND other.position < img.position
+  );
+
+-- Give variants without a main image one.
+UPDATE product_images img
+SET is_main = true
+WHERE img.position = 0
+  AND NOT EXISTS (
+      SELECT 1 FROM product_images other
+      WHERE other.variant_id = img.variant_id AND other.is_main
+  );
+
+CREATE UNIQUE INDEX IF NOT EXISTS product_images_one_main_per_variant
+    ON product_images (variant_id) WHERE is_main;
+CREATE INDEX IF NOT EXISTS product_images_variant_position_idx
+    ON product_images (variant_id, position);
@@ -0,0 +1,18 @@
+-- Bundles: a catalog item made of several variants, sold at its own price.
+
+CREATE TABLE IF NOT EXISTS bundles (
+    id           VARCHAR(255) PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    sku          VARCHAR(100) NOT NULL UNIQUE,
+    price        DOUBLE PRECISION NOT NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS bundle_items (
+    bundle_id   VARCHAR(255) NOT NULL REFERENCES bundles (id) ON DELETE CASCADE,
+    variant_id  VARCHAR(255) NOT NULL REFERENCES product_variants (id),
+    quantity    BIGINT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, variant_id)
+);
@@ -0,0 +1,21 @@
+-- Curated related products, in display order.
+CREATE TABLE IF NOT EXISTS related_products (
+    product_id          VARCHAR(255) NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    related_product_id  VARCHAR(255) NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    position            INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, related_product_id),
+    CHECK (product_id <> related_product_id)
+);
+
+-- Co-purchase scores, rebuilt by the recommendation batch job from the
+-- order service's order history.
+CREATE TABLE IF NOT EXISTS product_copurchases (
+    product_id          VARCHAR(255) NOT NULL,
+    related_product_id  VARCHAR(255) NOT NULL,
+    score               DOUBLE PRECISION NOT NULL,
+    refreshed_at        TIMESTAMP NOT NULL,
+    PRIMARY KEY (product_id, related_product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_copurchases_score
+    ON product_copurchases (product_id, score DESC);
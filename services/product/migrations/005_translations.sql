@@ -0,0 +1,10 @@
+-- Per-locale product content. products.name and products.description hold the
+-- default locale (DEFAULT_LOCALE, "id" unless configured).
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id   VARCHAR(255) NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+    locale       VARCHAR(35) NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    updated_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (product_id, locale)
+);
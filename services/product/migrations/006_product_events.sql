@@ -0,0 +1,19 @@
+-- Outbox of product category changes, delivered to the category service to
+-- maintain per-category product counts.
+CREATE TABLE IF NOT EXISTS product_events (
+    id               VARCHAR(255) PRIMARY KEY,
+    product_id       VARCHAR(255) NOT NULL,
+    old_category_id  VARCHAR(255) NOT NULL DEFAULT '',
+    new_category_id  VARCHAR(255) NOT NULL DEFAULT '',
+    created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at     TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_events_pending
+    ON product_events (created_at) WHERE delivered_at IS NULL;
+
+-- Seed the counts for products that existed before the outbox.
+INSERT INTO product_events (id, product_id, new_category_id, created_at)
+SELECT 'backfill-' || id, id, category_id, CURRENT_TIMESTAMP
+FROM products
+ON CONFLICT (id) DO NOTHING;
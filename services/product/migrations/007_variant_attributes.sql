@@ -0,0 +1,2 @@
+-- Variant attributes (e.g. size, RAM), validated against the category schema.
+ALTER TABLE product_variants ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,136 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// rowsQuerier is satisfied by both *sqlx.DB and *sql.Tx.
+type rowsQuerier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// validateAttributes checks variant attributes against a category schema.
+// Without a schema any attributes are accepted; with one, every attribute
+// must be declared and every required one present.
+func validateAttributes(schema []*pb.CategoryAttribute, attrs map[string]string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+
+	declared := make(map[string]*pb.CategoryAttribute, len(schema))
+	for _, attr := range schema {
+		declared[attr.Name] = attr
+	}
+
+	for name := range attrs {
+		if declared[name] == nil {
+			return fmt.Errorf("invalid attributes: %s is not defined for this category", name)
+		}
+	}
+
+	for _, attr := range schema {
+		value, ok := attrs[attr.Name]
+		if !ok || value == "" {
+			if attr.Required {
+				return fmt.Errorf("invalid attributes: %s is required", attr.Name)
+			}
+			continue
+		}
+
+		switch attr.Type {
+		case "number":
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return fmt.Errorf("invalid attributes: %s must be a number", attr.Name)
+			}
+		case "boolean":
+			if _, err := strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("invalid attributes: %s must be true or false", attr.Name)
+			}
+		}
+
+		if len(attr.AllowedValues) > 0 && !contains(attr.AllowedValues, value) {
+			return fmt.Errorf("invalid attributes: %s must be one of %v", attr.Name, attr.AllowedValues)
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateVariantAttributes validates each variant's attributes against the
+// schema of categoryID, as served by the category service.
+func (s *ProductService) validateVariantAttributes(ctx context.Context, categoryID string, variants ...map[string]string) error {
+	if categoryID == "" || len(variants) == 0 {
+		return nil
+	}
+	if s.categories == nil {
+		return fmt.Errorf("failed to validate attributes: category service not configured")
+	}
+
+	res, err := s.categories.GetCategoryAttributes(ctx, &pb.GetCategoryRequest{Id: categoryID})
+	if err != nil {
+		return fmt.Errorf("failed to get category attributes: %v", err)
+	}
+
+	for _, attrs := range variants {
+		if err := validateAttributes(res.Attributes, attrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// variantAttributes loads the attributes of all variants of a product.
+func variantAttributes(ctx context.Context, q rowsQuerier, productID string) ([]map[string]string, error) {
+	rows, err := q.QueryContext(ctx, "SELECT attributes FROM product_variants WHERE product_id = $1", productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant attributes: %v", err)
+	}
+	defer rows.Close()
+
+	var result []map[string]string
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("failed to scan variant attributes: %v", err)
+		}
+		attrs, err := decodeAttributes(raw)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, attrs)
+	}
+	return result, rows.Err()
+}
+
+func encodeAttributes(attrs map[string]string) ([]byte, error) {
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	return json.Marshal(attrs)
+}
+
+func decodeAttributes(raw []byte) (map[string]string, error) {
+	attrs := map[string]string{}
+	if len(raw) == 0 {
+		return attrs, nil
+	}
+	if err := json.Unmarshal(raw, &attrs); err != nil {
+		return nil, fmt.Errorf("failed to decode variant attributes: %v", err)
+	}
+	return attrs, nil
+}
@@ -0,0 +1,42 @@
+package productrepo
+
+import (
+	"testing"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+func TestValidateAttributes(t *testing.T) {
+	schema := []*pb.CategoryAttribute{
+		{Name: "size_eu", Type: "enum", AllowedValues: []string{"40", "41", "42"}, Required: true},
+		{Name: "weight", Type: "number"},
+		{Name: "waterproof", Type: "boolean"},
+	}
+
+	cases := []struct {
+		name    string
+		attrs   map[string]string
+		wantErr bool
+	}{
+		{"valid", map[string]string{"size_eu": "41", "weight": "0.8", "waterproof": "true"}, false},
+		{"required only", map[string]string{"size_eu": "40"}, false},
+		{"missing required", map[string]string{"weight": "1"}, true},
+		{"not allowed", map[string]string{"size_eu": "39"}, true},
+		{"not a number", map[string]string{"size_eu": "40", "weight": "heavy"}, true},
+		{"not a boolean", map[string]string{"size_eu": "40", "waterproof": "maybe"}, true},
+		{"undeclared", map[string]string{"size_eu": "40", "ram": "16"}, true},
+	}
+
+	for _, tc := range cases {
+		err := validateAttributes(schema, tc.attrs)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: validateAttributes() error = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateAttributesWithoutSchema(t *testing.T) {
+	if err := validateAttributes(nil, map[string]string{"anything": "goes"}); err != nil {
+		t.Errorf("validateAttributes() without schema = %v", err)
+	}
+}
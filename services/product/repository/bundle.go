@@ -0,0 +1,223 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/common/pkg/logger"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+func (s *ProductService) CreateBundle(ctx context.Context, req *pb.CreateBundleRequest) (*pb.Bundle, error) {
+	if req.Bundle == nil || len(req.Bundle.Items) == 0 {
+		return nil, fmt.Errorf("bundle must contain at least one item")
+	}
+
+	quantities := make(map[string]int64, len(req.Bundle.Items))
+	for _, item := range req.Bundle.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity for variant %s: must be positive", item.VariantId)
+		}
+		quantities[item.VariantId] += item.Quantity
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	bundle := &pb.Bundle{
+		Id:          uuid.New().String(),
+		Name:        req.Bundle.Name,
+		Description: req.Bundle.Description,
+		Sku:         req.Bundle.Sku,
+		Price:       req.Bundle.Price,
+		CreatedAt:   now.Unix(),
+		UpdatedAt:   now.Unix(),
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO bundles (id, name, description, sku, price, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		bundle.Id, bundle.Name, bundle.Description, bundle.Sku, bundle.Price, now, now)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to insert bundle: %v", err)
+		return nil, fmt.Errorf("failed to insert bundle: %v", err)
+	}
+
+	for _, item := range req.Bundle.Items {
+		quantity, ok := quantities[item.VariantId]
+		if !ok {
+			// Duplicate variant, already merged into the first occurrence.
+			continue
+		}
+		delete(quantities, item.VariantId)
+
+		var exists bool
+		err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM product_variants WHERE id = $1)", item.VariantId).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check variant: %v", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("variant %s not found", item.VariantId)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO bundle_items (bundle_id, variant_id, quantity)
+			VALUES ($1, $2, $3)`,
+			bundle.Id, item.VariantId, quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to insert bundle item: %v", err)
+		}
+
+		bundle.Items = append(bundle.Items, &pb.BundleItem{
+			VariantId: item.VariantId,
+			Quantity:  quantity,
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return bundle, nil
+}
+
+func (s *ProductService) GetBundle(ctx context.Context, req *pb.GetBundleRequest) (*pb.Bundle, error) {
+	bundle := &pb.Bundle{}
+	var createdAt, updatedAt time.Time
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, name, description, sku, price, created_at, updated_at
+		FROM bundles
+		WHERE id = $1`, req.Id).Scan(
+		&bundle.Id,
+		&bundle.Name,
+		&bundle.Description,
+		&bundle.Sku,
+		&bundle.Price,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("bundle not found")
+		}
+		s.log.Log(logger.ErrorLevel, "Failed to get bundle: %v", err)
+		return nil, fmt.Errorf("failed to get bundle")
+	}
+
+	bundle.CreatedAt = createdAt.Unix()
+	bundle.UpdatedAt = updatedAt.Unix()
+
+	if err := s.loadBundleItems(ctx, bundle); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (s *ProductService) ListBundles(ctx context.Context, req *pb.ListBundlesRequest) (*pb.ListBundlesResponse, error) {
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	page, _ := strconv.Atoi(req.PageToken)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, description, sku, price, created_at, updated_at
+		FROM bundles
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2`,
+		req.PageSize, int(req.PageSize)*page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bundles: %v", err)
+	}
+	defer rows.Close()
+
+	var bundles []*pb.Bundle
+	for rows.Next() {
+		bundle := &pb.Bundle{}
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(
+			&bundle.Id,
+			&bundle.Name,
+			&bundle.Description,
+			&bundle.Sku,
+			&bundle.Price,
+			&createdAt,
+			&updatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan bundle: %v", err)
+		}
+		bundle.CreatedAt = createdAt.Unix()
+		bundle.UpdatedAt = updatedAt.Unix()
+		bundles = append(bundles, bundle)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating bundles: %v", err)
+	}
+	rows.Close()
+
+	for _, bundle := range bundles {
+		if err := s.loadBundleItems(ctx, bundle); err != nil {
+			return nil, err
+		}
+	}
+
+	nextPageToken := ""
+	if len(bundles) == int(req.PageSize) {
+		nextPageToken = strconv.Itoa(page + 1)
+	}
+
+	return &pb.ListBundlesResponse{
+		Bundles:       bundles,
+		NextPageToken: nextPageToken,
+	}, nil
+}
+
+func (s *ProductService) DeleteBundle(ctx context.Context, req *pb.DeleteBundleRequest) (*pb.DeleteProductResponse, error) {
+	// bundle_items rows go with the bundle (ON DELETE CASCADE).
+	result, err := s.db.ExecContext(ctx, "DELETE FROM bundles WHERE id = $1", req.Id)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to delete bundle: %v", err)
+		return nil, fmt.Errorf("failed to delete bundle: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	return &pb.DeleteProductResponse{
+		Success: rowsAffected > 0,
+	}, nil
+}
+
+func (s *ProductService) loadBundleItems(ctx context.Context, bundle *pb.Bundle) error {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT variant_id, quantity
+		FROM bundle_items
+		WHERE bundle_id = $1
+		ORDER BY variant_id`, bundle.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get bundle items: %v", err)
+	}
+	defer rows.Close()
+
+	bundle.Items = make([]*pb.BundleItem, 0)
+	for rows.Next() {
+		item := &pb.BundleItem{}
+		if err := rows.Scan(&item.VariantId, &item.Quantity); err != nil {
+			return fmt.Errorf("failed to scan bundle item: %v", err)
+		}
+		bundle.Items = append(bundle.Items, item)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,159 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wafi04/common/pkg/logger"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// Changes to a product's category are written to the product_events outbox in
+// the same transaction as the product itself. DeliverProductEvents forwards
+// them to the category service, which keeps the per-category product counts.
+// Delivery is at least once; the category service skips replayed event ids.
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordProductEvent queues a category change for a product. An empty
+// oldCategoryID means the product was created, an empty newCategoryID that
+// it was deleted.
+func recordProductEvent(ctx context.Context, tx execer, productID, oldCategoryID, newCategoryID string) error {
+	if oldCategoryID == newCategoryID {
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO product_events (id, product_id, old_category_id, new_category_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		uuid.New().String(), productID, oldCategoryID, newCategoryID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record product event: %v", err)
+	}
+	return nil
+}
+
+// DeliverProductEvents sends up to batchSize pending events to the category
+// service and marks them delivered. It returns the number of events sent.
+func (s *ProductService) DeliverProductEvents(ctx context.Context, categories pb.CategoryServiceClient, batchSize int) (int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, product_id, old_category_id, new_category_id
+		FROM product_events
+		WHERE delivered_at IS NULL
+		ORDER BY created_at, id
+		LIMIT $1`,
+		batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query product events: %v", err)
+	}
+	defer rows.Close()
+
+	var events []*pb.ProductCategoryEvent
+	var ids []string
+	for rows.Next() {
+		event := &pb.ProductCategoryEvent{}
+		if err := rows.Scan(&event.EventId, &event.ProductId, &event.OldCategoryId, &event.NewCategoryId); err != nil {
+			return 0, fmt.Errorf("failed to scan product event: %v", err)
+		}
+		events = append(events, event)
+		ids = append(ids, event.EventId)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating product events: %v", err)
+	}
+	rows.Close()
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	if _, err := categories.ApplyProductEvents(ctx, &pb.ApplyProductEventsRequest{Events: events}); err != nil {
+		return 0, fmt.Errorf("failed to deliver product events: %v", err)
+	}
+
+	_, err = s.db.ExecContext(ctx, "UPDATE product_events SET delivered_at = $1 WHERE id = ANY($2)", time.Now(), ids)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to mark product events delivered: %v", err)
+		return 0, fmt.Errorf("failed to mark product events delivered: %v", err)
+	}
+
+	return len(events), nil
+}
+
+func (s *ProductService) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var categoryID string
+	err = tx.QueryRowContext(ctx, "SELECT category_id FROM products WHERE id = $1 FOR UPDATE", req.Id).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return &pb.DeleteProductResponse{
+			Success: false,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %v", err)
+	}
+
+	// bundle_items does not cascade from variants; a product whose variants
+	// are sold in a bundle has to leave the bundle first.
+	var bundles []string
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT bi.bundle_id
+		FROM bundle_items bi
+		JOIN product_variants pv ON pv.id = bi.variant_id
+		WHERE pv.product_id = $1
+		ORDER BY bi.bundle_id`,
+		req.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bundles: %v", err)
+	}
+	for rows.Next() {
+		var bundleID string
+		if err := rows.Scan(&bundleID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan bundle: %v", err)
+		}
+		bundles = append(bundles, bundleID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to check bundles: %v", err)
+	}
+	if len(bundles) > 0 {
+		return nil, fmt.Errorf("cannot delete product: its variants are in bundles %s", strings.Join(bundles, ", "))
+	}
+
+	queries := []string{
+		"DELETE FROM product_images WHERE variant_id IN (SELECT id FROM product_variants WHERE product_id = $1)",
+		"DELETE FROM product_variants WHERE product_id = $1",
+		"DELETE FROM products WHERE id = $1",
+	}
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, req.Id); err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to delete product: %v", err)
+			return nil, fmt.Errorf("failed to delete product: %v", err)
+		}
+	}
+
+	if err := recordProductEvent(ctx, tx, req.Id, categoryID, ""); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &pb.DeleteProductResponse{
+		Success: true,
+	}, nil
+}
@@ -19,29 +19,45 @@ import (
 type ProductService struct {
 	db  *sqlx.DB
 	log logger.Logger
+	// categories serves the attribute schemas variants are validated against.
+	categories pb.CategoryServiceClient
 }
 
-func NewProductService(db *sqlx.DB) *ProductService {
+func NewProductService(db *sqlx.DB, categories pb.CategoryServiceClient) *ProductService {
 	return &ProductService{
-		db: db,
+		db:         db,
+		categories: categories,
 	}
 }
 
 func (s *ProductService) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.Product, error) {
 	now := time.Now()
 	productID := uuid.New().String()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	slug, err := uniqueProductSlug(ctx, tx, req.Product.Name, productID)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to generate slug: %v", err)
+		return nil, err
+	}
+
 	query := `
     INSERT INTO products  
-    (id, name, sub_title, description, sku, price, category_id, created_at, updated_at)
+    (id, name, sub_title, description, sku, price, category_id, slug, created_at, updated_at)
     VALUES 
-    ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-    RETURNING id, name, sub_title, description, sku, price, category_id, created_at, updated_at
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+    RETURNING id, name, sub_title, description, sku, price, category_id, slug, created_at, updated_at
     `
 
 	var product pb.Product
 	var createdAt, updatedAt time.Time
 
-	err := s.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		productID,
 		req.Product.Name,
 		req.Product.SubTitle,
@@ -49,6 +65,7 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *pb.CreateProduc
 		req.Product.Sku,
 		req.Product.Price,
 		req.Product.CategoryId,
+		slug,
 		now,
 		now,
 	).Scan(
@@ -59,6 +76,7 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *pb.CreateProduc
 		&product.Sku,
 		&product.Price,
 		&product.CategoryId,
+		&product.Slug,
 		&createdAt,
 		&updatedAt,
 	)
@@ -68,6 +86,14 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *pb.CreateProduc
 		return nil, fmt.Errorf("failed to insert Product: %v", err)
 	}
 
+	if err := recordProductEvent(ctx, tx, product.Id, "", product.CategoryId); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
 	product.CreatedAt = time.Now().Unix()
 	product.UpdatedAt = time.Now().Unix()
 
@@ -85,6 +111,7 @@ func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductReque
 			price,
 			sku,
 			category_id,
+			slug,
 			created_at,
 			updated_at
 		FROM products
@@ -103,6 +130,7 @@ func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductReque
 		&product.Price,
 		&product.Sku,
 		&product.CategoryId,
+		&product.Slug,
 		&createdAt,
 		&updatedAt,
 	)
@@ -128,13 +156,15 @@ func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductReque
 			v.id,
 			v.color,
 			v.sku,
+			v.attributes,
 			img.id,
 			img.url,
-			img.is_main
+			img.is_main,
+			img.position
 		FROM product_variants v
 		LEFT JOIN product_images img ON v.id = img.variant_id
 		WHERE v.product_id = $1
-		ORDER BY v.id, img.is_main DESC
+		ORDER BY v.id, img.position
 	`
 
 	rows, err := s.db.QueryContext(ctx, variantsQuery, req.Id)
@@ -148,16 +178,20 @@ func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductReque
 
 	for rows.Next() {
 		var variantId, variantColor, variantSku string
+		var variantAttributes []byte
 		var imageId, imageUrl sql.NullString
 		var isMain sql.NullBool
+		var position sql.NullInt32
 
 		err := rows.Scan(
 			&variantId,
 			&variantColor,
 			&variantSku,
+			&variantAttributes,
 			&imageId,
 			&imageUrl,
 			&isMain,
+			&position,
 		)
 
 		if err != nil {
@@ -167,12 +201,18 @@ func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductReque
 
 		variant, exists := variantMap[variantId]
 		if !exists {
+			attrs, err := decodeAttributes(variantAttributes)
+			if err != nil {
+				s.log.Log(logger.ErrorLevel, "Failed to decode variant attributes: %v", err)
+				return nil, fmt.Errorf("failed to scan variant row")
+			}
 			variant = &pb.ProductVariant{
-				Id:        variantId,
-				Color:     variantColor,
-				Sku:       variantSku,
-				ProductId: req.Id,
-				Images:    make([]*pb.ProductImage, 0),
+				Id:         variantId,
+				Color:      variantColor,
+				Sku:        variantSku,
+				ProductId:  req.Id,
+				Images:     make([]*pb.ProductImage, 0),
+				Attributes: attrs,
 			}
 			variantMap[variantId] = variant
 		}
@@ -183,6 +223,7 @@ func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductReque
 				Url:       imageUrl.String,
 				VariantId: variant.Id,
 				IsMain:    isMain.Bool,
+				Position:  position.Int32,
 			}
 			variant.Images = append(variant.Images, image)
 		}
@@ -193,6 +234,11 @@ func (s *ProductService) GetProduct(ctx context.Context, req *pb.GetProductReque
 		product.Variants = append(product.Variants, variant)
 	}
 
+	if err := s.localizeProducts(ctx, req.Locale, product); err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to localize product: %v", err)
+		return nil, fmt.Errorf("failed to get product")
+	}
+
 	return product, nil
 }
 func (s *ProductService) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
@@ -212,6 +258,7 @@ func (s *ProductService) ListProducts(ctx context.Context, req *pb.ListProductsR
             p.price,
             p.sku,
             p.category_id,
+            p.slug,
             p.created_at,
             p.updated_at,
             (
@@ -248,6 +295,7 @@ func (s *ProductService) ListProducts(ctx context.Context, req *pb.ListProductsR
 			Price       float64         `db:"price"`
 			SKU         string          `db:"sku"`
 			CategoryID  string          `db:"category_id"`
+			Slug        string          `db:"slug"`
 			CreatedAt   time.Time       `db:"created_at"`
 			UpdatedAt   time.Time       `db:"updated_at"`
 			Variants    json.RawMessage `db:"variants"`
@@ -264,6 +312,7 @@ func (s *ProductService) ListProducts(ctx context.Context, req *pb.ListProductsR
 			Price:       product.Price,
 			Sku:         product.SKU,
 			CategoryId:  product.CategoryID,
+			Slug:        product.Slug,
 			CreatedAt:   product.CreatedAt.Unix(),
 			UpdatedAt:   product.UpdatedAt.Unix(),
 		}
@@ -283,6 +332,11 @@ func (s *ProductService) ListProducts(ctx context.Context, req *pb.ListProductsR
 	if err = rows.Err(); err != nil {
 		return nil, status.Errorf(codes.Internal, "error iterating products: %v", err)
 	}
+	rows.Close()
+
+	if err := s.localizeProducts(ctx, req.Locale, products...); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to localize products: %v", err)
+	}
 
 	nextPageToken := ""
 	if len(products) == int(req.PageSize) {
@@ -295,3 +349,134 @@ func (s *ProductService) ListProducts(ctx context.Context, req *pb.ListProductsR
 		NextPageToken: nextPageToken,
 	}, nil
 }
+
+// UpdateProduct changes the fields of a product that are set in the request
+// and keeps the others. A renamed product gets a new slug; the old one keeps
+// redirecting to it.
+func (s *ProductService) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+	if req.Id == "" {
+		return nil, fmt.Errorf("product id is required")
+	}
+	if req.Name != nil && *req.Name == "" {
+		return nil, fmt.Errorf("invalid name: must not be empty")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	current := &pb.Product{}
+	var subTitle sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT name, sub_title, description, sku, price, category_id, slug
+		FROM products WHERE id = $1 FOR UPDATE`, req.Id).Scan(
+		&current.Name,
+		&subTitle,
+		&current.Description,
+		&current.Sku,
+		&current.Price,
+		&current.CategoryId,
+		&current.Slug,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found")
+		}
+		return nil, fmt.Errorf("failed to get product: %v", err)
+	}
+	if subTitle.Valid {
+		current.SubTitle = &subTitle.String
+	}
+
+	updated := &pb.Product{
+		Name:        valueOr(req.Name, current.Name),
+		SubTitle:    current.SubTitle,
+		Description: valueOr(req.Description, current.Description),
+		Sku:         valueOr(req.Sku, current.Sku),
+		Price:       valueOr(req.Price, current.Price),
+		CategoryId:  valueOr(req.CategoryId, current.CategoryId),
+	}
+	if req.SubTitle != nil {
+		updated.SubTitle = req.SubTitle
+	}
+
+	// Moving to another category: the existing variants must satisfy the
+	// new category's schema.
+	if updated.CategoryId != current.CategoryId {
+		attrs, err := variantAttributes(ctx, tx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.validateVariantAttributes(ctx, updated.CategoryId, attrs...); err != nil {
+			return nil, err
+		}
+	}
+
+	slug := current.Slug
+	if updated.Name != current.Name {
+		slug, err = uniqueProductSlug(ctx, tx, updated.Name, req.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if slug != current.Slug {
+		// Keep the old slug pointing at this product so existing links redirect.
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO product_slug_history (slug, product_id, created_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (slug) DO UPDATE SET product_id = EXCLUDED.product_id`,
+			current.Slug, req.Id, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to save slug history: %v", err)
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM product_slug_history WHERE slug = $1", slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save slug history: %v", err)
+		}
+	}
+
+	query := `
+		UPDATE products
+		SET name = $1, sub_title = $2, description = $3, sku = $4, price = $5,
+		    category_id = $6, slug = $7, updated_at = $8
+		WHERE id = $9
+	`
+	_, err = tx.ExecContext(ctx, query,
+		updated.Name,
+		updated.SubTitle,
+		updated.Description,
+		updated.Sku,
+		updated.Price,
+		updated.CategoryId,
+		slug,
+		time.Now(),
+		req.Id,
+	)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to update product: %v", err)
+		return nil, fmt.Errorf("failed to update product: %v", err)
+	}
+
+	if err := recordProductEvent(ctx, tx, req.Id, current.CategoryId, updated.CategoryId); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return s.GetProduct(ctx, &pb.GetProductRequest{Id: req.Id})
+}
+
+// valueOr returns the value of an optional field, or fallback when it is
+// not set.
+func valueOr[T any](field *T, fallback T) T {
+	if field == nil {
+		return fallback
+	}
+	return *field
+}
@@ -0,0 +1,94 @@
+package productrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/common/pkg/logger"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// CountProductsByCategory counts the products in any of the given categories.
+func (s *ProductService) CountProductsByCategory(ctx context.Context, req *pb.CountProductsByCategoryRequest) (*pb.CountProductsByCategoryResponse, error) {
+	if len(req.CategoryIds) == 0 {
+		return &pb.CountProductsByCategoryResponse{}, nil
+	}
+
+	var count int64
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products WHERE category_id = ANY($1)", req.CategoryIds).Scan(&count)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to count products: %v", err)
+		return nil, fmt.Errorf("failed to count products: %v", err)
+	}
+
+	return &pb.CountProductsByCategoryResponse{
+		Count: count,
+	}, nil
+}
+
+// ReassignProducts moves every product in the given categories to another
+// category and records a product event for each, so the category product
+// counts follow. Variant attributes are not revalidated against the new
+// category's schema.
+func (s *ProductService) ReassignProducts(ctx context.Context, req *pb.ReassignProductsRequest) (*pb.ReassignProductsResponse, error) {
+	if req.ToCategoryId == "" {
+		return nil, fmt.Errorf("invalid request: target category is required")
+	}
+	if len(req.FromCategoryIds) == 0 {
+		return &pb.ReassignProductsResponse{}, nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH moved AS (
+			SELECT id, category_id
+			FROM products
+			WHERE category_id = ANY($1) AND category_id <> $2
+			FOR UPDATE
+		)
+		UPDATE products p
+		SET category_id = $2, updated_at = $3
+		FROM moved
+		WHERE p.id = moved.id
+		RETURNING p.id, moved.category_id`,
+		req.FromCategoryIds, req.ToCategoryId, time.Now())
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to reassign products: %v", err)
+		return nil, fmt.Errorf("failed to reassign products: %v", err)
+	}
+	defer rows.Close()
+
+	type move struct{ productID, oldCategoryID string }
+	var moves []move
+	for rows.Next() {
+		var m move
+		if err := rows.Scan(&m.productID, &m.oldCategoryID); err != nil {
+			return nil, fmt.Errorf("failed to scan reassigned product: %v", err)
+		}
+		moves = append(moves, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reassigned products: %v", err)
+	}
+	rows.Close()
+
+	for _, m := range moves {
+		if err := recordProductEvent(ctx, tx, m.productID, m.oldCategoryID, req.ToCategoryId); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &pb.ReassignProductsResponse{
+		Updated: int64(len(moves)),
+	}, nil
+}
@@ -0,0 +1,198 @@
+package productrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/common/pkg/logger"
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+const defaultRecommendationLimit = 10
+
+// GetRecommendations returns the curated related products first, followed by
+// the products most often bought by the same customers.
+func (s *ProductService) GetRecommendations(ctx context.Context, req *pb.GetRecommendationsRequest) (*pb.GetRecommendationsResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT related_product_id, source, score FROM (
+			SELECT related_product_id, 'related' AS source, 0::float8 AS score, 0 AS rank, position
+			FROM related_products
+			WHERE product_id = $1
+			UNION ALL
+			SELECT c.related_product_id, 'co_purchase', c.score, 1, 0
+			FROM product_copurchases c
+			WHERE c.product_id = $1
+			AND NOT EXISTS (
+				SELECT 1 FROM related_products r
+				WHERE r.product_id = $1 AND r.related_product_id = c.related_product_id
+			)
+		) recommendations
+		ORDER BY rank, position, score DESC, related_product_id
+		LIMIT $2`,
+		req.ProductId, limit)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to query recommendations: %v", err)
+		return nil, fmt.Errorf("failed to get recommendations: %v", err)
+	}
+	defer rows.Close()
+
+	var recommendations []*pb.Recommendation
+	for rows.Next() {
+		var productID string
+		recommendation := &pb.Recommendation{}
+		if err := rows.Scan(&productID, &recommendation.Source, &recommendation.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan recommendation: %v", err)
+		}
+		recommendation.Product = &pb.Product{Id: productID}
+		recommendations = append(recommendations, recommendation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recommendations: %v", err)
+	}
+	rows.Close()
+
+	result := make([]*pb.Recommendation, 0, len(recommendations))
+	for _, recommendation := range recommendations {
+		product, err := s.GetProduct(ctx, &pb.GetProductRequest{
+			Id:     recommendation.Product.Id,
+			Locale: req.Locale,
+		})
+		if err != nil {
+			// The product may have been deleted since the last refresh.
+			continue
+		}
+		recommendation.Product = product
+		result = append(result, recommendation)
+	}
+
+	return &pb.GetRecommendationsResponse{
+		Recommendations: result,
+	}, nil
+}
+
+// SetRelatedProducts replaces the curated related products of a product.
+func (s *ProductService) SetRelatedProducts(ctx context.Context, req *pb.SetRelatedProductsRequest) (*pb.SetRelatedProductsResponse, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", req.ProductId).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check product: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM related_products WHERE product_id = $1", req.ProductId); err != nil {
+		return nil, fmt.Errorf("failed to clear related products: %v", err)
+	}
+
+	seen := make(map[string]bool, len(req.RelatedProductIds))
+	related := make([]string, 0, len(req.RelatedProductIds))
+	for _, id := range req.RelatedProductIds {
+		if id == req.ProductId {
+			return nil, fmt.Errorf("product cannot be related to itself")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO related_products (product_id, related_product_id, position)
+			VALUES ($1, $2, $3)`,
+			req.ProductId, id, len(related))
+		if err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to insert related product: %v", err)
+			return nil, fmt.Errorf("failed to add related product %s: %v", id, err)
+		}
+		related = append(related, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &pb.SetRelatedProductsResponse{
+		RelatedProductIds: related,
+	}, nil
+}
+
+// RefreshCoPurchases rebuilds product_copurchases from the order history of
+// the given window, pairing variants a customer bought at most pairWindow
+// apart. Variant pairs are rolled up to their products; the score of a
+// product pair is the highest customer count among its variant pairs.
+func (s *ProductService) RefreshCoPurchases(ctx context.Context, orders pb.OrderServiceClient, window, pairWindow time.Duration) (int, error) {
+	res, err := orders.ListCoPurchases(ctx, &pb.ListCoPurchasesRequest{
+		Since:             time.Now().Add(-window).Unix(),
+		PairWindowSeconds: int64(pairWindow / time.Second),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list co-purchases: %v", err)
+	}
+
+	var variants []struct {
+		ID        string `db:"id"`
+		ProductID string `db:"product_id"`
+	}
+	if err := s.db.SelectContext(ctx, &variants, "SELECT id, product_id FROM product_variants"); err != nil {
+		return 0, fmt.Errorf("failed to get variants: %v", err)
+	}
+	productOf := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		productOf[variant.ID] = variant.ProductID
+	}
+
+	type productPair struct{ product, related string }
+	scores := make(map[productPair]int64)
+	for _, pair := range res.Pairs {
+		product, ok := productOf[pair.VariantId]
+		if !ok {
+			continue
+		}
+		related, ok := productOf[pair.OtherVariantId]
+		if !ok || related == product {
+			continue
+		}
+		key := productPair{product, related}
+		if pair.Count > scores[key] {
+			scores[key] = pair.Count
+		}
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM product_copurchases"); err != nil {
+		return 0, fmt.Errorf("failed to clear co-purchases: %v", err)
+	}
+
+	now := time.Now()
+	for pair, score := range scores {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO product_copurchases (product_id, related_product_id, score, refreshed_at)
+			VALUES ($1, $2, $3, $4)`,
+			pair.product, pair.related, score, now)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert co-purchase: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return len(scores), nil
+}
@@ -0,0 +1,65 @@
+package productrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/wafi04/common/pkg/logger"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+const maxSlugAttempts = 100
+
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// uniqueProductSlug finds the first free slug for name, skipping slugs that are
+// in use (or kept as redirects) by any product other than excludeID.
+func uniqueProductSlug(ctx context.Context, q rowQuerier, name, excludeID string) (string, error) {
+	base := common.Slugify(name)
+
+	query := `
+		SELECT EXISTS(SELECT 1 FROM products WHERE slug = $1 AND id <> $2)
+		    OR EXISTS(SELECT 1 FROM product_slug_history WHERE slug = $1 AND product_id <> $2)
+	`
+
+	for i := 1; i <= maxSlugAttempts; i++ {
+		candidate := common.SlugCandidate(base, i)
+
+		var taken bool
+		if err := q.QueryRowContext(ctx, query, candidate, excludeID).Scan(&taken); err != nil {
+			return "", fmt.Errorf("failed to check slug: %v", err)
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find a free slug for %q", name)
+}
+
+func (s *ProductService) GetProductBySlug(ctx context.Context, req *pb.GetProductBySlugRequest) (*pb.Product, error) {
+	query := `
+		SELECT id FROM products WHERE slug = $1
+		UNION ALL
+		SELECT product_id FROM product_slug_history WHERE slug = $1
+		LIMIT 1
+	`
+
+	var productID string
+	err := s.db.QueryRowContext(ctx, query, req.Slug).Scan(&productID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("product not found")
+		}
+		s.log.Log(logger.ErrorLevel, "Failed to resolve slug: %v", err)
+		return nil, fmt.Errorf("failed to get product")
+	}
+
+	// The returned product always carries its current slug, so callers can
+	// tell when they were given an old one and redirect.
+	return s.GetProduct(ctx, &pb.GetProductRequest{Id: productID, Locale: req.Locale})
+}
@@ -0,0 +1,109 @@
+package productrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/common/pkg/logger"
+	"github.com/wafi04/golang-backend/grpc/pb"
+	"github.com/wafi04/golang-backend/services/common"
+)
+
+// The name and description columns on products hold the default locale;
+// product_translations holds the other locales.
+
+func (s *ProductService) UpsertProductTranslation(ctx context.Context, req *pb.ProductTranslation) (*pb.ProductTranslation, error) {
+	locale := common.NormalizeLocale(req.Locale)
+	if locale == "" {
+		return nil, fmt.Errorf("locale is required")
+	}
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM products WHERE id = $1)", req.ProductId).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check product: %v", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("product not found")
+	}
+
+	translation := &pb.ProductTranslation{}
+	err := s.db.QueryRowContext(ctx, `
+		INSERT INTO product_translations (product_id, locale, name, description, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (product_id, locale)
+		DO UPDATE SET name = EXCLUDED.name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+		RETURNING product_id, locale, name, description`,
+		req.ProductId, locale, req.Name, req.Description, time.Now()).Scan(
+		&translation.ProductId,
+		&translation.Locale,
+		&translation.Name,
+		&translation.Description,
+	)
+	if err != nil {
+		s.log.Log(logger.ErrorLevel, "Failed to upsert product translation: %v", err)
+		return nil, fmt.Errorf("failed to upsert product translation: %v", err)
+	}
+
+	return translation, nil
+}
+
+// localizeProducts overwrites name and description with the best translation
+// for locale, following common.LocaleCandidates. Products without a matching
+// translation keep the default locale columns.
+func (s *ProductService) localizeProducts(ctx context.Context, locale string, products ...*pb.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	candidates := common.LocaleCandidates(locale)
+	rank := make(map[string]int, len(candidates))
+	for i, candidate := range candidates {
+		rank[candidate] = i
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.Id)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT product_id, locale, name, description
+		FROM product_translations
+		WHERE product_id = ANY($1) AND locale = ANY($2)`,
+		ids, candidates)
+	if err != nil {
+		return fmt.Errorf("failed to get product translations: %v", err)
+	}
+	defer rows.Close()
+
+	best := make(map[string]*pb.ProductTranslation)
+	for rows.Next() {
+		t := &pb.ProductTranslation{}
+		if err := rows.Scan(&t.ProductId, &t.Locale, &t.Name, &t.Description); err != nil {
+			return fmt.Errorf("failed to scan product translation: %v", err)
+		}
+		if current, ok := best[t.ProductId]; !ok || rank[t.Locale] < rank[current.Locale] {
+			best[t.ProductId] = t
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating product translations: %v", err)
+	}
+
+	for _, product := range products {
+		t, ok := best[product.Id]
+		if !ok {
+			continue
+		}
+		product.Name = t.Name
+		if t.Description != "" {
+			product.Description = t.Description
+		}
+	}
+
+	return nil
+}
@@ -2,53 +2,117 @@ package productrepo
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/wafi04/golang-backend/grpc/pb"
 )
 
+func (pr *ProductService) productCategory(ctx context.Context, productID string) (string, error) {
+	var categoryID string
+	err := pr.db.QueryRowContext(ctx, "SELECT category_id FROM products WHERE id = $1", productID).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("product not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get product: %v", err)
+	}
+	return categoryID, nil
+}
+
 func (pr *ProductService) CreateProductVariant(ctx context.Context, req *pb.CreateProductVariantRequest) (*pb.ProductVariant, error) {
+	categoryID, err := pr.productCategory(ctx, req.ProductId)
+	if err != nil {
+		return nil, err
+	}
+	if err := pr.validateVariantAttributes(ctx, categoryID, req.Attributes); err != nil {
+		return nil, err
+	}
+
+	attributes, err := encodeAttributes(req.Attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode attributes: %v", err)
+	}
+
 	variantsID := uuid.New().String()
 	var variants pb.ProductVariant
+	var rawAttributes []byte
 	query := `
-		INSERT INTO product_variants (id,color,sku,product_id)
-		VALUES ($1,$2,$3,$4)
-		RETURNING id, color, sku, product_id
+		INSERT INTO product_variants (id,color,sku,product_id,attributes)
+		VALUES ($1,$2,$3,$4,$5)
+		RETURNING id, color, sku, product_id, attributes
 	`
 
-	err := pr.db.QueryRowContext(ctx, query, variantsID, req.Color, req.Sku, req.ProductId).Scan(
+	err = pr.db.QueryRowContext(ctx, query, variantsID, req.Color, req.Sku, req.ProductId, attributes).Scan(
 		&variants.Id,
 		&variants.Color,
 		&variants.Sku,
 		&variants.ProductId,
+		&rawAttributes,
 	)
 
 	if err != nil {
 		pr.log.Error("Failed to Create Variants : %v ", err)
+		return nil, err
+	}
+
+	variants.Attributes, err = decodeAttributes(rawAttributes)
+	if err != nil {
+		return nil, err
 	}
 
 	return &pb.ProductVariant{
-		Id:        variants.Id,
-		Color:     variants.Color,
-		Sku:       variants.Sku,
-		ProductId: variants.ProductId,
+		Id:         variants.Id,
+		Color:      variants.Color,
+		Sku:        variants.Sku,
+		ProductId:  variants.ProductId,
+		Attributes: variants.Attributes,
 	}, nil
 }
 
 func (pr *ProductService) UpdateProductVariant(ctx context.Context, req *pb.UpdateProductVariantRequest) (*pb.ProductVariant, error) {
+	var categoryID string
+	err := pr.db.QueryRowContext(ctx, `
+		SELECT p.category_id
+		FROM product_variants v
+		JOIN products p ON p.id = v.product_id
+		WHERE v.id = $1`, req.Variant.Id).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("variant not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get variant: %v", err)
+	}
+
+	// No attributes keeps the current ones; they were validated when set.
+	var attributes interface{}
+	if len(req.Variant.Attributes) > 0 {
+		if err := pr.validateVariantAttributes(ctx, categoryID, req.Variant.Attributes); err != nil {
+			return nil, err
+		}
+		encoded, err := encodeAttributes(req.Variant.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode attributes: %v", err)
+		}
+		attributes = encoded
+	}
+
 	query := `
         UPDATE product_variants
-        SET color = $1, sku = $2
-        WHERE id = $3
-        RETURNING id, color, sku, product_id
+        SET color = $1, sku = $2, attributes = COALESCE($3, attributes)
+        WHERE id = $4
+        RETURNING id, color, sku, product_id, attributes
     `
 
 	var variant pb.ProductVariant
-	err := pr.db.QueryRowContext(ctx, query, req.Variant.Color, req.Variant.Sku, req.Variant.Id).Scan(
+	var rawAttributes []byte
+	err = pr.db.QueryRowContext(ctx, query, req.Variant.Color, req.Variant.Sku, attributes, req.Variant.Id).Scan(
 		&variant.Id,
 		&variant.Color,
 		&variant.Sku,
 		&variant.ProductId,
+		&rawAttributes,
 	)
 
 	if err != nil {
@@ -56,6 +120,11 @@ func (pr *ProductService) UpdateProductVariant(ctx context.Context, req *pb.Upda
 		return nil, err
 	}
 
+	variant.Attributes, err = decodeAttributes(rawAttributes)
+	if err != nil {
+		return nil, err
+	}
+
 	return &variant, nil
 }
 
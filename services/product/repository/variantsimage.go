@@ -2,111 +2,327 @@ package productrepo
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/wafi04/common/pkg/logger"
 	"github.com/wafi04/golang-backend/grpc/pb"
 )
 
-//   rpc AddProductImage (AddProductImageRequest) returns (ProductImage);
-//     rpc UpdateProductImage (UpdateProductImageRequest) returns (ProductImage);
-//     rpc DeleteProductImage (DeleteProductImageRequest) returns (DeleteProductResponse);
+// Every variant with images has exactly one main image. The rules are applied
+// inside a transaction that holds the variant row lock, so concurrent writers
+// on the same variant are serialized:
+//   - the first image of a variant always becomes main
+//   - setting is_main clears it on the sibling images
+//   - unsetting or deleting the main image promotes the next image by position
+
+func lockVariant(ctx context.Context, tx *sqlx.Tx, variantID string) error {
+	var id string
+	err := tx.QueryRowContext(ctx, "SELECT id FROM product_variants WHERE id = $1 FOR UPDATE", variantID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("variant not found")
+	}
+	return err
+}
+
+func clearMainImage(ctx context.Context, tx *sqlx.Tx, variantID, exceptID string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE product_images SET is_main = false
+		WHERE variant_id = $1 AND id <> $2 AND is_main`,
+		variantID, exceptID)
+	return err
+}
+
+// promoteMainImage makes the first image by position main, unless the variant
+// already has one or has no images left.
+func promoteMainImage(ctx context.Context, tx *sqlx.Tx, variantID string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE product_images SET is_main = true
+		WHERE id = (
+			SELECT id FROM product_images
+			WHERE variant_id = $1
+			ORDER BY position, id
+			LIMIT 1
+		)
+		AND NOT EXISTS (SELECT 1 FROM product_images WHERE variant_id = $1 AND is_main)`,
+		variantID)
+	return err
+}
+
+func getImage(ctx context.Context, tx *sqlx.Tx, imageID string) (*pb.ProductImage, error) {
+	var image pb.ProductImage
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, url, variant_id, is_main, position
+		FROM product_images WHERE id = $1`, imageID).Scan(
+		&image.Id,
+		&image.Url,
+		&image.VariantId,
+		&image.IsMain,
+		&image.Position,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &image, nil
+}
 
 func (pr *ProductService) AddProductImage(ctx context.Context, req *pb.AddProductImageRequest) (*pb.ProductImage, error) {
-    imageID := uuid.New().String()
+	imageID := uuid.New().String()
 
-    querySelect := `
-        SELECT id FROM product_variants WHERE id = $1
-    `
-    var variantID string
-    err := pr.db.QueryRowContext(ctx, querySelect, req.VariantId).Scan(&variantID)
-    if err != nil {
-        pr.log.Log(logger.ErrorLevel, "Variant not found: %v", err)
-        return nil, fmt.Errorf("variant not found: %v", err)
-    }
-
-    queryInsert := `
-        INSERT INTO product_images (id, url, variant_id, is_main)
-        VALUES ($1, $2, $3, $4)
-        RETURNING id, url, variant_id, is_main
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockVariant(ctx, tx, req.VariantId); err != nil {
+		pr.log.Log(logger.ErrorLevel, "Variant not found: %v", err)
+		return nil, fmt.Errorf("variant not found: %v", err)
+	}
+
+	var imageCount, nextPosition int32
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(MAX(position) + 1, 0)
+		FROM product_images WHERE variant_id = $1`, req.VariantId).Scan(&imageCount, &nextPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image position: %v", err)
+	}
+
+	isMain := req.IsMain || imageCount == 0
+	if isMain {
+		if err := clearMainImage(ctx, tx, req.VariantId, imageID); err != nil {
+			return nil, fmt.Errorf("failed to clear main image: %v", err)
+		}
+	}
+
+	queryInsert := `
+        INSERT INTO product_images (id, url, variant_id, is_main, position)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, url, variant_id, is_main, position
     `
 
-    var image pb.ProductImage
-    err = pr.db.QueryRowContext(ctx, queryInsert, imageID, req.Url, req.VariantId, req.IsMain).Scan(
-        &image.Id,
-        &image.Url,
-        &image.VariantId,
-        &image.IsMain,
-    )
-    if err != nil {
-        pr.log.Log(logger.ErrorLevel, "Failed to create product image: %v", err)
-        return nil, fmt.Errorf("failed to create product image: %v", err)
-    }
-
-    return &image, nil
-}
+	var image pb.ProductImage
+	err = tx.QueryRowContext(ctx, queryInsert, imageID, req.Url, req.VariantId, isMain, nextPosition).Scan(
+		&image.Id,
+		&image.Url,
+		&image.VariantId,
+		&image.IsMain,
+		&image.Position,
+	)
+	if err != nil {
+		pr.log.Log(logger.ErrorLevel, "Failed to create product image: %v", err)
+		return nil, fmt.Errorf("failed to create product image: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
 
+	return &image, nil
+}
 
+// UpdateProductImage changes the url and main flag of an image, keeping
+// whichever is not set in the request.
 func (pr *ProductService) UpdateProductImage(ctx context.Context, req *pb.UpdateProductImageRequest) (*pb.ProductImage, error) {
-    querySelect := `
-        SELECT id FROM product_images WHERE id = $1
-    `
-    var imageID string
-    err := pr.db.QueryRowContext(ctx, querySelect, req.Image.Id).Scan(&imageID)
-    if err != nil {
-        pr.log.Log(logger.ErrorLevel, "Image not found: %v", err)
-        return nil, fmt.Errorf("image not found: %v", err)
-    }
-
-    queryUpdate := `
+	if req.Url != nil && *req.Url == "" {
+		return nil, fmt.Errorf("invalid url: must not be empty")
+	}
+
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	current, err := getImage(ctx, tx, req.Id)
+	if err != nil {
+		pr.log.Log(logger.ErrorLevel, "Image not found: %v", err)
+		return nil, fmt.Errorf("image not found: %v", err)
+	}
+
+	if err := lockVariant(ctx, tx, current.VariantId); err != nil {
+		return nil, fmt.Errorf("failed to lock variant: %v", err)
+	}
+
+	// Re-read under the variant lock so is_main reflects committed state.
+	current, err = getImage(ctx, tx, current.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product image: %v", err)
+	}
+
+	url, isMain := current.Url, current.IsMain
+	if req.Url != nil {
+		url = *req.Url
+	}
+	if req.IsMain != nil {
+		isMain = *req.IsMain
+	}
+
+	if isMain && !current.IsMain {
+		if err := clearMainImage(ctx, tx, current.VariantId, current.Id); err != nil {
+			return nil, fmt.Errorf("failed to clear main image: %v", err)
+		}
+	}
+
+	queryUpdate := `
         UPDATE product_images
         SET url = $1, is_main = $2
         WHERE id = $3
-        RETURNING id, url, variant_id, is_main
     `
 
-    var image pb.ProductImage
-    err = pr.db.QueryRowContext(ctx, queryUpdate, req.Image.Url, req.Image.IsMain ,req.Image.Id).Scan(
-        &image.Id,
-        &image.Url,
-        &image.VariantId,
-        &image.IsMain,
-    )
-    if err != nil {
-        pr.log.Log(logger.ErrorLevel, "Failed to update product image: %v", err)
-        return nil, fmt.Errorf("failed to update product image: %v", err)
-    }
-
-    return &image, nil
-}
+	_, err = tx.ExecContext(ctx, queryUpdate, url, isMain, current.Id)
+	if err != nil {
+		pr.log.Log(logger.ErrorLevel, "Failed to update product image: %v", err)
+		return nil, fmt.Errorf("failed to update product image: %v", err)
+	}
+
+	if current.IsMain && !isMain {
+		// Hand the main flag to the next sibling; if there is none this
+		// image stays main.
+		_, err = tx.ExecContext(ctx, `
+			UPDATE product_images SET is_main = true
+			WHERE id = COALESCE((
+				SELECT id FROM product_images
+				WHERE variant_id = $1 AND id <> $2
+				ORDER BY position, id
+				LIMIT 1
+			), $2)`,
+			current.VariantId, current.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to promote main image: %v", err)
+		}
+	}
+
+	image, err := getImage(ctx, tx, current.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product image: %v", err)
+	}
 
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return image, nil
+}
 
 func (pr *ProductService) DeleteProductImage(ctx context.Context, req *pb.DeleteProductImageRequest) (*pb.DeleteProductResponse, error) {
-    queryDelete := `
-        DELETE FROM product_images
-        WHERE id = $1
-    `
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	current, err := getImage(ctx, tx, req.Id)
+	if err == sql.ErrNoRows {
+		return &pb.DeleteProductResponse{
+			Success: false,
+		}, nil
+	}
+	if err != nil {
+		pr.log.Log(logger.ErrorLevel, "Failed to get product image: %v", err)
+		return nil, fmt.Errorf("failed to get product image: %v", err)
+	}
+
+	if err := lockVariant(ctx, tx, current.VariantId); err != nil {
+		return nil, fmt.Errorf("failed to lock variant: %v", err)
+	}
+
+	// Re-read under the variant lock so is_main reflects committed state.
+	current, err = getImage(ctx, tx, current.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product image: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM product_images WHERE id = $1", req.Id)
+	if err != nil {
+		pr.log.Log(logger.ErrorLevel, "Failed to delete product image: %v", err)
+		return nil, fmt.Errorf("failed to delete product image: %v", err)
+	}
+
+	if current.IsMain {
+		if err := promoteMainImage(ctx, tx, current.VariantId); err != nil {
+			return nil, fmt.Errorf("failed to promote main image: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
 
-    result, err := pr.db.ExecContext(ctx, queryDelete, req.Id)
-    if err != nil {
-        pr.log.Log(logger.ErrorLevel, "Failed to delete product image: %v", err)
-        return nil, fmt.Errorf("failed to delete product image: %v", err)
-    }
-
-    rowsAffected, err := result.RowsAffected()
-    if err != nil {
-        pr.log.Log(logger.ErrorLevel, "Failed to get rows affected: %v", err)
-        return nil, fmt.Errorf("failed to get rows affected: %v", err)
-    }
-
-    if rowsAffected == 0 {
-        return &pb.DeleteProductResponse{
-            Success: false,
-        }, nil
-    }
-
-    return &pb.DeleteProductResponse{
-        Success: true,
-    }, nil
-}
\ No newline at end of file
+	return &pb.DeleteProductResponse{
+		Success: true,
+	}, nil
+}
+
+func (pr *ProductService) ReorderProductImages(ctx context.Context, req *pb.ReorderProductImagesRequest) (*pb.ReorderProductImagesResponse, error) {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockVariant(ctx, tx, req.VariantId); err != nil {
+		return nil, err
+	}
+
+	var existing []string
+	if err := tx.SelectContext(ctx, &existing, "SELECT id FROM product_images WHERE variant_id = $1", req.VariantId); err != nil {
+		return nil, fmt.Errorf("failed to get product images: %v", err)
+	}
+
+	if len(existing) != len(req.ImageIds) {
+		return nil, fmt.Errorf("invalid image order: expected %d images, got %d", len(existing), len(req.ImageIds))
+	}
+
+	remaining := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		remaining[id] = true
+	}
+	for _, id := range req.ImageIds {
+		if !remaining[id] {
+			return nil, fmt.Errorf("invalid image order: image %s is unknown or listed twice", id)
+		}
+		delete(remaining, id)
+	}
+
+	for position, id := range req.ImageIds {
+		_, err := tx.ExecContext(ctx, "UPDATE product_images SET position = $1 WHERE id = $2", position, id)
+		if err != nil {
+			pr.log.Log(logger.ErrorLevel, "Failed to reorder product image: %v", err)
+			return nil, fmt.Errorf("failed to reorder product images: %v", err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, url, variant_id, is_main, position
+		FROM product_images
+		WHERE variant_id = $1
+		ORDER BY position`, req.VariantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product images: %v", err)
+	}
+	defer rows.Close()
+
+	images := make([]*pb.ProductImage, 0, len(req.ImageIds))
+	for rows.Next() {
+		var image pb.ProductImage
+		if err := rows.Scan(&image.Id, &image.Url, &image.VariantId, &image.IsMain, &image.Position); err != nil {
+			return nil, fmt.Errorf("failed to scan product image: %v", err)
+		}
+		images = append(images, &image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating product images: %v", err)
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	return &pb.ReorderProductImagesResponse{
+		Images: images,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/golang-backend/grpc/pb"
+)
+
+// CheckBundleAvailability reports how many whole bundles the component stock
+// covers: the minimum over components of stock / quantity per bundle.
+func (r *Database) CheckBundleAvailability(ctx context.Context, req *pb.CheckBundleAvailabilityRequest) (*pb.CheckStockAvailabilityResponse, error) {
+	if len(req.Components) == 0 {
+		return nil, fmt.Errorf("bundle must contain at least one component")
+	}
+
+	var bundles int64 = -1
+	for _, component := range req.Components {
+		if component.Quantity <= 0 {
+			return nil, fmt.Errorf("invalid quantity for variant %s: must be positive", component.VariantId)
+		}
+
+		stock, err := r.availableQuantity(ctx, component.VariantId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stock for variant %s: %v", component.VariantId, err)
+		}
+
+		covered := stock / component.Quantity
+		if bundles < 0 || covered < bundles {
+			bundles = covered
+		}
+	}
+
+	return &pb.CheckStockAvailabilityResponse{
+		IsAvailable:       bundles >= req.RequestedQuantity,
+		AvailableQuantity: bundles,
+	}, nil
+}
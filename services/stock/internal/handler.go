@@ -27,3 +27,7 @@ func (h *Stockhandler) GetStock(ctx context.Context, req *pb.GetStockRequest) (*
 func (h *Stockhandler) CheckStockAvailability(ctx context.Context, req *pb.CheckStockAvailabilityRequest) (*pb.CheckStockAvailabilityResponse, error) {
 	return h.db.CheckStockAvailability(ctx, req)
 }
+
+func (h *Stockhandler) CheckBundleAvailability(ctx context.Context, req *pb.CheckBundleAvailabilityRequest) (*pb.CheckStockAvailabilityResponse, error) {
+	return h.db.CheckBundleAvailability(ctx, req)
+}
@@ -92,22 +92,27 @@ func (r *Database) GetStock(ctx context.Context, req *pb.GetStockRequest) (*pb.S
 }
 
 func (r *Database) CheckStockAvailability(ctx context.Context, req *pb.CheckStockAvailabilityRequest) (*pb.CheckStockAvailabilityResponse, error) {
-	redisKey := fmt.Sprintf("stock:%s", req.VariantId)
 	log.Printf("Variant : %s  , qty : %d", req.VariantId, req.RequestedQuantity)
 
+	availableQuantity, err := r.availableQuantity(ctx, req.VariantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CheckStockAvailabilityResponse{
+		IsAvailable:       availableQuantity >= req.RequestedQuantity,
+		AvailableQuantity: availableQuantity,
+	}, nil
+}
+
+// availableQuantity reads the stock of a variant from the Redis cache, falling
+// back to the database and refilling the cache. Unknown variants have 0 stock.
+func (r *Database) availableQuantity(ctx context.Context, variantID string) (int64, error) {
+	redisKey := fmt.Sprintf("stock:%s", variantID)
+
 	availableQuantity, err := r.redisClient.HGet(ctx, redisKey, "quantity").Int64()
 	if err == nil {
-		if availableQuantity >= req.RequestedQuantity {
-			return &pb.CheckStockAvailabilityResponse{
-				IsAvailable:       true,
-				AvailableQuantity: availableQuantity,
-			}, nil
-		} else {
-			return &pb.CheckStockAvailabilityResponse{
-				IsAvailable:       false,
-				AvailableQuantity: availableQuantity,
-			}, nil
-		}
+		return availableQuantity, nil
 	}
 
 	query := `
@@ -116,15 +121,12 @@ func (r *Database) CheckStockAvailability(ctx context.Context, req *pb.CheckStoc
         WHERE variant_id = $1
     `
 	var dbQuantity int64
-	err = r.db.QueryRowContext(ctx, query, req.VariantId).Scan(&dbQuantity)
+	err = r.db.QueryRowContext(ctx, query, variantID).Scan(&dbQuantity)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return &pb.CheckStockAvailabilityResponse{
-				IsAvailable:       false,
-				AvailableQuantity: 0,
-			}, nil
+			return 0, nil
 		}
-		return nil, err
+		return 0, err
 	}
 
 	err = r.redisClient.HSet(ctx, redisKey, "quantity", dbQuantity).Err()
@@ -132,15 +134,5 @@ func (r *Database) CheckStockAvailability(ctx context.Context, req *pb.CheckStoc
 		fmt.Printf("Gagal menyimpan data ke Redis: %v\n", err)
 	}
 
-	if dbQuantity >= req.RequestedQuantity {
-		return &pb.CheckStockAvailabilityResponse{
-			IsAvailable:       true,
-			AvailableQuantity: dbQuantity,
-		}, nil
-	} else {
-		return &pb.CheckStockAvailabilityResponse{
-			IsAvailable:       false,
-			AvailableQuantity: dbQuantity,
-		}, nil
-	}
+	return dbQuantity, nil
 }